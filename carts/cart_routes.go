@@ -6,6 +6,7 @@ import (
 	"backend/config"
 	"backend/internal/datastore"
 	"backend/middleware"
+	taxService "backend/taxes/service"
 
 	"github.com/gofiber/fiber/v2"
 	"log"
@@ -13,7 +14,8 @@ import (
 
 func RegisterModule(api fiber.Router, uow datastore.UnitOfWork, cfg *config.Config) {
 	// สร้าง dependencies
-	cartSvc := service.NewCartService(uow, cfg.ImageBaseURL)
+	taxEngine := taxService.NewEngine(taxService.SettingsFromConfig(cfg))
+	cartSvc := service.NewCartService(uow, cfg.ImageBaseURL, taxEngine)
 	cartHdl := handler.NewCartHandler(cartSvc)
 
 	// สร้างกลุ่ม Route สำหรับ Cart และป้องกันด้วย Middleware
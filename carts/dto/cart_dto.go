@@ -14,17 +14,21 @@ type CartItemResponse struct {
 	Price     float64 `json:"price"`
 	Quantity  uint    `json:"quantity"`
 	ImageURL  string  `json:"image_url"`
+	TaxRate   float64 `json:"tax_rate"` // อัตราภาษีของสินค้านี้ตามที่อยู่ (0.07 = 7%)
 }
 
 // CartResponse คือ DTO สำหรับตะกร้าสินค้าทั้งหมด
 type CartResponse struct {
-	ID            uint               `json:"id"`
-	UserID        uint               `json:"user_id"`
-	Items         []CartItemResponse `json:"items"`
-	Subtotal      float64            `json:"subtotal"`                 // <-- เพิ่ม: ราคารวมก่อนหักส่วนลด
-	Discount      float64            `json:"discount"`                 // <-- เพิ่ม: ยอดเงินส่วนลด
-	GrandTotal    float64            `json:"grand_total"`              // <-- เพิ่ม: ราคาสุทธิ
-	AppliedCoupon *string            `json:"applied_coupon,omitempty"` // <-- เพิ่ม: โค้ดคูปองที่ใช้
+	ID               uint               `json:"id"`
+	UserID           uint               `json:"user_id"`
+	Items            []CartItemResponse `json:"items"`
+	Subtotal         float64            `json:"subtotal"`                 // <-- เพิ่ม: ราคารวมก่อนหักส่วนลด
+	Discount         float64            `json:"discount"`                 // <-- เพิ่ม: ยอดเงินส่วนลด
+	NetTotal         float64            `json:"net_total"`                // ยอดหลังหักส่วนลด ไม่รวมภาษี
+	Tax              float64            `json:"tax"`                      // ภาษีรวม
+	PricesIncludeTax bool               `json:"prices_include_tax"`       // true = ราคาสินค้ารวมภาษีแล้ว (Tax เป็นยอดที่แยกให้ดู)
+	GrandTotal       float64            `json:"grand_total"`              // <-- เพิ่ม: ราคาสุทธิ
+	AppliedCoupon    *string            `json:"applied_coupon,omitempty"` // <-- เพิ่ม: โค้ดคูปองที่ใช้
 }
 type ApplyCouponRequest struct {
 	CouponCode string `json:"coupon_code" validate:"required"`
@@ -12,13 +12,14 @@ var ErrNotFound = errors.New("record not found")
 // CartRepository คือ Interface สำหรับจัดการข้อมูล Cart ทั้งหมด
 type CartRepository interface {
 	GetOrCreateCart(userID uint) (*domain.Cart, error)
-	AddItem(cartID, productID uint, quantity uint) (*domain.CartItem, error)
+	AddItem(cartID, productID uint, quantity uint, unitPrice float64) (*domain.CartItem, error)
 	GetCartByUserID(userID uint) (*domain.Cart, error)
 	UpdateItemQuantity(cartItemID uint, quantity uint) error
 	RemoveItem(cartItemID uint) error
 	ClearCart(cartID uint) error
 	FindItemByCartIDAndProductID(cartID, productID uint) (*domain.CartItem, error)
 	Update(cart *domain.Cart) error
+	RemoveCoupon(cartID uint) error
 }
 
 type cartRepository struct {
@@ -37,7 +38,8 @@ func (r *cartRepository) GetOrCreateCart(userID uint) (*domain.Cart, error) {
 	return &cart, err
 }
 
-func (r *cartRepository) AddItem(cartID, productID uint, quantity uint) (*domain.CartItem, error) {
+// AddItem เพิ่มสินค้าลงตะกร้า unitPrice คือราคาที่ลูกค้าเห็นตอนกดเพิ่ม (อัปเดตทุกครั้งที่เพิ่มจำนวน)
+func (r *cartRepository) AddItem(cartID, productID uint, quantity uint, unitPrice float64) (*domain.CartItem, error) {
 	// ตรวจสอบก่อนว่าสินค้านี้มีในตะกร้าแล้วหรือยัง
 	cartItem, err := r.FindItemByCartIDAndProductID(cartID, productID)
 	if err != nil && err != gorm.ErrRecordNotFound {
@@ -47,6 +49,7 @@ func (r *cartRepository) AddItem(cartID, productID uint, quantity uint) (*domain
 	if cartItem.ID != 0 {
 		// ถ้ามีอยู่แล้ว ให้อัปเดตจำนวน (บวกเพิ่ม)
 		cartItem.Quantity += quantity
+		cartItem.UnitPrice = unitPrice
 		err = r.db.Save(cartItem).Error
 	} else {
 		// ถ้ายังไม่มี ให้สร้างรายการใหม่
@@ -54,6 +57,7 @@ func (r *cartRepository) AddItem(cartID, productID uint, quantity uint) (*domain
 			CartID:    cartID,
 			ProductID: productID,
 			Quantity:  quantity,
+			UnitPrice: unitPrice,
 		}
 		err = r.db.Create(cartItem).Error
 	}
@@ -96,3 +100,8 @@ func (r *cartRepository) Update(cart *domain.Cart) error {
 	// เหมาะสำหรับการอัปเดต CouponID
 	return r.db.Save(cart).Error
 }
+
+// RemoveCoupon ถอดคูปองออกจากตะกร้า (เช่น หลังจากนำไปใช้สร้าง Order แล้ว)
+func (r *cartRepository) RemoveCoupon(cartID uint) error {
+	return r.db.Model(&domain.Cart{}).Where("id = ?", cartID).Update("coupon_id", nil).Error
+}
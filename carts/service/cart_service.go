@@ -5,6 +5,7 @@ import (
 	"backend/carts/repository"
 	"backend/domain"
 	"backend/internal/datastore"
+	taxService "backend/taxes/service"
 	"errors"
 	"time"
 )
@@ -30,12 +31,14 @@ type CartService interface {
 type cartService struct {
 	uow          datastore.UnitOfWork
 	imageBaseURL string
+	taxEngine    *taxService.Engine
 }
 
-func NewCartService(uow datastore.UnitOfWork, imageBaseURL string) CartService {
+func NewCartService(uow datastore.UnitOfWork, imageBaseURL string, taxEngine *taxService.Engine) CartService {
 	return &cartService{
 		uow:          uow,
 		imageBaseURL: imageBaseURL,
+		taxEngine:    taxEngine,
 	}
 }
 
@@ -58,7 +61,7 @@ func (s *cartService) AddItemToCart(userID uint, req dto.AddItemRequest) (*dto.C
 	}
 
 	// 3. เพิ่ม Item ลงในตะกร้า (Repository จะจัดการเรื่องบวกจำนวนเอง)
-	if _, err := s.uow.CartRepository().AddItem(cart.ID, req.ProductID, req.Quantity); err != nil {
+	if _, err := s.uow.CartRepository().AddItem(cart.ID, req.ProductID, req.Quantity, product.Price); err != nil {
 		return nil, err
 	}
 
@@ -85,7 +88,7 @@ func (s *cartService) GetCart(userID uint) (*dto.CartResponse, error) {
 	}
 
 	// แปลง Domain Model เป็น DTO
-	return s.mapCartToCartResponse(cart), nil
+	return s.mapCartToCartResponse(cart)
 }
 
 // UpdateCartItem อัปเดตจำนวนสินค้า
@@ -117,11 +120,12 @@ func (s *cartService) RemoveCartItem(userID, cartItemID uint) (*dto.CartResponse
 	return s.GetCart(userID)
 }
 
-func (s *cartService) mapCartToCartResponse(cart *domain.Cart) *dto.CartResponse {
+func (s *cartService) mapCartToCartResponse(cart *domain.Cart) (*dto.CartResponse, error) {
 	var subtotal float64
 	var discount float64
 
 	itemResponses := make([]dto.CartItemResponse, 0, len(cart.Items))
+	taxLines := make([]taxService.Line, 0, len(cart.Items))
 
 	for _, item := range cart.Items {
 		var imageURL string
@@ -140,31 +144,40 @@ func (s *cartService) mapCartToCartResponse(cart *domain.Cart) *dto.CartResponse
 		})
 		// คำนวณราคารวมก่อนหักส่วนลด
 		subtotal += item.Product.Price * float64(item.Quantity)
+		taxLines = append(taxLines, taxService.Line{
+			ProductID:  item.ProductID,
+			TaxClassID: item.Product.TaxClassID,
+			UnitPrice:  item.Product.Price,
+			Quantity:   item.Quantity,
+		})
 	}
 
 	// คำนวณส่วนลดถ้ามีคูปองผูกอยู่
 	if cart.Coupon != nil && cart.Coupon.ID != 0 {
-		switch cart.Coupon.DiscountType {
-		case domain.DiscountTypeFixed:
-			discount = cart.Coupon.DiscountValue
-		case domain.DiscountTypePercentage:
-			discount = subtotal * (cart.Coupon.DiscountValue / 100)
-		}
+		discount = cart.Coupon.CalculateDiscount(subtotal)
 	}
 
-	grandTotal := subtotal - discount
-	if grandTotal < 0 {
-		grandTotal = 0 // ราคาสุดท้ายต้องไม่ติดลบ
+	// คำนวณภาษีด้วย Engine เดียวกับตอน Checkout (ใช้ที่อยู่หลักของผู้ใช้ ถ้ายังไม่มีจะใช้ประเทศเริ่มต้น)
+	region := s.taxEngine.RegionFor(s.defaultAddress(cart.UserID))
+	tax, err := s.taxEngine.Calculate(s.uow.TaxRepository(), region, taxLines, discount)
+	if err != nil {
+		return nil, err
+	}
+	for i := range itemResponses {
+		itemResponses[i].TaxRate = tax.Lines[i].Rate
 	}
 
-	// สร้าง Response DTO
+	// สร้าง Response DTO (GrandTotal = ยอดที่ต้องจ่ายจริง รวมภาษีแล้ว)
 	response := &dto.CartResponse{
-		ID:         cart.ID,
-		UserID:     cart.UserID,
-		Items:      itemResponses,
-		Subtotal:   subtotal,
-		Discount:   discount,
-		GrandTotal: grandTotal,
+		ID:               cart.ID,
+		UserID:           cart.UserID,
+		Items:            itemResponses,
+		Subtotal:         subtotal,
+		Discount:         tax.Discount,
+		NetTotal:         tax.Net,
+		Tax:              tax.Tax,
+		PricesIncludeTax: tax.PricesIncludeTax,
+		GrandTotal:       tax.Total,
 	}
 
 	// เพิ่มโค้ดคูปองเข้าไปใน Response ถ้ามี
@@ -172,7 +185,21 @@ func (s *cartService) mapCartToCartResponse(cart *domain.Cart) *dto.CartResponse
 		response.AppliedCoupon = &cart.Coupon.Code
 	}
 
-	return response
+	return response, nil
+}
+
+// defaultAddress คืนที่อยู่หลักของผู้ใช้ (หรือที่อยู่แรกถ้าไม่ได้ตั้งที่อยู่หลัก) ถ้าไม่มีเลยคืน nil
+func (s *cartService) defaultAddress(userID uint) *domain.Address {
+	addresses, err := s.uow.AddressRepository().FindByUserID(userID)
+	if err != nil || len(addresses) == 0 {
+		return nil
+	}
+	for i := range addresses {
+		if addresses[i].IsDefault {
+			return &addresses[i]
+		}
+	}
+	return &addresses[0]
 }
 
 func (s *cartService) ApplyCoupon(userID uint, couponCode string) (*dto.CartResponse, error) {
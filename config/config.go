@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +16,46 @@ type Config struct {
 	AzureConnectionString string
 	PostgresDSN           string
 	ImageBaseURL          string
+
+	// Payment
+	PaymentProvider         string        // ผู้ให้บริการรับชำระเงิน ("fake" = FakeGateway ใน Process ต้องระบุเสมอ)
+	PaymentWebhookSecret    string        // Secret สำหรับตรวจ HMAC Signature ของ Webhook
+	PaymentWebhookTolerance time.Duration // อายุสูงสุดของ Timestamp ใน Webhook (กัน Replay)
+	PromptPayID             string        // PromptPay ID ของร้าน (เบอร์โทรศัพท์หรือเลขผู้เสียภาษี)
+
+	// Shipment
+	CarrierWebhookSecret    string // Secret สำหรับตรวจ HMAC Signature ของ Webhook จากขนส่ง
+	CarrierWebhookTolerance time.Duration
+
+	// Order
+	PendingOrderTTL     time.Duration // Order ที่ไม่ชำระเงินภายในเวลานี้จะถูกยกเลิกอัตโนมัติ (0 = ปิด)
+	OrderExpiryInterval time.Duration // ความถี่ในการตรวจหา Order ที่หมดอายุ
+	OrderNumberSecret   string        // Key ลับที่ใช้สลับลำดับในเลขที่ Order (ต้องระบุเสมอ และห้ามเปลี่ยนหลังเปิดใช้งาน ไม่เช่นนั้นเลขของวันเดียวกันอาจชนกัน)
+
+	// Idempotency-Key
+	IdempotencyInFlightTimeout time.Duration // คีย์ที่ค้างสถานะกำลังประมวลผลนานกว่านี้ถือว่าค้าง และให้ Request ใหม่ใช้คีย์นั้นได้
+	IdempotencyRetention       time.Duration // เก็บคีย์และ Response ไว้นานเท่านี้ก่อนลบ (0 = เก็บไว้ตลอด)
+
+	// Invoice (ข้อมูลผู้ขายที่แสดงบนใบกำกับภาษี)
+	SellerName          string
+	SellerAddress       string
+	SellerTaxID         string // เลขประจำตัวผู้เสียภาษี 13 หลัก
+	SellerBranch        string // รหัสสาขา 5 หลัก ("00000" = สำนักงานใหญ่)
+	InvoiceFontPath     string // ฟอนต์ TTF ที่รองรับภาษาไทย (เช่น Sarabun) ถ้าไม่ตั้งจะใช้ Helvetica และข้อความภาษาอังกฤษ
+	InvoiceFontBoldPath string
+
+	// Tax
+	PricesIncludeTax  bool   // ราคาสินค้าที่ตั้งไว้รวมภาษีแล้วหรือไม่
+	TaxDefaultClass   string // Tax Class ของสินค้าที่ไม่ได้ระบุ
+	TaxDefaultCountry string // ประเทศที่ใช้คำนวณภาษีในตะกร้าเมื่อผู้ใช้ยังไม่มีที่อยู่
+
+	// Outbox (Domain Event ที่ส่งออกไปยังระบบภายนอก)
+	OutboxPublisher      string        // ช่องทางส่ง Event ("log" = เขียนลง Log, "webhook" = POST ไปที่ OutboxWebhookURL)
+	OutboxWebhookURL     string        // URL ปลายทางของ Webhook Publisher
+	OutboxWebhookSecret  string        // Secret สำหรับสร้าง HMAC Signature ให้ปลายทางตรวจสอบ
+	OutboxWebhookTimeout time.Duration // เวลารอปลายทางตอบต่อ 1 Event
+	OutboxRelayInterval  time.Duration // ความถี่ในการส่ง Event ที่ค้างอยู่ (0 = ปิด)
+	OutboxRetention      time.Duration // เก็บ Event ที่ส่งสำเร็จไว้นานเท่านี้ก่อนลบ (0 = เก็บไว้ตลอด)
 }
 
 // LoadConfig จะทำหน้าที่โหลด .env และคืนค่า Config struct ที่พร้อมใช้งาน
@@ -36,5 +78,74 @@ func LoadConfig() *Config {
 		AzureConnectionString: os.Getenv("AZURE_STORAGE_CONNECTION_STRING"),
 		PostgresDSN:           dsn,
 		ImageBaseURL:          os.Getenv("AZURE_STORAGE_BASE_URL"),
+
+		PaymentProvider:         os.Getenv("PAYMENT_PROVIDER"),
+		PaymentWebhookSecret:    os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		PaymentWebhookTolerance: getEnvDuration("PAYMENT_WEBHOOK_TOLERANCE", 5*time.Minute),
+		PromptPayID:             os.Getenv("PROMPTPAY_ID"),
+
+		CarrierWebhookSecret:    os.Getenv("CARRIER_WEBHOOK_SECRET"),
+		CarrierWebhookTolerance: getEnvDuration("CARRIER_WEBHOOK_TOLERANCE", 5*time.Minute),
+
+		PendingOrderTTL:     getEnvDuration("PENDING_ORDER_TTL", 24*time.Hour),
+		OrderExpiryInterval: getEnvDuration("ORDER_EXPIRY_INTERVAL", time.Minute),
+		OrderNumberSecret:   os.Getenv("ORDER_NUMBER_SECRET"),
+
+		IdempotencyInFlightTimeout: getEnvDuration("IDEMPOTENCY_INFLIGHT_TIMEOUT", 5*time.Minute),
+		IdempotencyRetention:       getEnvDuration("IDEMPOTENCY_RETENTION", 24*time.Hour),
+
+		SellerName:          os.Getenv("SELLER_NAME"),
+		SellerAddress:       os.Getenv("SELLER_ADDRESS"),
+		SellerTaxID:         os.Getenv("SELLER_TAX_ID"),
+		SellerBranch:        getEnv("SELLER_BRANCH", "00000"),
+		InvoiceFontPath:     os.Getenv("INVOICE_FONT_PATH"),
+		InvoiceFontBoldPath: os.Getenv("INVOICE_FONT_BOLD_PATH"),
+
+		PricesIncludeTax:  getEnvBool("PRICES_INCLUDE_TAX", true),
+		TaxDefaultClass:   getEnv("TAX_DEFAULT_CLASS", "standard"),
+		TaxDefaultCountry: getEnv("TAX_DEFAULT_COUNTRY", "TH"),
+
+		OutboxPublisher:      getEnv("OUTBOX_PUBLISHER", "log"),
+		OutboxWebhookURL:     os.Getenv("OUTBOX_WEBHOOK_URL"),
+		OutboxWebhookSecret:  os.Getenv("OUTBOX_WEBHOOK_SECRET"),
+		OutboxWebhookTimeout: getEnvDuration("OUTBOX_WEBHOOK_TIMEOUT", 10*time.Second),
+		OutboxRelayInterval:  getEnvDuration("OUTBOX_RELAY_INTERVAL", 5*time.Second),
+		OutboxRetention:      getEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+	}
+}
+
+// getEnv อ่านค่าจาก Env Var ถ้าไม่มีจะใช้ค่า fallback
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvBool อ่านค่า true/false จาก Env Var ถ้าไม่มีหรือรูปแบบผิดจะใช้ค่า fallback
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid boolean for %s: %q, using %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getEnvDuration อ่านค่า Duration (เช่น "5m", "30s") จาก Env Var ถ้าไม่มีหรือรูปแบบผิดจะใช้ค่า fallback
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid duration for %s: %q, using %s", key, value, fallback)
+		return fallback
 	}
+	return d
 }
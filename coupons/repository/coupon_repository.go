@@ -3,12 +3,14 @@ package repository
 import (
 	"backend/domain"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 var (
-	ErrNotFound = errors.New("record not found")
+	ErrNotFound          = errors.New("record not found")
+	ErrCouponUnavailable = errors.New("coupon is inactive, expired or has reached its usage limit")
 )
 
 type CouponRepository interface {
@@ -18,6 +20,8 @@ type CouponRepository interface {
 	FindByCode(code string) (*domain.Coupon, error)
 	Update(coupon *domain.Coupon) error
 	Delete(id uint) error
+	ConsumeUsage(id uint) error
+	ReleaseUsage(code string) error
 }
 
 type couponRepository struct {
@@ -70,3 +74,26 @@ func (r *couponRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+// ConsumeUsage ใช้สิทธิ์คูปอง 1 ครั้ง โดยเพิ่ม usage_count แบบ Atomic
+// เงื่อนไขทั้งหมดถูกตรวจใน WHERE เดียวกัน จึงไม่มีทางใช้เกิน usage_limit แม้มีหลาย Request พร้อมกัน
+func (r *couponRepository) ConsumeUsage(id uint) error {
+	tx := r.db.Model(&domain.Coupon{}).
+		Where("id = ? AND is_active = ? AND expiry_date > ? AND usage_count < usage_limit", id, true, time.Now()).
+		Update("usage_count", gorm.Expr("usage_count + 1"))
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrCouponUnavailable
+	}
+	return nil
+}
+
+// ReleaseUsage คืนสิทธิ์การใช้คูปอง 1 ครั้ง (เช่น ตอน Order ที่ใช้คูปองถูกยกเลิก)
+// ลดค่า usage_count แบบ Atomic และไม่ให้ติดลบ
+func (r *couponRepository) ReleaseUsage(code string) error {
+	return r.db.Model(&domain.Coupon{}).
+		Where("code = ? AND usage_count > 0", code).
+		Update("usage_count", gorm.Expr("usage_count - 1")).Error
+}
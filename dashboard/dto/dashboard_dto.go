@@ -17,6 +17,16 @@ type SalesSummaryResponse struct {
 	Today      float64 `json:"today"`
 	Last7Days  float64 `json:"last_7_days"`
 	Last30Days float64 `json:"last_30_days"`
+
+	Gross SalesPeriodResponse `json:"gross"` // ยอดขายรวมภาษี (FinalPrice)
+	Net   SalesPeriodResponse `json:"net"`   // ยอดขายไม่รวมภาษี
+}
+
+// SalesPeriodResponse คือยอดขายแยกตามช่วงเวลา
+type SalesPeriodResponse struct {
+	Today      float64 `json:"today"`
+	Last7Days  float64 `json:"last_7_days"`
+	Last30Days float64 `json:"last_30_days"`
 }
 
 type CountSummaryResponse struct {
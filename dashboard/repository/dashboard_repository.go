@@ -32,9 +32,27 @@ func (r *dashboardRepository) GetSalesSummary() (*dto.SalesSummaryResponse, erro
 
 	completedStatus := string(domain.StatusCompleted)
 
-	r.db.Model(&domain.Order{}).Where("created_at >= ? AND status = ?", todayStart, completedStatus).Select("COALESCE(SUM(total_price), 0)").Row().Scan(&summary.Today)
-	r.db.Model(&domain.Order{}).Where("created_at >= ? AND status = ?", sevenDaysAgo, completedStatus).Select("COALESCE(SUM(total_price), 0)").Row().Scan(&summary.Last7Days)
-	r.db.Model(&domain.Order{}).Where("created_at >= ? AND status = ?", thirtyDaysAgo, completedStatus).Select("COALESCE(SUM(total_price), 0)").Row().Scan(&summary.Last30Days)
+	// ยอดขายหักยอดที่คืนเงินให้ลูกค้าแล้ว (จากการคืนสินค้า)
+	r.db.Model(&domain.Order{}).Where("created_at >= ? AND status = ?", todayStart, completedStatus).Select("COALESCE(SUM(final_price - refunded_amount), 0)").Row().Scan(&summary.Today)
+	r.db.Model(&domain.Order{}).Where("created_at >= ? AND status = ?", sevenDaysAgo, completedStatus).Select("COALESCE(SUM(final_price - refunded_amount), 0)").Row().Scan(&summary.Last7Days)
+	r.db.Model(&domain.Order{}).Where("created_at >= ? AND status = ?", thirtyDaysAgo, completedStatus).Select("COALESCE(SUM(final_price - refunded_amount), 0)").Row().Scan(&summary.Last30Days)
+
+	// ยอดขายรวมภาษีและไม่รวมภาษี หักยอดคืนเงินและภาษีส่วนที่คืนแล้ว
+	// (Order เก่าที่ไม่มียอดภาษีแยกจะถูกนับเป็นยอดไม่รวมภาษีทั้งหมด)
+	periods := []struct {
+		from  time.Time
+		gross *float64
+		net   *float64
+	}{
+		{todayStart, &summary.Gross.Today, &summary.Net.Today},
+		{sevenDaysAgo, &summary.Gross.Last7Days, &summary.Net.Last7Days},
+		{thirtyDaysAgo, &summary.Gross.Last30Days, &summary.Net.Last30Days},
+	}
+	for _, p := range periods {
+		r.db.Model(&domain.Order{}).Where("created_at >= ? AND status = ?", p.from, completedStatus).
+			Select("COALESCE(SUM(final_price - refunded_amount), 0), COALESCE(SUM(final_price - tax_amount - (refunded_amount - refunded_tax_amount)), 0)").
+			Row().Scan(p.gross, p.net)
+	}
 
 	return summary, nil
 }
@@ -13,4 +13,9 @@ type Address struct {
 	PostalCode   string `json:"postal_code" gorm:"not null"`
 	Country      string `json:"country" gorm:"not null"`
 	IsDefault    bool   `json:"is_default" gorm:"default:false"`
+
+	// ข้อมูลสำหรับออกใบกำกับภาษีเต็มรูป (ลูกค้านิติบุคคล) ไม่บังคับ
+	CompanyName string `json:"company_name" gorm:"type:varchar(255)"`
+	TaxID       string `json:"tax_id" gorm:"type:varchar(13)"`     // เลขประจำตัวผู้เสียภาษี 13 หลัก
+	BranchCode  string `json:"branch_code" gorm:"type:varchar(5)"` // รหัสสาขา 5 หลัก ("00000" = สำนักงานใหญ่)
 }
@@ -19,4 +19,5 @@ type CartItem struct {
 	ProductID uint    `gorm:"not null"`
 	Product   Product // เพื่อให้ดึงข้อมูลสินค้ามาแสดงได้
 	Quantity  uint    `gorm:"not null"`
+	UnitPrice float64 `gorm:"not null;default:0"` // ราคาสินค้าตอนหยิบใส่ตะกร้า ใช้แจ้งลูกค้าถ้าราคาเปลี่ยน (0 = รายการเก่าที่ยังไม่เก็บราคา)
 }
@@ -22,3 +22,19 @@ type Coupon struct {
 	UsageCount    uint         `gorm:"not null;default:0"`
 	IsActive      bool         `gorm:"not null;default:true"`
 }
+
+// CalculateDiscount คำนวณยอดส่วนลดจากราคารวม (Subtotal) ตามประเภทของคูปอง
+// ส่วนลดจะไม่เกินราคารวมเสมอ ใช้ร่วมกันทั้งตอนแสดงตะกร้าและตอนสร้าง Order
+func (c *Coupon) CalculateDiscount(subtotal float64) float64 {
+	var discount float64
+	switch c.DiscountType {
+	case DiscountTypeFixed:
+		discount = c.DiscountValue
+	case DiscountTypePercentage:
+		discount = subtotal * (c.DiscountValue / 100)
+	}
+	if discount > subtotal {
+		discount = subtotal
+	}
+	return discount
+}
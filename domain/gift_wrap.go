@@ -0,0 +1,13 @@
+package domain
+
+import "gorm.io/gorm"
+
+// GiftWrapOption คือบริการห่อของขวัญที่ลูกค้าซื้อเพิ่มได้ตอน Checkout (ราคาไม่มีภาษีเหมือนค่าจัดส่ง)
+type GiftWrapOption struct {
+	gorm.Model
+	Name        string  `json:"name" gorm:"type:varchar(100);not null"`
+	Description string  `json:"description" gorm:"type:varchar(500)"`
+	Price       float64 `json:"price" gorm:"not null;default:0"`
+	IsActive    bool    `json:"is_active" gorm:"not null;default:true"`
+	SortOrder   int     `json:"sort_order" gorm:"not null;default:0"`
+}
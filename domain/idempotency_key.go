@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey เก็บผลลัพธ์ของ Request แรกที่ส่ง Header Idempotency-Key มา
+// เพื่อให้ Request ที่ถูกส่งซ้ำ (Retry) ได้รับ Response เดิมกลับไปแทนการประมวลผลซ้ำ
+type IdempotencyKey struct {
+	gorm.Model
+	UserID       uint   `gorm:"not null;uniqueIndex:idx_idempotency_user_key"` // 0 = ไม่ได้ Login (เช่น Webhook)
+	Key          string `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_user_key"`
+	RequestHash  string `gorm:"type:varchar(64);not null"` // SHA-256 ของ Method + Path + Body
+	StatusCode   int    `gorm:"not null;default:0"`        // 0 = ยังประมวลผลไม่เสร็จ
+	ContentType  string `gorm:"type:varchar(100)"`
+	ResponseBody []byte
+	CompletedAt  *time.Time
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultVATRate คืออัตรา VAT มาตรฐาน ใช้กับ Order ที่สร้างก่อนมีการเก็บยอดภาษีแยก
+const DefaultVATRate = 0.07
+
+// Invoice คือใบกำกับภาษี/ใบเสร็จรับเงินของ Order (1 Order มีได้ 1 ใบ)
+// ออกให้อัตโนมัติตอน Order ได้รับชำระเงิน รายการสินค้าและยอดทั้งหมดถูก Snapshot ไว้ ณ ตอนออก
+// เอกสารที่พิมพ์ซ้ำภายหลังจึงตรงกับที่ออกไปเสมอ แม้ Order จะถูกแก้ไขหรือคืนสินค้าในภายหลัง
+type Invoice struct {
+	gorm.Model
+	OrderID       uint          `gorm:"not null;uniqueIndex"`
+	Number        string        `gorm:"type:varchar(20);not null;uniqueIndex"` // เช่น INV2026-000001
+	IssuedAt      time.Time     `gorm:"not null"`
+	Subtotal      float64       `gorm:"not null;default:0"` // ยอดรวมสินค้าก่อนส่วนลด
+	Discount      float64       `gorm:"not null;default:0"`
+	ShippingCost  float64       `gorm:"not null;default:0"`
+	GiftWrapPrice float64       `gorm:"not null;default:0"`
+	VATRate       float64       `gorm:"not null"`
+	NetAmount     float64       `gorm:"not null"` // มูลค่าก่อนภาษี
+	VATAmount     float64       `gorm:"not null"`
+	TotalAmount   float64       `gorm:"not null"` // ยอดรวม VAT (เท่ากับ FinalPrice ของ Order ณ ตอนออก)
+	Lines         []InvoiceLine `gorm:"foreignKey:InvoiceID"`
+}
+
+// InvoiceLine คือรายการสินค้า 1 บรรทัดบนใบกำกับภาษี (Snapshot จาก OrderItem ตอนออก)
+type InvoiceLine struct {
+	gorm.Model
+	InvoiceID   uint    `gorm:"not null;index"`
+	Position    int     `gorm:"not null"` // ลำดับบรรทัด เริ่มที่ 1
+	Description string  `gorm:"type:varchar(255);not null"`
+	Quantity    uint    `gorm:"not null"`
+	UnitPrice   float64 `gorm:"not null"`
+	Amount      float64 `gorm:"not null"`
+}
+
+// InvoiceAdjustmentKind คือประเภทของเอกสารปรับยอดใบกำกับภาษี
+type InvoiceAdjustmentKind string
+
+const (
+	InvoiceCreditNote InvoiceAdjustmentKind = "credit_note" // ใบลดหนี้ (ยอดลดลง)
+	InvoiceDebitNote  InvoiceAdjustmentKind = "debit_note"  // ใบเพิ่มหนี้ (ยอดเพิ่มขึ้น)
+)
+
+// InvoiceAdjustment คือใบลดหนี้/ใบเพิ่มหนี้ที่อ้างอิงใบกำกับภาษีเดิม
+// ออกเมื่อยอดของ Order เปลี่ยนหลังออกใบกำกับภาษีไปแล้ว (เช่น Admin แก้รายการสินค้าของ Order ที่ชำระแล้ว)
+type InvoiceAdjustment struct {
+	gorm.Model
+	InvoiceID      uint                  `gorm:"not null;index"`
+	OrderID        uint                  `gorm:"not null;index"`
+	Kind           InvoiceAdjustmentKind `gorm:"type:varchar(20);not null"`
+	Number         string                `gorm:"type:varchar(20);not null;uniqueIndex"` // เช่น CN2026-000001, DN2026-000001
+	IssuedAt       time.Time             `gorm:"not null"`
+	OriginalAmount float64               `gorm:"not null"` // ยอดรวม VAT ตามเอกสารก่อนหน้า
+	CorrectAmount  float64               `gorm:"not null"` // ยอดรวม VAT ที่ถูกต้อง
+	Amount         float64               `gorm:"not null"` // ส่วนต่าง (รวม VAT)
+	VATAmount      float64               `gorm:"not null"` // VAT ของส่วนต่าง
+	Reason         string                `gorm:"type:varchar(255)"`
+}
+
+// InvoiceAdjustmentSequence เก็บเลขที่ล่าสุดของใบลดหนี้/ใบเพิ่มหนี้แต่ละประเภทในแต่ละปี
+type InvoiceAdjustmentSequence struct {
+	Kind       InvoiceAdjustmentKind `gorm:"type:varchar(20);primaryKey"`
+	Year       int                   `gorm:"primaryKey;autoIncrement:false"`
+	LastNumber uint                  `gorm:"not null;default:0"`
+}
+
+// InvoiceSequence เก็บเลขที่ใบกำกับภาษีล่าสุดของแต่ละปี
+// ถูก Lock และเพิ่มค่าใน Transaction เดียวกับการเปลี่ยนสถานะ Order ทำให้เลขไม่ข้ามและไม่ซ้ำ
+type InvoiceSequence struct {
+	Year       int  `gorm:"primaryKey;autoIncrement:false"`
+	LastNumber uint `gorm:"not null;default:0"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "math"
+
+// RoundMoney ปัดยอดเงินเป็นหลักสตางค์ (ทศนิยม 2 ตำแหน่ง) ใช้ทุกที่ที่คำนวณยอดเงินด้วย float64
+func RoundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// SameMoney เทียบยอดเงินถึงหลักสตางค์ (กันปัญหาทศนิยมของ float64)
+func SameMoney(a, b float64) bool {
+	return math.Round(a*100) == math.Round(b*100)
+}
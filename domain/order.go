@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -16,21 +18,68 @@ const (
 	StatusCancelled  OrderStatus = "cancelled"  // ยกเลิก
 )
 
+// PaymentMethodCOD คือ PaymentMethod ของ Order ที่เก็บเงินปลายทาง
+// Order จะเข้า processing ทันทีตอน Checkout และถือว่าชำระแล้วเมื่อขนส่งโอนเงินที่เก็บได้ครบ
+const PaymentMethodCOD = "cod"
+
 // Order คือข้อมูลหลักของคำสั่งซื้อ
 type Order struct {
 	gorm.Model
-	UserID            uint `gorm:"not null"`
+	OrderNumber       *string `gorm:"type:varchar(20);uniqueIndex"` // เลขที่ Order สำหรับลูกค้า (ดู NewOrderNumber) Order เก่าก่อนมีเลขที่จะเป็น NULL
+	UserID            uint    `gorm:"not null"`
 	User              User
-	OrderItems        []OrderItem `gorm:"foreignKey:OrderID"`
-	TotalPrice        float64     `gorm:"not null"`
-	Discount          float64     `gorm:"not null;default:0"` // <-- เพิ่ม: ยอดส่วนลด
-	FinalPrice        float64     `gorm:"not null"`           // <-- เพิ่ม: ยอดที่ต้องจ่ายจริง
-	AppliedCouponCode *string     `gorm:"type:varchar(50)"`   // <-- เพิ่ม: โค้ดคูปองที่ใช้
-	ShippingAddressID uint        `gorm:"not null"`           // ID ของที่อยู่ที่จะจัดส่ง
-	ShippingAddress   Address     `gorm:"foreignKey:ShippingAddressID"`
-	PaymentMethod     *string     `gorm:"type:varchar(50)"`
-	Status            OrderStatus `gorm:"type:varchar(20);not null;default:'pending'"`
-	TrackingNumber    *string     `gorm:"type:varchar(50);default:null"` // หมายเลขติดตามพัสดุ
+	OrderItems        []OrderItem     `gorm:"foreignKey:OrderID"`
+	TotalPrice        float64         `gorm:"not null"`
+	Discount          float64         `gorm:"not null;default:0"` // <-- เพิ่ม: ยอดส่วนลด
+	FinalPrice        float64         `gorm:"not null"`           // <-- เพิ่ม: ยอดที่ต้องจ่ายจริง
+	AppliedCouponCode *string         `gorm:"type:varchar(50)"`   // <-- เพิ่ม: โค้ดคูปองที่ใช้
+	ShippingAddressID uint            `gorm:"not null"`           // ID ของที่อยู่ที่จะจัดส่ง
+	ShippingAddress   Address         `gorm:"foreignKey:ShippingAddressID"`
+	ShippingSnapshot  AddressSnapshot `gorm:"embedded;embeddedPrefix:shipping_"` // สำเนาที่อยู่ ณ เวลาที่สั่งซื้อ
+	PaymentMethod     *string         `gorm:"type:varchar(50)"`
+	Status            OrderStatus     `gorm:"type:varchar(20);not null;default:'pending'"`
+	TrackingNumber    *string         `gorm:"type:varchar(50);default:null"` // หมายเลขติดตามพัสดุ
+	Payments          []Payment       `gorm:"foreignKey:OrderID"`
+	PaidAt            *time.Time      // เวลาที่ได้รับเงินครบ (COD = เวลาที่ขนส่งโอนเงินครบ)
+	BalanceDue        float64         `gorm:"not null;default:0"` // ยอดที่ลูกค้าต้องชำระเพิ่ม หลัง Admin แก้ไข Order ที่ชำระแล้วจนยอดเพิ่มขึ้น
+
+	// ภาษี (คำนวณตอน Checkout ตามที่อยู่จัดส่ง)
+	PricesIncludeTax bool           `gorm:"not null;default:true"` // ราคาสินค้ารวมภาษีแล้วหรือไม่ ณ เวลาที่สั่งซื้อ
+	NetAmount        float64        `gorm:"not null;default:0"`    // ยอดหลังหักส่วนลด ไม่รวมภาษี
+	TaxAmount        float64        `gorm:"not null;default:0"`
+	TaxLines         []OrderTaxLine `gorm:"foreignKey:OrderID"`
+
+	// การจัดส่ง (ชื่อและผู้ให้บริการเก็บเป็นสำเนา เผื่อวิธีจัดส่งถูกแก้ไขหรือลบทีหลัง)
+	ShippingMethodID   *uint
+	ShippingMethodName string  `gorm:"type:varchar(100)"`
+	ShippingCarrier    string  `gorm:"type:varchar(100)"`
+	ShippingCost       float64 `gorm:"not null;default:0"` // รวมอยู่ใน FinalPrice แล้ว
+
+	// ของขวัญ (ชื่อบริการห่อเก็บเป็นสำเนาเหมือนวิธีจัดส่ง)
+	GiftWrapOptionID *uint
+	GiftWrapName     string  `gorm:"type:varchar(100)"`
+	GiftWrapPrice    float64 `gorm:"not null;default:0"` // รวมอยู่ใน FinalPrice แล้ว
+	GiftMessage      string  `gorm:"type:varchar(500)"`
+	GiftHidePrices   bool    `gorm:"not null;default:false"` // เอกสารแพ็คสินค้าไม่แสดงราคา
+
+	// ยอดที่คืนเงินให้ลูกค้าแล้วทั้งหมด (รายละเอียดอยู่ใน OrderRefund)
+	RefundedAmount    float64 `gorm:"not null;default:0"`
+	RefundedTaxAmount float64 `gorm:"not null;default:0"`
+}
+
+// IsCOD คืนค่า true ถ้า Order นี้เก็บเงินปลายทาง
+func (o *Order) IsCOD() bool {
+	return o.PaymentMethod != nil && *o.PaymentMethod == PaymentMethodCOD
+}
+
+// IsGift คืนค่า true ถ้าลูกค้าสั่ง Order นี้เป็นของขวัญ (ห่อของขวัญ, มีข้อความ หรือซ่อนราคา)
+func (o *Order) IsGift() bool {
+	return o.GiftWrapName != "" || o.GiftMessage != "" || o.GiftHidePrices
+}
+
+// AddOnCost คือยอดที่ไม่มีภาษีซึ่งบวกเพิ่มจากยอดสินค้า (ค่าจัดส่งและค่าห่อของขวัญ)
+func (o *Order) AddOnCost() float64 {
+	return o.ShippingCost + o.GiftWrapPrice
 }
 
 // OrderItem คือสินค้าแต่ละรายการในคำสั่งซื้อ
@@ -41,4 +90,105 @@ type OrderItem struct {
 	Product   Product
 	Quantity  uint    `gorm:"not null"`
 	Price     float64 `gorm:"not null"` // ราคาของสินค้า ณ เวลาที่สั่งซื้อ
+	TaxRate   float64 `gorm:"not null;default:0"`
+	TaxAmount float64 `gorm:"not null;default:0"` // ภาษีของรายการนี้ (หลังปันส่วนส่วนลดแล้ว)
+
+	// สำเนาข้อมูลสินค้า ณ เวลาที่สั่งซื้อ (สินค้าถูกแก้ชื่อหรือลบทีหลังก็ไม่กระทบ Order เก่า)
+	ProductName      string `gorm:"type:varchar(255)"`
+	ProductSKU       string `gorm:"type:varchar(100)"`
+	ProductImagePath string `gorm:"type:varchar(255)"` // เก็บเป็น Path เหมือน ProductImage แล้วค่อยต่อ URL ตอนส่งกลับ
+}
+
+// AddressSnapshot คือสำเนาที่อยู่จัดส่งที่บันทึกไว้กับ Order
+// ลูกค้าแก้ไขหรือลบ Address ทีหลังก็ไม่ทำให้ Order เก่าเปลี่ยน
+type AddressSnapshot struct {
+	AddressLine1 string `gorm:"type:varchar(255)"`
+	AddressLine2 string `gorm:"type:varchar(255)"`
+	City         string `gorm:"type:varchar(100)"`
+	State        string `gorm:"type:varchar(100)"`
+	PostalCode   string `gorm:"type:varchar(20)"`
+	Country      string `gorm:"type:varchar(100)"`
+	CompanyName  string `gorm:"type:varchar(255)"`
+	TaxID        string `gorm:"type:varchar(13)"`
+	BranchCode   string `gorm:"type:varchar(5)"`
+}
+
+// NewAddressSnapshot คัดลอกข้อมูลจาก Address มาเป็น Snapshot
+func NewAddressSnapshot(a *Address) AddressSnapshot {
+	return AddressSnapshot{
+		AddressLine1: a.AddressLine1,
+		AddressLine2: a.AddressLine2,
+		City:         a.City,
+		State:        a.State,
+		PostalCode:   a.PostalCode,
+		Country:      a.Country,
+		CompanyName:  a.CompanyName,
+		TaxID:        a.TaxID,
+		BranchCode:   a.BranchCode,
+	}
+}
+
+// IsEmpty คืนค่า true ถ้ายังไม่มี Snapshot (Order ที่สร้างก่อนมีการเก็บ Snapshot)
+func (s AddressSnapshot) IsEmpty() bool {
+	return s.AddressLine1 == "" && s.City == "" && s.PostalCode == ""
+}
+
+// orderStatusTransitions คือตารางกลางที่กำหนดว่าแต่ละสถานะเปลี่ยนไปเป็นสถานะไหนได้บ้าง
+// การเปลี่ยนสถานะทุกครั้งต้องผ่านตารางนี้ (ดู CanTransitionTo)
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	StatusPending:    {StatusProcessing, StatusCancelled},
+	StatusProcessing: {StatusShipped, StatusCancelled},
+	StatusShipped:    {StatusCompleted, StatusCancelled, StatusProcessing}, // processing = พัสดุตีกลับ รอส่งใหม่หรือยกเลิก
+}
+
+// CanTransitionTo ตรวจสอบว่าสามารถเปลี่ยนจากสถานะปัจจุบันไปเป็น next ได้หรือไม่
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderStatusHistory คือประวัติการเปลี่ยนสถานะของ Order แต่ละครั้ง
+type OrderStatusHistory struct {
+	gorm.Model
+	OrderID    uint        `gorm:"not null;index"`
+	FromStatus OrderStatus `gorm:"type:varchar(20)"` // ว่างได้ ถ้าเป็นตอนสร้าง Order
+	ToStatus   OrderStatus `gorm:"type:varchar(20);not null"`
+	ChangedBy  *uint       // ID ของผู้ใช้ที่เปลี่ยนสถานะ (nil = ระบบหรือ Payment Gateway)
+	Reason     string      `gorm:"type:varchar(255)"`
+}
+
+// OrderEditAction คือประเภทของการแก้ไขรายการสินค้าใน Order
+type OrderEditAction string
+
+const (
+	OrderEditAddItem        OrderEditAction = "add_item"        // เพิ่มสินค้า (หรือเพิ่มจำนวนของสินค้าที่มีอยู่แล้ว)
+	OrderEditChangeQuantity OrderEditAction = "change_quantity" // เปลี่ยนจำนวน
+	OrderEditSwapItem       OrderEditAction = "swap_item"       // เปลี่ยนเป็นสินค้าตัวอื่น
+	OrderEditRemoveItem     OrderEditAction = "remove_item"     // ลบรายการออก
+)
+
+// OrderEdit คือบันทึกการแก้ไขรายการสินค้าของ Order โดย Admin (ก่อนจัดส่ง) ใช้เป็น Audit Trail
+type OrderEdit struct {
+	gorm.Model
+	OrderID           uint            `gorm:"not null;index"`
+	EditedBy          uint            `gorm:"not null"`
+	Action            OrderEditAction `gorm:"type:varchar(20);not null"`
+	OrderItemID       uint            `gorm:"not null"`
+	ProductID         uint            `gorm:"not null"`
+	PreviousProductID *uint           // สินค้าเดิม กรณี swap_item
+	ProductName       string          `gorm:"type:varchar(255)"`
+	OldQuantity       uint            `gorm:"not null;default:0"`
+	NewQuantity       uint            `gorm:"not null;default:0"`
+	OldFinalPrice     float64         `gorm:"not null"`
+	NewFinalPrice     float64         `gorm:"not null"`
+	Reason            string          `gorm:"type:varchar(255)"`
+
+	// การปรับยอดของ Order ที่ชำระแล้ว (ยอดลด = คืนเงิน, ยอดเพิ่ม = BalanceDue) และใบลดหนี้/ใบเพิ่มหนี้ที่ออกให้
+	RefundAmount            float64 `gorm:"not null;default:0"`
+	BalanceDue              float64 `gorm:"not null;default:0"`
+	InvoiceAdjustmentNumber string  `gorm:"type:varchar(20)"`
 }
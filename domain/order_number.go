@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// orderNumberDateLayout คือส่วนวันที่นำหน้าเลขที่ Order (YYMMDD)
+const orderNumberDateLayout = "060102"
+
+// OrderNumberSequence เก็บเลขลำดับ Order ล่าสุดของแต่ละวัน
+// ต่างจาก InvoiceSequence ตรงที่เลขขาดช่วงได้ (จองเลขนอก Transaction ของ Checkout เพื่อไม่ให้ Checkout ต้องรอกัน)
+type OrderNumberSequence struct {
+	Date       string `gorm:"type:varchar(6);primaryKey"` // YYMMDD
+	LastNumber uint   `gorm:"not null;default:0"`
+}
+
+// OrderNumberDate คืนส่วนวันที่ของเลขที่ Order ที่สร้าง ณ เวลา t
+func OrderNumberDate(t time.Time) string {
+	return t.Format(orderNumberDateLayout)
+}
+
+// orderNumberSpace คือจำนวนเลขที่ไม่ซ้ำกันได้ต่อวันของส่วนที่สลับลำดับ (6 หลัก)
+const orderNumberSpace = 1000000
+
+// NewOrderNumber สร้างเลขที่ Order สำหรับอ่านให้ลูกค้าฟัง เช่น 261017-6832594
+// = วันที่ (YYMMDD) + ลำดับของวันนั้นที่ถูกสลับเป็นเลข 6 หลัก + Check Digit แบบ Luhn 1 หลัก
+// ลำดับถูกสลับแบบ 1 ต่อ 1 ด้วย key ลับ (permuteOrderSequence) เลขจึงยังไม่ซ้ำกันตาม Sequence
+// แต่คนที่ไม่รู้ key ย้อนกลับเป็นลำดับไม่ได้ จึงเดายอดขายต่อวันจากเลขที่ Order ไม่ได้
+// ถ้าลำดับเกิน 999,999 ในวันเดียว จะใช้ลำดับตรงๆ (7 หลักขึ้นไป จึงไม่ชนกับเลขที่ถูกสลับ)
+func NewOrderNumber(key []byte, date string, seq uint) string {
+	var body string
+	if seq < orderNumberSpace {
+		body = fmt.Sprintf("%s%06d", date, permuteOrderSequence(key, date, seq))
+	} else {
+		body = fmt.Sprintf("%s%d", date, seq)
+	}
+	return formatOrderNumber(body + string(luhnCheckDigit(body)))
+}
+
+// permuteOrderSequence สลับลำดับใน [0, 1,000,000) แบบ 1 ต่อ 1 ด้วย Feistel Network 4 รอบ (ครึ่งละ 3 หลัก)
+// Round Function คือ HMAC-SHA256 ด้วย key ลับ ของวันที่ รอบ และครึ่งขวา
+// ลำดับเดียวกันของคนละวันจึงได้เลขต่างกัน และผลคงที่เสมอตราบที่ key ไม่เปลี่ยน
+func permuteOrderSequence(key []byte, date string, seq uint) uint {
+	left, right := seq/1000, seq%1000
+	for round := 0; round < 4; round++ {
+		mac := hmac.New(sha256.New, key)
+		fmt.Fprintf(mac, "%s:%d:%d", date, round, right)
+		f := uint(binary.BigEndian.Uint32(mac.Sum(nil)[:4])) % 1000
+		left, right = right, (left+f)%1000
+	}
+	return left*1000 + right
+}
+
+// NormalizeOrderNumber แปลงเลขที่ Order ที่ผู้ใช้พิมพ์มา (มีช่องว่างหรือขีดก็ได้) เป็นรูปแบบมาตรฐาน
+// คืน false ถ้ารูปแบบผิดหรือ Check Digit ไม่ตรง (เช่น พิมพ์ผิด 1 หลัก หรือสลับหลักที่ติดกัน)
+func NormalizeOrderNumber(input string) (string, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, input)
+
+	if len(digits) < len(orderNumberDateLayout)+5 {
+		return "", false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	body, check := digits[:len(digits)-1], digits[len(digits)-1]
+	if luhnCheckDigit(body) != check {
+		return "", false
+	}
+	return formatOrderNumber(digits), true
+}
+
+func formatOrderNumber(digits string) string {
+	return digits[:len(orderNumberDateLayout)] + "-" + digits[len(orderNumberDateLayout):]
+}
+
+// luhnCheckDigit คำนวณ Check Digit แบบ Luhn (mod 10) ของตัวเลขทั้งหมดใน digits
+func luhnCheckDigit(digits string) byte {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
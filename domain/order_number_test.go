@@ -0,0 +1,70 @@
+package domain
+
+import "testing"
+
+var testOrderNumberKey = []byte("test-order-number-key")
+
+func TestLuhnCheckDigit(t *testing.T) {
+	cases := map[string]byte{
+		"7992739871":      '3',
+		"0":               '0',
+		"453914880343646": '7',
+	}
+	for digits, want := range cases {
+		if got := luhnCheckDigit(digits); got != want {
+			t.Errorf("luhnCheckDigit(%q) = %c, want %c", digits, got, want)
+		}
+	}
+}
+
+func TestPermuteOrderSequenceIsBijective(t *testing.T) {
+	seen := make([]bool, orderNumberSpace)
+	for seq := uint(0); seq < orderNumberSpace; seq++ {
+		p := permuteOrderSequence(testOrderNumberKey, "261017", seq)
+		if p >= orderNumberSpace {
+			t.Fatalf("permuteOrderSequence(%d) = %d is out of range", seq, p)
+		}
+		if seen[p] {
+			t.Fatalf("permuteOrderSequence(%d) = %d collides with an earlier sequence", seq, p)
+		}
+		seen[p] = true
+	}
+}
+
+func TestNewOrderNumber(t *testing.T) {
+	if got := NewOrderNumber(testOrderNumberKey, "261017", 427); got != "261017-2959003" {
+		t.Errorf("NewOrderNumber(261017, 427) = %s, want 261017-2959003", got)
+	}
+	if a, b := NewOrderNumber(testOrderNumberKey, "261017", 1), NewOrderNumber(testOrderNumberKey, "261018", 1); a[7:] == b[7:] {
+		t.Errorf("the same sequence on different days should not share a number: %s, %s", a, b)
+	}
+	if got := NewOrderNumber(testOrderNumberKey, "261017", orderNumberSpace); got[:14] != "261017-1000000" {
+		t.Errorf("overflowing sequence should be used as-is, got %s", got)
+	}
+}
+
+// เลขที่ Order ต้องขึ้นกับ Key ลับ คนที่ไม่รู้ Key จึงย้อนกลับเป็นลำดับของวันไม่ได้
+func TestNewOrderNumberDependsOnKey(t *testing.T) {
+	other := []byte("another-order-number-key")
+	for seq := uint(1); seq <= 100; seq++ {
+		a, b := NewOrderNumber(testOrderNumberKey, "261017", seq), NewOrderNumber(other, "261017", seq)
+		if a == b {
+			t.Errorf("sequence %d gives %s under both keys", seq, a)
+		}
+	}
+}
+
+func TestNormalizeOrderNumber(t *testing.T) {
+	number := NewOrderNumber(testOrderNumberKey, "261017", 427)
+	for _, input := range []string{number, "261017 2959003", " 2610172959003"} {
+		got, ok := NormalizeOrderNumber(input)
+		if !ok || got != number {
+			t.Errorf("NormalizeOrderNumber(%q) = %q, %v; want %q, true", input, got, ok, number)
+		}
+	}
+	for _, input := range []string{"261017-2959004", "261017-2995003", "261017-29590", "26101A-2959003", ""} {
+		if got, ok := NormalizeOrderNumber(input); ok {
+			t.Errorf("NormalizeOrderNumber(%q) = %q, true; want false", input, got)
+		}
+	}
+}
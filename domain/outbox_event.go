@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxStatus คือสถานะการส่ง Event ออกไปยังระบบภายนอก
+type OutboxStatus string
+
+const (
+	OutboxPending    OutboxStatus = "pending"    // รอส่ง (รวมถึงที่ส่งไม่สำเร็จและรอลองใหม่)
+	OutboxProcessing OutboxStatus = "processing" // Relay จองไปส่งแล้ว ถ้าเลย LockedUntil โดยไม่มีผลถือว่า Relay ล่มและจองใหม่ได้
+	OutboxPublished  OutboxStatus = "published"  // ส่งสำเร็จแล้ว
+	OutboxFailed     OutboxStatus = "failed"     // ลองส่งครบจำนวนครั้งแล้วยังไม่สำเร็จ ต้องให้ Admin สั่งส่งใหม่
+)
+
+// ประเภทของ Aggregate ที่ Event อ้างถึง
+const (
+	AggregateOrder   = "order"
+	AggregateProduct = "product"
+	AggregatePayment = "payment"
+)
+
+// ประเภทของ Domain Event (ระบบปลายทางใช้แยกว่าจะสนใจ Event ไหน)
+const (
+	EventOrderCreated         = "order.created"
+	EventOrderUpdated         = "order.updated" // Admin แก้ไขรายการสินค้าก่อนจัดส่ง
+	EventOrderPaid            = "order.paid"
+	EventOrderShipped         = "order.shipped"
+	EventOrderCompleted       = "order.completed"
+	EventOrderCancelled       = "order.cancelled"
+	EventOrderRefunded        = "order.refunded"
+	EventShipmentCreated      = "shipment.created"
+	EventPaymentRefunded      = "payment.refunded"
+	EventInventoryStockChange = "inventory.stock_changed"
+)
+
+// OutboxEvent คือ Domain Event ที่ถูกบันทึกใน Transaction เดียวกับข้อมูลที่เปลี่ยน (Transactional Outbox)
+// Relay จะอ่านแถวที่ยังไม่ส่งไป Publish ภายหลัง จึงรับประกันว่า Event ไม่หายแม้ระบบปลายทางล่ม (At-least-once)
+// ระบบปลายทางอาจได้รับ Event เดิมซ้ำ ต้องใช้ EventID กันการประมวลผลซ้ำเอง
+type OutboxEvent struct {
+	gorm.Model
+	EventID       string       `gorm:"type:varchar(36);not null;uniqueIndex"`
+	EventType     string       `gorm:"type:varchar(100);not null;index"`
+	AggregateType string       `gorm:"type:varchar(50);not null;index:idx_outbox_aggregate"`
+	AggregateID   uint         `gorm:"not null;index:idx_outbox_aggregate"`
+	Payload       []byte       `gorm:"type:jsonb;not null"`
+	OccurredAt    time.Time    `gorm:"not null"`
+	Status        OutboxStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_due"`
+	Attempts      int          `gorm:"not null;default:0"`
+	NextAttemptAt time.Time    `gorm:"not null;index:idx_outbox_due"`
+	LastError     string       `gorm:"type:varchar(500)"`
+	LockedUntil   *time.Time   // หมดเวลาจองของ Relay (เฉพาะสถานะ processing)
+	PublishedAt   *time.Time
+}
+
+// NewOutboxEvent สร้าง Event ใหม่พร้อม EventID และแปลง payload เป็น JSON
+func NewOutboxEvent(aggregateType string, aggregateID uint, eventType string, payload interface{}) (*OutboxEvent, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+	now := time.Now().UTC()
+	return &OutboxEvent{
+		EventID:       uuid.NewString(),
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       body,
+		OccurredAt:    now,
+		Status:        OutboxPending,
+		NextAttemptAt: now,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PaymentStatus คือสถานะของการชำระเงินแต่ละครั้ง
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending"            // สร้าง Intent แล้ว รอ Capture
+	PaymentStatusCaptured          PaymentStatus = "captured"           // ตัดเงินสำเร็จ
+	PaymentStatusFailed            PaymentStatus = "failed"             // ตัดเงินไม่สำเร็จ
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded" // คืนเงินบางส่วนแล้ว
+	PaymentStatusRefunded          PaymentStatus = "refunded"           // คืนเงินเต็มจำนวนแล้ว
+)
+
+// Payment คือการชำระเงิน 1 ครั้งของ Order ผ่าน PaymentGateway
+// 1 Order มีได้หลาย Payment (เช่น ครั้งแรกถูกปฏิเสธแล้วจ่ายใหม่)
+type Payment struct {
+	gorm.Model
+	OrderID           uint `gorm:"not null;index"`
+	Order             Order
+	Provider          string        `gorm:"type:varchar(50);not null"`
+	ProviderPaymentID string        `gorm:"type:varchar(255);not null;uniqueIndex"` // ID ของ Payment ฝั่ง Gateway
+	Method            string        `gorm:"type:varchar(50);not null"`
+	Amount            float64       `gorm:"not null"`
+	Currency          string        `gorm:"type:varchar(3);not null;default:'THB'"`
+	Status            PaymentStatus `gorm:"type:varchar(30);not null;default:'pending'"`
+	RefundedAmount    float64       `gorm:"not null;default:0"`
+	FailureReason     string        `gorm:"type:varchar(255)"`
+	RefundReason      string        `gorm:"type:varchar(255)"`
+	CapturedAt        *time.Time
+}
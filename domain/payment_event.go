@@ -0,0 +1,26 @@
+package domain
+
+import "gorm.io/gorm"
+
+// PaymentEventResult คือผลของการประมวลผล Payment Event แต่ละครั้ง
+type PaymentEventResult string
+
+const (
+	PaymentEventApplied   PaymentEventResult = "applied"   // เปลี่ยนสถานะ Order สำเร็จ
+	PaymentEventIgnored   PaymentEventResult = "ignored"   // รับทราบแต่ไม่เปลี่ยนสถานะ (เช่น Transition ไม่ถูกต้อง)
+	PaymentEventDuplicate PaymentEventResult = "duplicate" // เคยได้รับ Event นี้แล้ว
+)
+
+// PaymentEvent คือ Log ของ Webhook ทุกครั้งที่ได้รับจาก Payment Gateway
+// Provider + EventID เป็น Unique เพื่อกันการประมวลผล Event เดิมซ้ำ (Replay)
+type PaymentEvent struct {
+	gorm.Model
+	Provider      string             `gorm:"type:varchar(50);not null;uniqueIndex:idx_payment_event_provider_event"`
+	EventID       string             `gorm:"type:varchar(255);not null;uniqueIndex:idx_payment_event_provider_event"`
+	OrderID       uint               `gorm:"not null;index"`
+	Status        string             `gorm:"type:varchar(20);not null"` // success / failed ตามที่ Gateway ส่งมา
+	PaymentMethod string             `gorm:"type:varchar(50)"`
+	Payload       []byte             // Body ดิบที่ได้รับ (ใช้ตรวจสอบย้อนหลัง)
+	Result        PaymentEventResult `gorm:"type:varchar(20)"`
+	Note          string             `gorm:"type:varchar(255)"`
+}
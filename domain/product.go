@@ -13,6 +13,22 @@ type Product struct {
 	Images      []ProductImage `gorm:"foreignKey:ProductID" json:"images"`
 	CategoryID  uint           `json:"category_id"`
 	Category    Category       `gorm:"foreignKey:CategoryID" json:"category"`
+	TaxClassID  *uint          `json:"tax_class_id"` // nil = ใช้ Tax Class เริ่มต้นของระบบ
+	TaxClass    *TaxClass      `json:"tax_class,omitempty"`
+	Weight      float64        `json:"weight" gorm:"not null;default:0"` // น้ำหนักต่อชิ้น (กิโลกรัม) ใช้คิดค่าจัดส่ง
+}
+
+// PrimaryImagePath คืน Path ของรูปหลัก ถ้าไม่มีรูปที่ตั้งเป็นรูปหลักจะใช้รูปแรกแทน
+func (p *Product) PrimaryImagePath() string {
+	for _, img := range p.Images {
+		if img.IsPrimary {
+			return img.Path
+		}
+	}
+	if len(p.Images) > 0 {
+		return p.Images[0].Path
+	}
+	return ""
 }
 
 type ProductListDTO struct {
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReturnStatus คือสถานะของคำขอคืนสินค้า (RMA)
+type ReturnStatus string
+
+const (
+	ReturnRequested ReturnStatus = "requested" // ลูกค้าส่งคำขอแล้ว รอ Admin ตรวจสอบ
+	ReturnApproved  ReturnStatus = "approved"  // อนุมัติแล้ว รอรับสินค้าคืน
+	ReturnRejected  ReturnStatus = "rejected"  // ไม่อนุมัติ
+	ReturnReceived  ReturnStatus = "received"  // ได้รับสินค้าคืนแล้ว
+	ReturnRefunded  ReturnStatus = "refunded"  // คืนเงินแล้ว (ทั้งหมดหรือบางส่วน)
+)
+
+// returnStatusTransitions คือสถานะที่คำขอคืนสินค้าเปลี่ยนไปได้ (แนวเดียวกับ orderStatusTransitions)
+// คืนเงินก่อนได้รับของได้ และคืนเงินเพิ่มได้หลายครั้ง
+var returnStatusTransitions = map[ReturnStatus][]ReturnStatus{
+	ReturnRequested: {ReturnApproved, ReturnRejected},
+	ReturnApproved:  {ReturnReceived, ReturnRefunded},
+	ReturnReceived:  {ReturnRefunded},
+	ReturnRefunded:  {ReturnReceived, ReturnRefunded},
+}
+
+// CanTransitionTo ตรวจสอบว่าสามารถเปลี่ยนจากสถานะปัจจุบันไปเป็น next ได้หรือไม่
+func (s ReturnStatus) CanTransitionTo(next ReturnStatus) bool {
+	for _, allowed := range returnStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ReturnRequest คือคำขอคืนสินค้าบางรายการของ Order ที่ได้รับของแล้ว
+type ReturnRequest struct {
+	gorm.Model
+	OrderID        uint         `gorm:"not null;index"`
+	UserID         uint         `gorm:"not null;index"`
+	Status         ReturnStatus `gorm:"type:varchar(20);not null;default:'requested'"`
+	Reason         string       `gorm:"type:varchar(500);not null"`
+	AdminNote      string       `gorm:"type:varchar(500)"`
+	ReviewedBy     *uint
+	ReviewedAt     *time.Time
+	ReceivedAt     *time.Time
+	Restocked      bool          `gorm:"not null;default:false"`
+	RefundedAmount float64       `gorm:"not null;default:0"`
+	Items          []ReturnItem  `gorm:"foreignKey:ReturnRequestID"`
+	Photos         []ReturnPhoto `gorm:"foreignKey:ReturnRequestID"`
+}
+
+// ReturnItem คือจำนวนของ OrderItem ที่ขอคืน
+type ReturnItem struct {
+	gorm.Model
+	ReturnRequestID uint `gorm:"not null;index"`
+	OrderItemID     uint `gorm:"not null;index"`
+	OrderItem       OrderItem
+	Quantity        uint `gorm:"not null"`
+}
+
+// ReturnPhoto คือรูปประกอบคำขอคืนสินค้า เก็บเป็น Path ใน UploadRepository
+type ReturnPhoto struct {
+	gorm.Model
+	ReturnRequestID uint   `gorm:"not null;index"`
+	Path            string `gorm:"type:varchar(255);not null"`
+}
+
+// OrderRefund คือการคืนเงิน 1 ครั้งที่บันทึกไว้กับ Order
+// PaymentID เป็น nil หมายถึงคืนเงินนอกระบบ (เช่น โอนคืนให้ลูกค้าที่จ่ายด้วยการโอนเงิน)
+type OrderRefund struct {
+	gorm.Model
+	OrderID         uint  `gorm:"not null;index"`
+	ReturnRequestID *uint `gorm:"index"`
+	PaymentID       *uint
+	Amount          float64 `gorm:"not null"`
+	TaxAmount       float64 `gorm:"not null;default:0"` // ภาษีที่รวมอยู่ใน Amount (ใช้ลดยอดขายแบบไม่รวมภาษี)
+	Reason          string  `gorm:"type:varchar(255)"`
+	RefundedBy      *uint
+	Adjustment      bool `gorm:"not null;default:false"` // คืนส่วนต่างจากการแก้ไข Order (FinalPrice ลดลงแล้ว จึงไม่นับใน Order.RefundedAmount)
+}
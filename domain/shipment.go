@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShipmentStatus คือสถานะของพัสดุแต่ละกล่อง
+type ShipmentStatus string
+
+const (
+	ShipmentInTransit      ShipmentStatus = "in_transit"       // ส่งมอบให้ขนส่งแล้ว
+	ShipmentOutForDelivery ShipmentStatus = "out_for_delivery" // อยู่ระหว่างนำจ่าย
+	ShipmentDelivered      ShipmentStatus = "delivered"        // ผู้รับได้รับพัสดุแล้ว
+	ShipmentFailed         ShipmentStatus = "failed"           // นำจ่ายไม่สำเร็จ รอขนส่งแจ้งสถานะถัดไป
+	ShipmentReturned       ShipmentStatus = "returned"         // พัสดุตีกลับถึงผู้ส่ง
+)
+
+// IsFinal คืนค่า true ถ้าพัสดุถึงปลายทางแล้ว (ส่งสำเร็จหรือตีกลับ) ขนส่งจะไม่เปลี่ยนสถานะอีก
+func (s ShipmentStatus) IsFinal() bool {
+	return s == ShipmentDelivered || s == ShipmentReturned
+}
+
+// CODStatus คือสถานะการเก็บเงินปลายทางของพัสดุ (ว่าง = ไม่ใช่พัสดุเก็บเงินปลายทาง)
+type CODStatus string
+
+const (
+	CODPending  CODStatus = "pending"  // รอขนส่งโอนเงินที่เก็บได้
+	CODRemitted CODStatus = "remitted" // ขนส่งโอนเงินครบตามยอดแล้ว
+	CODMismatch CODStatus = "mismatch" // ยอดที่ขนส่งโอนมาไม่ตรงกับยอดที่ต้องเก็บ รอ Admin ตรวจสอบ
+	CODVoid     CODStatus = "void"     // พัสดุตีกลับ ไม่มียอดที่ต้องเก็บจากกล่องนี้แล้ว
+)
+
+// Shipment คือพัสดุ 1 กล่องของ Order (1 Order แบ่งส่งได้หลายกล่อง จากหลายคลัง)
+type Shipment struct {
+	gorm.Model
+	OrderID        uint           `gorm:"not null;index"`
+	Carrier        string         `gorm:"type:varchar(100);not null;uniqueIndex:idx_shipment_carrier_tracking"`
+	TrackingNumber string         `gorm:"type:varchar(100);not null;uniqueIndex:idx_shipment_carrier_tracking"`
+	Origin         string         `gorm:"type:varchar(100)"` // คลังหรือสาขาที่ส่งออก
+	Status         ShipmentStatus `gorm:"type:varchar(20);not null"`
+	CreatedBy      *uint
+	LastEventAt    *time.Time // เวลาของ Event ล่าสุดที่ใช้กำหนด Status (กัน Event ที่มาช้ากว่าเขียนทับ)
+	DeliveredAt    *time.Time
+
+	// เก็บเงินปลายทาง: ยอดที่ขนส่งต้องเก็บจากผู้รับสำหรับกล่องนี้ และยอดที่ขนส่งโอนคืนมาแล้ว
+	CODAmount         float64   `gorm:"not null;default:0"`
+	CODStatus         CODStatus `gorm:"type:varchar(20);index"`
+	CODRemittedAmount float64   `gorm:"not null;default:0"`
+	CODRemittedAt     *time.Time
+	CODRemittanceRef  string `gorm:"type:varchar(100)"` // เลขอ้างอิงรอบการโอนของขนส่ง
+
+	Items  []ShipmentItem  `gorm:"foreignKey:ShipmentID"`
+	Events []TrackingEvent `gorm:"foreignKey:ShipmentID"`
+}
+
+// ShipmentItem คือจำนวนของ OrderItem ที่อยู่ในพัสดุกล่องนี้
+type ShipmentItem struct {
+	gorm.Model
+	ShipmentID  uint `gorm:"not null;index"`
+	OrderItemID uint `gorm:"not null;index"`
+	Quantity    uint `gorm:"not null"`
+}
+
+// TrackingEvent คือประวัติสถานะพัสดุที่ได้รับจากขนส่ง
+// ShipmentID + ExternalID เป็น Unique เพื่อกันการบันทึก Event เดิมซ้ำเมื่อขนส่งส่งมาใหม่
+type TrackingEvent struct {
+	gorm.Model
+	ShipmentID  uint           `gorm:"not null;uniqueIndex:idx_tracking_event_shipment_external"`
+	ExternalID  string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_tracking_event_shipment_external"`
+	Status      ShipmentStatus `gorm:"type:varchar(20);not null"`
+	Description string         `gorm:"type:varchar(255)"`
+	Location    string         `gorm:"type:varchar(255)"`
+	OccurredAt  time.Time      `gorm:"not null"`
+}
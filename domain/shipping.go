@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ShippingMethodType คือประเภทการคิดค่าจัดส่ง
+type ShippingMethodType string
+
+const (
+	ShippingFlatRate          ShippingMethodType = "flat_rate"           // ราคาเดียว
+	ShippingWeightBased       ShippingMethodType = "weight_based"        // ราคาเริ่มต้น + ราคาต่อกิโลกรัม
+	ShippingFreeOverThreshold ShippingMethodType = "free_over_threshold" // ราคาเดียว แต่ส่งฟรีเมื่อยอดถึงเกณฑ์
+	ShippingStorePickup       ShippingMethodType = "store_pickup"        // รับสินค้าที่ร้าน ไม่มีค่าจัดส่ง
+)
+
+// ShippingZone คือพื้นที่จัดส่ง ใช้จับคู่กับที่อยู่ด้วยประเทศ, จังหวัด/รัฐ และรหัสไปรษณีย์
+// States และ PostalCodes เป็นรายการคั่นด้วยจุลภาค ว่าง = ทั้งหมด
+// PostalCodes รองรับแบบตรงตัว ("10110"), ขึ้นต้นด้วย ("10*") และช่วง ("10100-10299")
+type ShippingZone struct {
+	gorm.Model
+	Name        string `json:"name" gorm:"type:varchar(100);not null"`
+	Country     string `json:"country" gorm:"type:varchar(100)"`
+	States      string `json:"states" gorm:"type:varchar(1000)"`
+	PostalCodes string `json:"postal_codes" gorm:"type:varchar(1000)"`
+}
+
+// Match ตรวจว่าที่อยู่อยู่ในโซนนี้หรือไม่ และคืนคะแนนความเจาะจง (โซนที่เจาะจงกว่าจะถูกเลือกก่อน)
+func (z *ShippingZone) Match(a *Address) (bool, int) {
+	score := 0
+	if z.Country != "" {
+		if !strings.EqualFold(z.Country, a.Country) {
+			return false, 0
+		}
+		score++
+	}
+	if z.States != "" {
+		if !matchAny(z.States, func(s string) bool { return strings.EqualFold(s, a.State) }) {
+			return false, 0
+		}
+		score += 2
+	}
+	if z.PostalCodes != "" {
+		if !matchAny(z.PostalCodes, func(p string) bool { return matchPostalCode(p, a.PostalCode) }) {
+			return false, 0
+		}
+		score += 4
+	}
+	return true, score
+}
+
+func matchAny(list string, fn func(string) bool) bool {
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" && fn(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPostalCode(pattern, code string) bool {
+	code = strings.TrimSpace(code)
+	switch {
+	case strings.HasSuffix(pattern, "*"):
+		return strings.HasPrefix(code, strings.TrimSuffix(pattern, "*"))
+	case strings.Contains(pattern, "-"):
+		bounds := strings.SplitN(pattern, "-", 2)
+		low, high := strings.TrimSpace(bounds[0]), strings.TrimSpace(bounds[1])
+		// เทียบแบบ String ได้เมื่อความยาวเท่ากัน (รหัสไปรษณีย์ไทยยาว 5 หลักเสมอ)
+		return len(code) == len(low) && len(code) == len(high) && code >= low && code <= high
+	default:
+		return strings.EqualFold(pattern, code)
+	}
+}
+
+// ShippingMethod คือวิธีจัดส่งที่ลูกค้าเลือกได้ตอน Checkout
+// ZoneID เป็น nil หมายถึงใช้ได้ทุกพื้นที่ (เช่น รับที่ร้าน)
+type ShippingMethod struct {
+	gorm.Model
+	ZoneID        *uint              `json:"zone_id" gorm:"index"`
+	Zone          *ShippingZone      `json:"zone,omitempty"`
+	Name          string             `json:"name" gorm:"type:varchar(100);not null"`
+	Carrier       string             `json:"carrier" gorm:"type:varchar(100)"` // เช่น Kerry, Flash, ไปรษณีย์ไทย
+	Type          ShippingMethodType `json:"type" gorm:"type:varchar(30);not null"`
+	Rate          float64            `json:"rate" gorm:"not null;default:0"`           // ราคาคงที่ หรือราคาเริ่มต้นของแบบคิดตามน้ำหนัก
+	PerKgRate     float64            `json:"per_kg_rate" gorm:"not null;default:0"`    // ใช้กับ weight_based
+	FreeThreshold float64            `json:"free_threshold" gorm:"not null;default:0"` // ใช้กับ free_over_threshold
+	IsActive      bool               `json:"is_active" gorm:"not null;default:true"`
+	SortOrder     int                `json:"sort_order" gorm:"not null;default:0"`
+}
+
+// CalculateCost คำนวณค่าจัดส่งจากยอดสินค้า (หลังหักส่วนลด) และน้ำหนักรวม (กิโลกรัม)
+// น้ำหนักถูกปัดขึ้นเป็นกิโลกรัมเต็มเหมือนที่ขนส่งส่วนใหญ่คิด
+func (m *ShippingMethod) CalculateCost(subtotal, weightKg float64) float64 {
+	switch m.Type {
+	case ShippingFlatRate:
+		return m.Rate
+	case ShippingWeightBased:
+		return m.Rate + m.PerKgRate*math.Ceil(weightKg)
+	case ShippingFreeOverThreshold:
+		if subtotal >= m.FreeThreshold {
+			return 0
+		}
+		return m.Rate
+	default: // ShippingStorePickup
+		return 0
+	}
+}
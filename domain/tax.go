@@ -0,0 +1,33 @@
+package domain
+
+import "gorm.io/gorm"
+
+// TaxClass คือกลุ่มภาษีของสินค้า เช่น standard (VAT ปกติ), exempt (ยกเว้นภาษี)
+type TaxClass struct {
+	gorm.Model
+	Code string `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name string `json:"name" gorm:"type:varchar(100)"`
+}
+
+// TaxRate คืออัตราภาษีของ TaxClass ในแต่ละประเทศ/ภูมิภาค
+// Country หรือ State ว่าง หมายถึงใช้ได้ทุกประเทศ/ทุกภูมิภาค (อัตราที่ระบุเจาะจงกว่าจะถูกเลือกก่อน)
+type TaxRate struct {
+	gorm.Model
+	TaxClassID uint     `json:"tax_class_id" gorm:"not null;index"`
+	TaxClass   TaxClass `json:"tax_class"`
+	Name       string   `json:"name" gorm:"type:varchar(100);not null"` // เช่น "VAT 7%"
+	Country    string   `json:"country" gorm:"type:varchar(100)"`
+	State      string   `json:"state" gorm:"type:varchar(100)"`
+	Rate       float64  `json:"rate" gorm:"not null"` // 0.07 = 7%
+}
+
+// OrderTaxLine คือยอดภาษีของ Order แยกตามอัตรา (บันทึกตอน Checkout)
+type OrderTaxLine struct {
+	gorm.Model
+	OrderID       uint    `gorm:"not null;index"`
+	TaxClassCode  string  `gorm:"type:varchar(50)"`
+	Name          string  `gorm:"type:varchar(100)"`
+	Rate          float64 `gorm:"not null"`
+	TaxableAmount float64 `gorm:"not null"` // มูลค่าก่อนภาษี
+	TaxAmount     float64 `gorm:"not null"`
+}
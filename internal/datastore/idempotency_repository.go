@@ -0,0 +1,97 @@
+package datastore
+
+import (
+	"backend/domain"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrIdempotencyNotFound = errors.New("idempotency key not found")
+	ErrIdempotencyKeyInUse = errors.New("a request with this Idempotency-Key has already been processed")
+)
+
+type IdempotencyRepository interface {
+	Create(record *domain.IdempotencyKey) error
+	FindByKey(userID uint, key string) (*domain.IdempotencyKey, error)
+	SaveResponse(userID uint, key string, statusCode int, contentType string, body []byte) error
+	ReleaseStale(userID uint, key string, before time.Time) (bool, error)
+	DeleteBefore(cutoff time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Create จองคีย์ไว้ ถ้ามีคีย์นี้อยู่แล้ว (หรือมี Transaction อื่นกำลังจองอยู่) จะคืน ErrIdempotencyKeyInUse
+// ใช้ ON CONFLICT DO NOTHING เพื่อไม่ให้ Transaction ทั้งก้อนพังจาก Unique Violation
+func (r *idempotencyRepository) Create(record *domain.IdempotencyKey) error {
+	tx := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrIdempotencyKeyInUse
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) FindByKey(userID uint, key string) (*domain.IdempotencyKey, error) {
+	var record domain.IdempotencyKey
+	err := r.db.Where("user_id = ? AND key = ?", userID, key).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIdempotencyNotFound
+	}
+	return &record, err
+}
+
+// SaveResponse บันทึก Response ของ Request แรก เพื่อใช้ตอบกลับเมื่อมี Request ซ้ำเข้ามา
+// ถ้าคีย์ไม่ได้ถูกบันทึกไว้ (เช่น Transaction ถูก Rollback) ก็จะไม่มีอะไรเกิดขึ้น
+func (r *idempotencyRepository) SaveResponse(userID uint, key string, statusCode int, contentType string, body []byte) error {
+	now := time.Now()
+	return r.db.Model(&domain.IdempotencyKey{}).
+		Where("user_id = ? AND key = ? AND status_code = 0", userID, key).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+			"completed_at":  &now,
+		}).Error
+}
+
+// ReleaseStale ลบคีย์ที่ยังไม่มี Response และถูกสร้างก่อน before ทิ้ง เพื่อให้ Request ใหม่ใช้คีย์นี้ได้
+// คืน false ถ้าคีย์ไม่ได้ค้าง (เช่น มี Response แล้ว หรือมี Request อื่นเคลียร์ไปก่อน)
+// ลบแบบถาวรเพราะ Unique Index นับแถวที่ Soft Delete ด้วย
+func (r *idempotencyRepository) ReleaseStale(userID uint, key string, before time.Time) (bool, error) {
+	tx := r.db.Unscoped().
+		Where("user_id = ? AND key = ? AND status_code = 0 AND created_at < ?", userID, key, before).
+		Delete(&domain.IdempotencyKey{})
+	return tx.RowsAffected > 0, tx.Error
+}
+
+// DeleteBefore ลบคีย์ที่สร้างก่อน cutoff ทิ้งถาวร (กันตารางโตไม่จำกัด)
+func (r *idempotencyRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	tx := r.db.Unscoped().Where("created_at < ?", cutoff).Delete(&domain.IdempotencyKey{})
+	return tx.RowsAffected, tx.Error
+}
+
+type idempotencyContextKey struct{}
+
+// WithIdempotencyKey แนบคีย์ไปกับ Context เพื่อให้ ExecuteWithContext บันทึกคีย์ใน Transaction เดียวกับงานหลัก
+func WithIdempotencyKey(ctx context.Context, record *domain.IdempotencyKey) context.Context {
+	return context.WithValue(ctx, idempotencyContextKey{}, record)
+}
+
+// IdempotencyKeyFromContext ดึงคีย์ที่แนบไว้กับ Context (ถ้าไม่มีจะคืน nil)
+func IdempotencyKeyFromContext(ctx context.Context) *domain.IdempotencyKey {
+	record, _ := ctx.Value(idempotencyContextKey{}).(*domain.IdempotencyKey)
+	return record
+}
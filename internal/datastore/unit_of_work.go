@@ -1,12 +1,20 @@
 package datastore
 
 import (
+	"context"
+
 	cartRepo "backend/carts/repository"
 	categoryRepo "backend/categories/repository"
 	cuponRepo "backend/coupons/repository"
 	dashboardRepo "backend/dashboard/repository"
+	invoiceRepo "backend/invoices/repository"
 	orderRepo "backend/orders/repository"
+	outboxRepo "backend/outbox/repository"
+	paymentRepo "backend/payments/repository"
 	productRepo "backend/products/repository"
+	returnRepo "backend/returns/repository"
+	shippingRepo "backend/shipping/repository"
+	taxRepo "backend/taxes/repository"
 	userRepo "backend/users/repository"
 
 	"gorm.io/gorm"
@@ -15,20 +23,31 @@ import (
 // Repositories คือ struct ที่รวบรวม "Interface" ของ Repository ทั้งหมด
 // ที่ต้องการทำงานภายใต้ Transaction เดียวกัน
 type Repositories struct {
-	User      userRepo.UserRepository
-	Address   userRepo.AddressRepository
-	Product   productRepo.ProductRepository
-	Category  categoryRepo.CategoryRepository
-	Cart      cartRepo.CartRepository
-	Order     orderRepo.OrderRepository
-	Coupon    cuponRepo.CouponRepository
-	Dashboard dashboardRepo.DashboardRepository
+	User        userRepo.UserRepository
+	Address     userRepo.AddressRepository
+	Product     productRepo.ProductRepository
+	Category    categoryRepo.CategoryRepository
+	Cart        cartRepo.CartRepository
+	Order       orderRepo.OrderRepository
+	Coupon      cuponRepo.CouponRepository
+	Dashboard   dashboardRepo.DashboardRepository
+	Idempotency IdempotencyRepository
+	Payment     paymentRepo.PaymentRepository
+	Invoice     invoiceRepo.InvoiceRepository
+	Tax         taxRepo.TaxRepository
+	Shipping    shippingRepo.ShippingRepository
+	Shipment    orderRepo.ShipmentRepository
+	Return      returnRepo.ReturnRepository
+	Outbox      outboxRepo.OutboxRepository
 }
 
 // UnitOfWork คือ Interface หลักที่ Service จะเรียกใช้
 type UnitOfWork interface {
 	// Execute จะรับฟังก์ชันเข้ามาทำงานภายใน Database Transaction
 	Execute(fn func(repos *Repositories) error) error
+	// ExecuteWithContext ทำงานเหมือน Execute แต่ถ้า ctx มี Idempotency Key แนบมา (ดู WithIdempotencyKey)
+	// จะบันทึกคีย์ใน Transaction เดียวกัน ทำให้คีย์และข้อมูลที่สร้าง Commit หรือ Rollback พร้อมกัน
+	ExecuteWithContext(ctx context.Context, fn func(repos *Repositories) error) error
 
 	// เรามี Getter สำหรับ Repository ที่ไม่เกี่ยวกับ DB Transaction ด้วย (เช่น Azure)
 	ProductRepository() productRepo.ProductRepository
@@ -40,54 +59,102 @@ type UnitOfWork interface {
 	DashboardRepository() dashboardRepo.DashboardRepository
 	OrderRepository() orderRepo.OrderRepository
 	UploadRepository() UploadRepository
+	IdempotencyRepository() IdempotencyRepository
+	PaymentRepository() paymentRepo.PaymentRepository
+	InvoiceRepository() invoiceRepo.InvoiceRepository
+	TaxRepository() taxRepo.TaxRepository
+	ShippingRepository() shippingRepo.ShippingRepository
+	ShipmentRepository() orderRepo.ShipmentRepository
+	ReturnRepository() returnRepo.ReturnRepository
+	OutboxRepository() outboxRepo.OutboxRepository
 }
 
 // unitOfWork คือ struct ที่ทำงานจริง
 type unitOfWork struct {
-	db            *gorm.DB
-	userRepo      userRepo.UserRepository
-	addressRepo   userRepo.AddressRepository
-	categoryRepo  categoryRepo.CategoryRepository
-	productRepo   productRepo.ProductRepository
-	couponRepo    cuponRepo.CouponRepository
-	dashboardRepo dashboardRepo.DashboardRepository
-	cartRepo      cartRepo.CartRepository
-	orderRepo     orderRepo.OrderRepository
-	uploadRepo    UploadRepository
+	db              *gorm.DB
+	userRepo        userRepo.UserRepository
+	addressRepo     userRepo.AddressRepository
+	categoryRepo    categoryRepo.CategoryRepository
+	productRepo     productRepo.ProductRepository
+	couponRepo      cuponRepo.CouponRepository
+	dashboardRepo   dashboardRepo.DashboardRepository
+	cartRepo        cartRepo.CartRepository
+	orderRepo       orderRepo.OrderRepository
+	uploadRepo      UploadRepository
+	idempotencyRepo IdempotencyRepository
+	paymentRepo     paymentRepo.PaymentRepository
+	invoiceRepo     invoiceRepo.InvoiceRepository
+	taxRepo         taxRepo.TaxRepository
+	shippingRepo    shippingRepo.ShippingRepository
+	shipmentRepo    orderRepo.ShipmentRepository
+	returnRepo      returnRepo.ReturnRepository
+	outboxRepo      outboxRepo.OutboxRepository
 }
 
 func NewUnitOfWork(db *gorm.DB, uploadRepo UploadRepository) UnitOfWork {
 	return &unitOfWork{
 		db: db,
 		// --- [เพิ่ม] สร้าง repo ทั้งหมดตอนเริ่มต้น และเก็บไว้ ---
-		userRepo:      userRepo.NewUserRepository(db),
-		addressRepo:   userRepo.NewAddressRepository(db),
-		productRepo:   productRepo.NewProductRepository(db),
-		categoryRepo:  categoryRepo.NewCategoryRepository(db),
-		cartRepo:      cartRepo.NewCartRepository(db),
-		couponRepo:    cuponRepo.NewCouponRepository(db),
-		dashboardRepo: dashboardRepo.NewDashboardRepository(db),
-		orderRepo:     orderRepo.NewOrderRepository(db),
-		uploadRepo:    uploadRepo,
+		userRepo:        userRepo.NewUserRepository(db),
+		addressRepo:     userRepo.NewAddressRepository(db),
+		productRepo:     productRepo.NewProductRepository(db),
+		categoryRepo:    categoryRepo.NewCategoryRepository(db),
+		cartRepo:        cartRepo.NewCartRepository(db),
+		couponRepo:      cuponRepo.NewCouponRepository(db),
+		dashboardRepo:   dashboardRepo.NewDashboardRepository(db),
+		orderRepo:       orderRepo.NewOrderRepository(db),
+		uploadRepo:      uploadRepo,
+		idempotencyRepo: NewIdempotencyRepository(db),
+		paymentRepo:     paymentRepo.NewPaymentRepository(db),
+		invoiceRepo:     invoiceRepo.NewInvoiceRepository(db),
+		taxRepo:         taxRepo.NewTaxRepository(db),
+		shippingRepo:    shippingRepo.NewShippingRepository(db),
+		shipmentRepo:    orderRepo.NewShipmentRepository(db),
+		returnRepo:      returnRepo.NewReturnRepository(db),
+		outboxRepo:      outboxRepo.NewOutboxRepository(db),
 	}
 }
 
 func (u *unitOfWork) Execute(fn func(repos *Repositories) error) error {
 	return u.db.Transaction(func(tx *gorm.DB) error {
-		repos := &Repositories{
-			User:      userRepo.NewUserRepository(tx),
-			Address:   userRepo.NewAddressRepository(tx),
-			Product:   productRepo.NewProductRepository(tx),
-			Category:  categoryRepo.NewCategoryRepository(tx),
-			Cart:      cartRepo.NewCartRepository(tx),
-			Order:     orderRepo.NewOrderRepository(tx),
-			Dashboard: dashboardRepo.NewDashboardRepository(tx),
-			Coupon:    cuponRepo.NewCouponRepository(tx),
+		return fn(newRepositories(tx))
+	})
+}
+
+func (u *unitOfWork) ExecuteWithContext(ctx context.Context, fn func(repos *Repositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repos := newRepositories(tx)
+		if record := IdempotencyKeyFromContext(ctx); record != nil {
+			if err := repos.Idempotency.Create(record); err != nil {
+				return err
+			}
 		}
 		return fn(repos)
 	})
 }
 
+// newRepositories สร้าง Repository ทั้งหมดที่ผูกกับ Transaction เดียวกัน
+func newRepositories(tx *gorm.DB) *Repositories {
+	return &Repositories{
+		User:        userRepo.NewUserRepository(tx),
+		Address:     userRepo.NewAddressRepository(tx),
+		Product:     productRepo.NewProductRepository(tx),
+		Category:    categoryRepo.NewCategoryRepository(tx),
+		Cart:        cartRepo.NewCartRepository(tx),
+		Order:       orderRepo.NewOrderRepository(tx),
+		Dashboard:   dashboardRepo.NewDashboardRepository(tx),
+		Coupon:      cuponRepo.NewCouponRepository(tx),
+		Idempotency: NewIdempotencyRepository(tx),
+		Payment:     paymentRepo.NewPaymentRepository(tx),
+		Invoice:     invoiceRepo.NewInvoiceRepository(tx),
+		Tax:         taxRepo.NewTaxRepository(tx),
+		Shipping:    shippingRepo.NewShippingRepository(tx),
+		Shipment:    orderRepo.NewShipmentRepository(tx),
+		Return:      returnRepo.NewReturnRepository(tx),
+		Outbox:      outboxRepo.NewOutboxRepository(tx),
+	}
+}
+
 func (u *unitOfWork) ProductRepository() productRepo.ProductRepository {
 	return u.productRepo
 }
@@ -120,3 +187,35 @@ func (u *unitOfWork) CouponRepository() cuponRepo.CouponRepository {
 func (u *unitOfWork) DashboardRepository() dashboardRepo.DashboardRepository {
 	return u.dashboardRepo
 }
+
+func (u *unitOfWork) IdempotencyRepository() IdempotencyRepository {
+	return u.idempotencyRepo
+}
+
+func (u *unitOfWork) PaymentRepository() paymentRepo.PaymentRepository {
+	return u.paymentRepo
+}
+
+func (u *unitOfWork) InvoiceRepository() invoiceRepo.InvoiceRepository {
+	return u.invoiceRepo
+}
+
+func (u *unitOfWork) TaxRepository() taxRepo.TaxRepository {
+	return u.taxRepo
+}
+
+func (u *unitOfWork) ShippingRepository() shippingRepo.ShippingRepository {
+	return u.shippingRepo
+}
+
+func (u *unitOfWork) ShipmentRepository() orderRepo.ShipmentRepository {
+	return u.shipmentRepo
+}
+
+func (u *unitOfWork) ReturnRepository() returnRepo.ReturnRepository {
+	return u.returnRepo
+}
+
+func (u *unitOfWork) OutboxRepository() outboxRepo.OutboxRepository {
+	return u.outboxRepo
+}
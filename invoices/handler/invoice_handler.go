@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"backend/domain"
+	"backend/invoices/service"
+	"backend/middleware"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type InvoiceHandler struct {
+	invoiceSvc service.InvoiceService
+}
+
+func NewInvoiceHandler(invoiceSvc service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{invoiceSvc: invoiceSvc}
+}
+
+// HandleGetInvoice ส่งไฟล์ PDF ใบเสร็จรับเงิน/ใบกำกับภาษีของ Order ให้เจ้าของหรือ Admin ดาวน์โหลด
+func (h *InvoiceHandler) HandleGetInvoice(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil || orderID <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	isAdmin := claims.Role == string(domain.RoleAdmin)
+	filename, pdf, err := h.invoiceSvc.GetInvoicePDF(claims.UserID, isAdmin, uint(orderID))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s"`, filename))
+	return c.Send(pdf)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"backend/invoices/service"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PackingHandler struct {
+	packingSvc service.PackingService
+}
+
+func NewPackingHandler(packingSvc service.PackingService) *PackingHandler {
+	return &PackingHandler{packingSvc: packingSvc}
+}
+
+// HandleGetPackingSlip ส่งใบแพ็คสินค้าของ Order (?format=pdf|html ค่าเริ่มต้นคือ pdf)
+func (h *PackingHandler) HandleGetPackingSlip(c *fiber.Ctx) error {
+	orderID, err := c.ParamsInt("id")
+	if err != nil || orderID <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	doc, err := h.packingSvc.GetPackingSlip(uint(orderID), documentFormat(c))
+	if err != nil {
+		return err
+	}
+	return sendDocument(c, doc)
+}
+
+// HandleGetPickList ส่งใบจัดสินค้ารวมของ Order ที่รอจัดส่ง
+// ?order_ids=1,2,3 เลือกเฉพาะบาง Order (ไม่ระบุ = ทุก Order ที่สถานะ processing) และ ?format=pdf|html
+func (h *PackingHandler) HandleGetPickList(c *fiber.Ctx) error {
+	var orderIDs []uint
+	if raw := c.Query("order_ids"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+			if err != nil || id == 0 {
+				return fiber.NewError(fiber.StatusBadRequest, "Invalid order_ids")
+			}
+			orderIDs = append(orderIDs, uint(id))
+		}
+	}
+
+	doc, err := h.packingSvc.GetPickList(orderIDs, documentFormat(c))
+	if err != nil {
+		return err
+	}
+	return sendDocument(c, doc)
+}
+
+func documentFormat(c *fiber.Ctx) service.DocumentFormat {
+	return service.DocumentFormat(strings.ToLower(c.Query("format", string(service.DocumentPDF))))
+}
+
+func sendDocument(c *fiber.Ctx, doc *service.Document) error {
+	c.Set(fiber.HeaderContentType, doc.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s"`, doc.Filename))
+	return c.Send(doc.Body)
+}
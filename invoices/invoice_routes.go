@@ -0,0 +1,34 @@
+package invoices
+
+import (
+	"backend/config"
+	"backend/internal/datastore"
+	"backend/invoices/handler"
+	"backend/invoices/service"
+	"backend/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"log"
+)
+
+// RegisterModule ต้องถูกเรียกก่อน orders.RegisterModule
+// เพราะกลุ่ม Admin ของ /orders จะบังคับสิทธิ์ Admin กับทุก Route ที่ลงทะเบียนหลังจากนั้น
+func RegisterModule(api fiber.Router, uow datastore.UnitOfWork, cfg *config.Config) {
+	invoiceSvc := service.NewInvoiceService(uow, cfg)
+	invoiceHdl := handler.NewInvoiceHandler(invoiceSvc)
+
+	api.Get("/orders/:id/invoice", middleware.Protected(), invoiceHdl.HandleGetInvoice)
+
+	// เอกสารสำหรับคลังสินค้า
+	packingSvc := service.NewPackingService(uow, cfg)
+	packingHdl := handler.NewPackingHandler(packingSvc)
+
+	warehouseAPI := api.Group("/admin/warehouse", middleware.Protected(), middleware.AdminRequired())
+	warehouseAPI.Get("/orders/:id/packing-slip", packingHdl.HandleGetPackingSlip)
+	warehouseAPI.Get("/pick-list", packingHdl.HandleGetPickList)
+
+	if cfg.InvoiceFontPath == "" {
+		log.Println("Warning: INVOICE_FONT_PATH is not set, invoices will be printed in English only.")
+	}
+	log.Println("✅ Invoice module registered successfully.")
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+type InvoiceRepository interface {
+	NextSequence(year int) (uint, error)
+	Create(invoice *domain.Invoice) error
+	FindByOrderID(orderID uint) (*domain.Invoice, error)
+	NextAdjustmentSequence(kind domain.InvoiceAdjustmentKind, year int) (uint, error)
+	CreateAdjustment(adjustment *domain.InvoiceAdjustment) error
+}
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+// NextSequence เพิ่มเลขลำดับของปีนั้นแล้วคืนค่าเลขใหม่
+// แถวของปีจะถูก Lock จนกว่า Transaction จะจบ ถ้า Rollback เลขก็จะถูกคืนด้วย (เลขไม่ขาดช่วง)
+// ต้องเรียกภายใน Transaction (uow.Execute) เท่านั้น
+func (r *invoiceRepository) NextSequence(year int) (uint, error) {
+	// สร้างแถวของปีใหม่ถ้ายังไม่มี (ถ้ามีแล้วไม่ต้องทำอะไร)
+	seq := domain.InvoiceSequence{Year: year}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&seq).Error; err != nil {
+		return 0, err
+	}
+
+	var next uint
+	err := r.db.Raw(
+		"UPDATE invoice_sequences SET last_number = last_number + 1 WHERE year = ? RETURNING last_number", year,
+	).Scan(&next).Error
+	return next, err
+}
+
+// Create บันทึกใบกำกับภาษีพร้อมรายการสินค้า (Lines)
+func (r *invoiceRepository) Create(invoice *domain.Invoice) error {
+	return r.db.Create(invoice).Error
+}
+
+func (r *invoiceRepository) FindByOrderID(orderID uint) (*domain.Invoice, error) {
+	var invoice domain.Invoice
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position asc")
+	}).Where("order_id = ?", orderID).First(&invoice).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// NextAdjustmentSequence เพิ่มเลขลำดับของใบลดหนี้หรือใบเพิ่มหนี้ของปีนั้นแล้วคืนค่าเลขใหม่ (Lock แบบเดียวกับ NextSequence)
+// ต้องเรียกภายใน Transaction (uow.Execute) เท่านั้น
+func (r *invoiceRepository) NextAdjustmentSequence(kind domain.InvoiceAdjustmentKind, year int) (uint, error) {
+	seq := domain.InvoiceAdjustmentSequence{Kind: kind, Year: year}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&seq).Error; err != nil {
+		return 0, err
+	}
+
+	var next uint
+	err := r.db.Raw(
+		"UPDATE invoice_adjustment_sequences SET last_number = last_number + 1 WHERE kind = ? AND year = ? RETURNING last_number", kind, year,
+	).Scan(&next).Error
+	return next, err
+}
+
+func (r *invoiceRepository) CreateAdjustment(adjustment *domain.InvoiceAdjustment) error {
+	return r.db.Create(adjustment).Error
+}
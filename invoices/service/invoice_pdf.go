@@ -0,0 +1,220 @@
+package service
+
+import (
+	"backend/domain"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// SellerInfo คือข้อมูลผู้ขายที่พิมพ์บนหัวใบกำกับภาษี
+type SellerInfo struct {
+	Name    string
+	Address string
+	TaxID   string
+	Branch  string
+}
+
+// FontConfig คือ Path ของฟอนต์ TTF ที่ใช้พิมพ์ภาษาไทย
+type FontConfig struct {
+	RegularPath string
+	BoldPath    string
+}
+
+// invoiceDoc ช่วยพิมพ์ข้อความบน PDF โดยเลือกภาษาตามฟอนต์ที่มี
+// (ฟอนต์มาตรฐานของ PDF ไม่มีอักษรไทย ถ้าไม่ได้ตั้งฟอนต์ไว้จะพิมพ์เฉพาะภาษาอังกฤษ)
+type invoiceDoc struct {
+	pdf    *fpdf.Fpdf
+	family string
+	thai   bool
+}
+
+func newInvoiceDoc(fonts FontConfig) (*invoiceDoc, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+
+	doc := &invoiceDoc{pdf: pdf, family: "Helvetica"}
+	if fonts.RegularPath != "" {
+		boldPath := fonts.BoldPath
+		if boldPath == "" {
+			boldPath = fonts.RegularPath
+		}
+		regular, err := os.ReadFile(fonts.RegularPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load invoice font: %w", err)
+		}
+		bold, err := os.ReadFile(boldPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load invoice bold font: %w", err)
+		}
+		pdf.AddUTF8FontFromBytes("thai", "", regular)
+		pdf.AddUTF8FontFromBytes("thai", "B", bold)
+		doc.family = "thai"
+		doc.thai = true
+	}
+	return doc, nil
+}
+
+// label คืนข้อความ 2 ภาษาถ้ามีฟอนต์ภาษาไทย ไม่อย่างนั้นคืนเฉพาะภาษาอังกฤษ
+func (d *invoiceDoc) label(th, en string) string {
+	if d.thai {
+		return th + " / " + en
+	}
+	return en
+}
+
+func (d *invoiceDoc) font(style string, size float64) {
+	d.pdf.SetFont(d.family, style, size)
+}
+
+func (d *invoiceDoc) line(w, h float64, text, align string) {
+	d.pdf.CellFormat(w, h, text, "", 1, align, false, 0, "")
+}
+
+// renderInvoicePDF สร้าง PDF ใบเสร็จรับเงิน/ใบกำกับภาษีจากรายการและยอดที่ Snapshot ไว้ใน Invoice
+// Order ใช้เฉพาะเลขอ้างอิงและที่อยู่จัดส่ง (Snapshot) เท่านั้น
+func renderInvoicePDF(seller SellerInfo, fonts FontConfig, invoice *domain.Invoice, order *domain.Order, buyer *domain.User) ([]byte, error) {
+	doc, err := newInvoiceDoc(fonts)
+	if err != nil {
+		return nil, err
+	}
+	pdf := doc.pdf
+	pdf.AddPage()
+
+	// --- หัวเอกสาร: ผู้ขาย ---
+	doc.font("B", 14)
+	doc.line(0, 7, seller.Name, "L")
+	doc.font("", 10)
+	if seller.Address != "" {
+		pdf.MultiCell(110, 5, seller.Address, "", "L", false)
+	}
+	doc.line(0, 5, fmt.Sprintf("%s: %s  %s", doc.label("เลขประจำตัวผู้เสียภาษี", "Tax ID"), seller.TaxID, doc.branchLabel(seller.Branch)), "L")
+
+	// --- ชื่อเอกสาร ---
+	pdf.Ln(4)
+	doc.font("B", 16)
+	doc.line(0, 8, doc.label("ใบเสร็จรับเงิน/ใบกำกับภาษี", "Receipt/Tax Invoice"), "C")
+	doc.font("", 10)
+	doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("เลขที่", "No."), invoice.Number), "R")
+	doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("วันที่", "Date"), invoice.IssuedAt.Format("02/01/2006")), "R")
+	doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("อ้างอิงคำสั่งซื้อ", "Order"), orderReference(order)), "R")
+
+	// --- ผู้ซื้อ ---
+	address := order.ShippingSnapshot
+	if address.IsEmpty() {
+		address = domain.NewAddressSnapshot(&order.ShippingAddress)
+	}
+	buyerName := address.CompanyName
+	if buyerName == "" {
+		buyerName = strings.TrimSpace(buyer.FirstName + " " + buyer.LastName)
+	}
+
+	pdf.Ln(2)
+	doc.font("B", 11)
+	doc.line(0, 6, doc.label("ผู้ซื้อ", "Customer"), "L")
+	doc.font("", 10)
+	doc.line(0, 5, buyerName, "L")
+	pdf.MultiCell(0, 5, formatAddress(address), "", "L", false)
+	if address.TaxID != "" {
+		doc.line(0, 5, fmt.Sprintf("%s: %s  %s", doc.label("เลขประจำตัวผู้เสียภาษี", "Tax ID"), address.TaxID, doc.branchLabel(address.BranchCode)), "L")
+	}
+
+	// --- รายการสินค้า ---
+	pdf.Ln(4)
+	widths := []float64{10, 90, 20, 30, 30}
+	doc.font("B", 10)
+	headers := []string{"#", doc.label("รายการ", "Description"), doc.label("จำนวน", "Qty"), doc.label("ราคา/หน่วย", "Unit Price"), doc.label("จำนวนเงิน", "Amount")}
+	for i, h := range headers {
+		pdf.CellFormat(widths[i], 7, h, "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	doc.font("", 10)
+	for _, line := range invoice.Lines {
+		pdf.CellFormat(widths[0], 7, fmt.Sprintf("%d", line.Position), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(widths[1], 7, line.Description, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], 7, fmt.Sprintf("%d", line.Quantity), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 7, formatBaht(line.UnitPrice), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 7, formatBaht(line.Amount), "1", 1, "R", false, 0, "")
+	}
+
+	// --- สรุปยอด ---
+	type totalRow struct {
+		label  string
+		amount float64
+		bold   bool
+	}
+	totals := []totalRow{
+		{doc.label("รวมเงิน", "Subtotal"), invoice.Subtotal, false},
+		{doc.label("ส่วนลด", "Discount"), invoice.Discount, false},
+		{doc.label("มูลค่าสินค้าก่อนภาษี", "Amount before VAT"), invoice.NetAmount, false},
+		{doc.label(fmt.Sprintf("ภาษีมูลค่าเพิ่ม %.0f%%", invoice.VATRate*100), fmt.Sprintf("VAT %.0f%%", invoice.VATRate*100)), invoice.VATAmount, false},
+	}
+	if invoice.ShippingCost > 0 {
+		// ค่าจัดส่งไม่มีภาษี จึงแสดงแยกหลังบรรทัด VAT
+		totals = append(totals, totalRow{doc.label("ค่าจัดส่ง", "Shipping"), invoice.ShippingCost, false})
+	}
+	if invoice.GiftWrapPrice > 0 {
+		totals = append(totals, totalRow{doc.label("ค่าห่อของขวัญ", "Gift wrap"), invoice.GiftWrapPrice, false})
+	}
+	totals = append(totals, totalRow{doc.label("จำนวนเงินรวมทั้งสิ้น", "Grand Total"), invoice.TotalAmount, true})
+	labelWidth := widths[0] + widths[1] + widths[2] + widths[3]
+	for _, t := range totals {
+		style := ""
+		if t.bold {
+			style = "B"
+		}
+		doc.font(style, 10)
+		pdf.CellFormat(labelWidth, 7, t.label, "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 7, formatBaht(t.amount), "1", 1, "R", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render invoice pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// branchLabel แปลงรหัสสาขาเป็นข้อความ ("00000" = สำนักงานใหญ่)
+func (d *invoiceDoc) branchLabel(code string) string {
+	if code == "" || code == "00000" {
+		return d.label("สำนักงานใหญ่", "Head Office")
+	}
+	return fmt.Sprintf("%s %s", d.label("สาขาที่", "Branch"), code)
+}
+
+func formatAddress(a domain.AddressSnapshot) string {
+	parts := []string{a.AddressLine1, a.AddressLine2, a.City, a.State, a.PostalCode, a.Country}
+	nonEmpty := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// formatBaht แสดงจำนวนเงินทศนิยม 2 ตำแหน่งพร้อมคั่นหลักพัน เช่น 1,234.50
+func formatBaht(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	intPart, frac := s[:len(s)-3], s[len(s)-3:]
+	neg := strings.HasPrefix(intPart, "-")
+	intPart = strings.TrimPrefix(intPart, "-")
+
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if neg {
+		return "-" + b.String() + frac
+	}
+	return b.String() + frac
+}
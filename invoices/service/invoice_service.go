@@ -0,0 +1,192 @@
+package service
+
+import (
+	"backend/config"
+	"backend/domain"
+	"backend/internal/datastore"
+	"backend/invoices/repository"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrInvoiceNotIssued    = errors.New("invoice has not been issued for this order yet")
+	ErrInvoiceAccessDenied = errors.New("you do not have permission to view this invoice")
+)
+
+type InvoiceService interface {
+	GetInvoicePDF(userID uint, isAdmin bool, orderID uint) (filename string, pdf []byte, err error)
+}
+
+type invoiceService struct {
+	uow    datastore.UnitOfWork
+	seller SellerInfo
+	fonts  FontConfig
+}
+
+func NewInvoiceService(uow datastore.UnitOfWork, cfg *config.Config) InvoiceService {
+	return &invoiceService{
+		uow: uow,
+		seller: SellerInfo{
+			Name:    cfg.SellerName,
+			Address: cfg.SellerAddress,
+			TaxID:   cfg.SellerTaxID,
+			Branch:  cfg.SellerBranch,
+		},
+		fonts: FontConfig{
+			RegularPath: cfg.InvoiceFontPath,
+			BoldPath:    cfg.InvoiceFontBoldPath,
+		},
+	}
+}
+
+// IssueInvoice ออกใบกำกับภาษีให้ Order พร้อมเลขที่ถัดไปของปี
+// ต้องเรียกภายใน Transaction เดียวกับการเปลี่ยนสถานะ Order (ดู orders/service.TransitionOrderStatus)
+// ถ้า Transaction Rollback เลขที่ก็จะถูกคืนด้วย จึงไม่มีเลขขาดช่วง
+func IssueInvoice(repos *datastore.Repositories, order *domain.Order) (*domain.Invoice, error) {
+	// Order ที่มีใบกำกับภาษีแล้วไม่ต้องออกใหม่
+	existing, err := repos.Invoice.FindByOrderID(order.ID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, repository.ErrInvoiceNotFound) {
+		return nil, err
+	}
+
+	issuedAt := time.Now()
+	seq, err := repos.Invoice.NextSequence(issuedAt.Year())
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate invoice number: %w", err)
+	}
+
+	// ใช้ยอดภาษีที่ Tax Engine คำนวณไว้ตอน Checkout
+	total := domain.RoundMoney(order.FinalPrice)
+	vatRate := 0.0
+	for _, line := range order.TaxLines {
+		if line.Rate > vatRate {
+			vatRate = line.Rate
+		}
+	}
+	net, vat := order.NetAmount, order.TaxAmount
+	if net == 0 && total > 0 {
+		// Order ที่สร้างก่อนมี Tax Engine ถือว่าราคารวม VAT อัตรามาตรฐานแล้ว
+		vatRate = domain.DefaultVATRate
+		vat = domain.RoundMoney(total * vatRate / (1 + vatRate))
+		net = domain.RoundMoney(total - vat)
+	}
+
+	// Snapshot รายการสินค้าและยอดต่างๆ ไว้กับใบกำกับภาษี
+	lines := make([]domain.InvoiceLine, 0, len(order.OrderItems))
+	for i, item := range order.OrderItems {
+		description := item.ProductName
+		if description == "" {
+			description = item.Product.Name
+		}
+		if description == "" {
+			description = fmt.Sprintf("Product #%d", item.ProductID)
+		}
+		lines = append(lines, domain.InvoiceLine{
+			Position:    i + 1,
+			Description: description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Price,
+			Amount:      domain.RoundMoney(item.Price * float64(item.Quantity)),
+		})
+	}
+
+	invoice := &domain.Invoice{
+		OrderID:       order.ID,
+		Number:        fmt.Sprintf("INV%d-%06d", issuedAt.Year(), seq),
+		IssuedAt:      issuedAt,
+		Subtotal:      order.TotalPrice,
+		Discount:      order.Discount,
+		ShippingCost:  order.ShippingCost,
+		GiftWrapPrice: order.GiftWrapPrice,
+		VATRate:       vatRate,
+		NetAmount:     net,
+		VATAmount:     vat,
+		TotalAmount:   total,
+		Lines:         lines,
+	}
+	if err := repos.Invoice.Create(invoice); err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+	return invoice, nil
+}
+
+// IssueInvoiceAdjustment ออกใบลดหนี้หรือใบเพิ่มหนี้อ้างอิงใบกำกับภาษีของ Order เมื่อยอดเปลี่ยนจาก oldTotal/oldVAT เป็นยอดปัจจุบันของ order
+// คืน nil ถ้า Order ยังไม่มีใบกำกับภาษี (เช่น เก็บเงินปลายทางที่ยังไม่ได้รับเงิน ใบกำกับภาษีจะออกตามยอดใหม่เอง) หรือยอดไม่เปลี่ยน
+// ต้องเรียกภายใน Transaction เดียวกับการแก้ไข Order เหมือน IssueInvoice
+func IssueInvoiceAdjustment(repos *datastore.Repositories, order *domain.Order, oldTotal, oldVAT float64, reason string) (*domain.InvoiceAdjustment, error) {
+	invoice, err := repos.Invoice.FindByOrderID(order.ID)
+	if errors.Is(err, repository.ErrInvoiceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	oldTotal, newTotal := domain.RoundMoney(oldTotal), domain.RoundMoney(order.FinalPrice)
+	if oldTotal == newTotal {
+		return nil, nil
+	}
+	kind, prefix := domain.InvoiceDebitNote, "DN"
+	if newTotal < oldTotal {
+		kind, prefix = domain.InvoiceCreditNote, "CN"
+	}
+
+	issuedAt := time.Now()
+	seq, err := repos.Invoice.NextAdjustmentSequence(kind, issuedAt.Year())
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate %s number: %w", kind, err)
+	}
+	adjustment := &domain.InvoiceAdjustment{
+		InvoiceID:      invoice.ID,
+		OrderID:        order.ID,
+		Kind:           kind,
+		Number:         fmt.Sprintf("%s%d-%06d", prefix, issuedAt.Year(), seq),
+		IssuedAt:       issuedAt,
+		OriginalAmount: oldTotal,
+		CorrectAmount:  newTotal,
+		Amount:         domain.RoundMoney(math.Abs(newTotal - oldTotal)),
+		VATAmount:      domain.RoundMoney(math.Abs(order.TaxAmount - oldVAT)),
+		Reason:         reason,
+	}
+	if err := repos.Invoice.CreateAdjustment(adjustment); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", kind, err)
+	}
+	return adjustment, nil
+}
+
+// GetInvoicePDF สร้างไฟล์ PDF ใบเสร็จรับเงิน/ใบกำกับภาษีของ Order
+// เจ้าของ Order หรือ Admin เท่านั้นที่ดาวน์โหลดได้
+func (s *invoiceService) GetInvoicePDF(userID uint, isAdmin bool, orderID uint) (string, []byte, error) {
+	order, err := s.uow.OrderRepository().FindByID(orderID)
+	if err != nil {
+		return "", nil, err
+	}
+	if !isAdmin && order.UserID != userID {
+		return "", nil, ErrInvoiceAccessDenied
+	}
+
+	invoice, err := s.uow.InvoiceRepository().FindByOrderID(orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvoiceNotFound) {
+			return "", nil, ErrInvoiceNotIssued
+		}
+		return "", nil, err
+	}
+
+	buyer, err := s.uow.UserRepository().FindByID(order.UserID)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not get buyer: %w", err)
+	}
+
+	pdf, err := renderInvoicePDF(s.seller, s.fonts, invoice, order, buyer)
+	if err != nil {
+		return "", nil, err
+	}
+	return invoice.Number + ".pdf", pdf, nil
+}
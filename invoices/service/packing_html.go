@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// packingTemplates คือ Template HTML ของเอกสารคลังสินค้า (เปิดใน Browser แล้วสั่งพิมพ์ได้ทันที)
+var packingTemplates = template.Must(template.New("packing").Funcs(template.FuncMap{
+	"baht": formatBaht,
+}).Parse(`
+{{define "style"}}
+<style>
+	body { font-family: "Sarabun", "Tahoma", sans-serif; font-size: 13px; margin: 24px; color: #000; }
+	h1 { text-align: center; font-size: 22px; margin: 12px 0; }
+	table { width: 100%; border-collapse: collapse; margin-top: 12px; }
+	th, td { border: 1px solid #000; padding: 4px 6px; vertical-align: top; }
+	th { background: #eee; }
+	.num { text-align: right; white-space: nowrap; }
+	.img { width: 64px; text-align: center; }
+	.img img { max-width: 56px; max-height: 56px; }
+	.meta { text-align: right; }
+	.gift { border: 1px dashed #000; padding: 8px; margin-top: 12px; white-space: pre-wrap; }
+	.check { width: 48px; }
+	.sign { margin-top: 32px; text-align: right; }
+	@media print { body { margin: 0; } tr { page-break-inside: avoid; } }
+</style>
+{{end}}
+
+{{define "image"}}{{if .}}<img src="{{.}}" alt="">{{end}}{{end}}
+
+{{define "packingSlip"}}<!DOCTYPE html>
+<html lang="th">
+<head>
+<meta charset="utf-8">
+<title>ใบแพ็คสินค้า / Packing Slip {{.Slip.OrderRef}}</title>
+{{template "style"}}
+</head>
+<body>
+{{with .Slip}}
+{{if .Seller.Name}}<div><strong>{{.Seller.Name}}</strong>{{if .Seller.Address}}<br>{{.Seller.Address}}{{end}}</div>{{end}}
+<h1>ใบแพ็คสินค้า / Packing Slip</h1>
+<div class="meta">
+	เลขที่คำสั่งซื้อ / Order: {{.OrderRef}}<br>
+	วันที่สั่งซื้อ / Order Date: {{.OrderDate.Format "02/01/2006"}}
+	{{if .ShippingMethod}}<br>วิธีจัดส่ง / Shipping: {{.ShippingMethod}}{{end}}
+</div>
+<h3>ผู้รับ / Ship To</h3>
+<div>{{.Recipient}}<br>{{.Address}}{{if .Phone}}<br>โทร / Tel: {{.Phone}}{{end}}</div>
+{{if .IsGift}}
+<h3>ของขวัญ / Gift</h3>
+{{if .GiftWrap}}<div>ห่อของขวัญ / Gift wrap: {{.GiftWrap}}</div>{{end}}
+{{if .GiftMessage}}<div class="gift">{{.GiftMessage}}</div>{{end}}
+{{end}}
+{{end}}
+<table>
+	<tr>
+		<th class="img">รูป / Image</th><th>SKU</th><th>รายการ / Description</th><th>จำนวน / Qty</th>
+		{{if not .Slip.HidePrices}}<th>ราคา/หน่วย / Unit Price</th><th>จำนวนเงิน / Amount</th>{{end}}
+	</tr>
+	{{range .Lines}}
+	<tr>
+		<td class="img">{{template "image" .ImageURL}}</td>
+		<td>{{.SKU}}</td>
+		<td>{{.Name}}</td>
+		<td class="num">{{.Quantity}}</td>
+		{{if not $.Slip.HidePrices}}<td class="num">{{baht .UnitPrice}}</td><td class="num">{{baht .Amount}}</td>{{end}}
+	</tr>
+	{{end}}
+	{{if not .Slip.HidePrices}}{{with .Slip}}
+	<tr><td colspan="5" class="num">รวมเงิน / Subtotal</td><td class="num">{{baht .Subtotal}}</td></tr>
+	<tr><td colspan="5" class="num">ส่วนลด / Discount</td><td class="num">{{baht .Discount}}</td></tr>
+	{{if gt .ShippingCost 0.0}}<tr><td colspan="5" class="num">ค่าจัดส่ง / Shipping</td><td class="num">{{baht .ShippingCost}}</td></tr>{{end}}
+	{{if gt .GiftWrapPrice 0.0}}<tr><td colspan="5" class="num">ค่าห่อของขวัญ / Gift wrap</td><td class="num">{{baht .GiftWrapPrice}}</td></tr>{{end}}
+	<tr><th colspan="5" class="num">ยอดรวม / Total</th><th class="num">{{baht .Total}}</th></tr>
+	{{end}}{{end}}
+</table>
+<div class="sign">ผู้แพ็ค / Packed by ____________________</div>
+</body>
+</html>
+{{end}}
+
+{{define "pickList"}}<!DOCTYPE html>
+<html lang="th">
+<head>
+<meta charset="utf-8">
+<title>ใบจัดสินค้า / Pick List</title>
+{{template "style"}}
+</head>
+<body>
+<h1>ใบจัดสินค้า / Pick List</h1>
+<div class="meta">
+	สร้างเมื่อ / Generated: {{.List.GeneratedAt.Format "02/01/2006 15:04"}}<br>
+	จำนวนคำสั่งซื้อ / Orders: {{len .List.Orders}} &nbsp; จำนวนชิ้น / Units: {{.List.TotalUnits}}
+</div>
+<table>
+	<tr>
+		<th class="img">รูป / Image</th><th>SKU</th><th>รายการ / Description</th><th>จำนวน / Qty</th><th>คำสั่งซื้อ / Orders</th><th class="check">หยิบ / Pick</th>
+	</tr>
+	{{range .Lines}}
+	<tr>
+		<td class="img">{{template "image" .ImageURL}}</td>
+		<td>{{.SKU}}</td>
+		<td>{{.Name}}</td>
+		<td class="num">{{.Quantity}}</td>
+		<td>{{range $i, $o := .Orders}}{{if $i}}<br>{{end}}{{$o}}{{end}}</td>
+		<td class="check"></td>
+	</tr>
+	{{else}}
+	<tr><td colspan="6" style="text-align:center">ไม่มีสินค้าที่รอจัด / Nothing to pick</td></tr>
+	{{end}}
+</table>
+{{if .List.Orders}}
+<h3>คำสั่งซื้อในรอบนี้ / Orders in this batch</h3>
+<table>
+	<tr><th>เลขที่ / Order</th><th>ผู้รับ / Recipient</th><th>วิธีจัดส่ง / Shipping</th><th>ชิ้น / Units</th><th>ห่อของขวัญ / Gift wrap</th></tr>
+	{{range .List.Orders}}
+	<tr><td>{{.OrderRef}}</td><td>{{.Recipient}}</td><td>{{.ShippingMethod}}</td><td class="num">{{.Quantity}}</td><td>{{.GiftWrap}}</td></tr>
+	{{end}}
+</table>
+{{end}}
+</body>
+</html>
+{{end}}
+`))
+
+// htmlPackingLine คือรายการสินค้าบนใบแพ็คสินค้าพร้อม URL รูปเต็ม
+type htmlPackingLine struct {
+	packingLine
+	ImageURL string
+}
+
+// htmlPickListLine คือรายการสินค้าบนใบจัดสินค้าพร้อม URL รูปเต็ม
+type htmlPickListLine struct {
+	pickListLine
+	ImageURL string
+}
+
+// renderPackingSlipHTML สร้างใบแพ็คสินค้าแบบ HTML (รูปสินค้าโหลดจาก Storage โดย Browser)
+func renderPackingSlipHTML(slip *packingSlip, imageBaseURL string) ([]byte, error) {
+	lines := make([]htmlPackingLine, 0, len(slip.Lines))
+	for _, line := range slip.Lines {
+		lines = append(lines, htmlPackingLine{packingLine: line, ImageURL: imageURL(imageBaseURL, line.ImagePath)})
+	}
+
+	var buf bytes.Buffer
+	data := struct {
+		Slip  *packingSlip
+		Lines []htmlPackingLine
+	}{slip, lines}
+	if err := packingTemplates.ExecuteTemplate(&buf, "packingSlip", data); err != nil {
+		return nil, fmt.Errorf("failed to render packing slip html: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderPickListHTML สร้างใบจัดสินค้าแบบ HTML
+func renderPickListHTML(list *pickList, imageBaseURL string) ([]byte, error) {
+	lines := make([]htmlPickListLine, 0, len(list.Lines))
+	for _, line := range list.Lines {
+		lines = append(lines, htmlPickListLine{pickListLine: line, ImageURL: imageURL(imageBaseURL, line.ImagePath)})
+	}
+
+	var buf bytes.Buffer
+	data := struct {
+		List  *pickList
+		Lines []htmlPickListLine
+	}{list, lines}
+	if err := packingTemplates.ExecuteTemplate(&buf, "pickList", data); err != nil {
+		return nil, fmt.Errorf("failed to render pick list html: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func imageURL(baseURL, imagePath string) string {
+	if baseURL == "" || imagePath == "" {
+		return ""
+	}
+	return baseURL + "/" + imagePath
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// maxProductImageBytes คือขนาดรูปสินค้าสูงสุดที่ยอมโหลดมาใส่ใน PDF
+const maxProductImageBytes = 5 << 20
+
+// productImages โหลดรูปสินค้าจาก Storage มาลงทะเบียนใน PDF ครั้งเดียวต่อรูป
+// รูปที่โหลดไม่ได้หรือเป็นชนิดที่ PDF ไม่รองรับ (เช่น WebP) จะถูกข้ามไป เอกสารยังพิมพ์ได้ตามปกติ
+type productImages struct {
+	baseURL string
+	client  *http.Client
+	loaded  map[string]string // Path ของรูป -> ชื่อที่ลงทะเบียนใน PDF ("" = ใช้ไม่ได้)
+}
+
+// register คืนชื่อรูปที่ลงทะเบียนใน pdf แล้ว หรือ "" ถ้าไม่มีรูป
+func (p *productImages) register(pdf *fpdf.Fpdf, imagePath string) string {
+	if p.baseURL == "" || imagePath == "" {
+		return ""
+	}
+	if p.loaded == nil {
+		p.loaded = make(map[string]string)
+	}
+	if name, ok := p.loaded[imagePath]; ok {
+		return name
+	}
+	p.loaded[imagePath] = ""
+
+	resp, err := p.client.Get(p.baseURL + "/" + imagePath)
+	if err != nil {
+		log.Printf("WARNING: could not load product image %s: %v", imagePath, err)
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("WARNING: could not load product image %s: status %d", imagePath, resp.StatusCode)
+		return ""
+	}
+	imageType := pdfImageType(imagePath, resp.Header.Get("Content-Type"))
+	if imageType == "" {
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxProductImageBytes))
+	if err != nil {
+		log.Printf("WARNING: could not read product image %s: %v", imagePath, err)
+		return ""
+	}
+
+	name := fmt.Sprintf("product-%d", len(p.loaded))
+	pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+	if !pdf.Ok() {
+		// รูปเสียไม่ควรทำให้ทั้งเอกสารพิมพ์ไม่ได้
+		log.Printf("WARNING: could not embed product image %s: %v", imagePath, pdf.Error())
+		pdf.ClearError()
+		return ""
+	}
+	p.loaded[imagePath] = name
+	return name
+}
+
+// pdfImageType คืนชนิดรูปที่ fpdf รองรับจาก Content-Type หรือนามสกุลไฟล์ ("" = ไม่รองรับ)
+func pdfImageType(imagePath, contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "image/jpeg":
+			return "JPG"
+		case "image/png":
+			return "PNG"
+		case "image/gif":
+			return "GIF"
+		}
+	}
+	switch strings.ToLower(path.Ext(imagePath)) {
+	case ".jpg", ".jpeg":
+		return "JPG"
+	case ".png":
+		return "PNG"
+	case ".gif":
+		return "GIF"
+	}
+	return ""
+}
+
+// image วาดรูปให้พอดีกับกรอบ size x size มม. โดยคงสัดส่วนเดิมและจัดกึ่งกลาง
+func (d *invoiceDoc) image(name string, x, y, size float64) {
+	if name == "" {
+		return
+	}
+	info := d.pdf.GetImageInfo(name)
+	if info == nil || info.Width() <= 0 || info.Height() <= 0 {
+		return
+	}
+	w, h := size, size*info.Height()/info.Width()
+	if h > size {
+		w, h = size*info.Width()/info.Height(), size
+	}
+	d.pdf.ImageOptions(name, x+(size-w)/2, y+(size-h)/2, w, h, false, fpdf.ImageOptions{}, 0, "")
+}
+
+// tableRow วาดตาราง 1 แถวที่ข้อความยาวตัดขึ้นบรรทัดใหม่ได้ ความสูงของแถวเท่ากับช่องที่สูงที่สุด (ไม่น้อยกว่า minHeight)
+// ถ้าแถวล้นหน้าจะขึ้นหน้าใหม่และเรียก header ก่อนวาด คืนตำแหน่งมุมซ้ายบนของแถวสำหรับวาดรูปต่อ
+func (d *invoiceDoc) tableRow(widths []float64, cells, aligns []string, minHeight float64, header func()) (x, y float64) {
+	const lineHeight = 5.0
+	pdf := d.pdf
+
+	height := minHeight
+	for i, text := range cells {
+		if lines := len(pdf.SplitText(text, widths[i]-2)); float64(lines)*lineHeight+2 > height {
+			height = float64(lines)*lineHeight + 2
+		}
+	}
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+height > pageHeight-bottom {
+		pdf.AddPage()
+		if header != nil {
+			header()
+		}
+	}
+
+	x, y = pdf.GetX(), pdf.GetY()
+	cx := x
+	for i, text := range cells {
+		pdf.Rect(cx, y, widths[i], height, "D")
+		pdf.SetXY(cx+1, y+1)
+		pdf.MultiCell(widths[i]-2, lineHeight, text, "", aligns[i], false)
+		cx += widths[i]
+	}
+	pdf.SetXY(x, y+height)
+	return x, y
+}
+
+// tableHeader วาดหัวตาราง
+func (d *invoiceDoc) tableHeader(widths []float64, headers []string) {
+	d.font("B", 10)
+	for i, h := range headers {
+		d.pdf.CellFormat(widths[i], 7, h, "1", 0, "C", false, 0, "")
+	}
+	d.pdf.Ln(-1)
+	d.font("", 10)
+}
+
+// renderPackingSlipPDF สร้าง PDF ใบแพ็คสินค้า 1 Order (ถ้า HidePrices จะไม่พิมพ์ราคาและยอดเงิน)
+func renderPackingSlipPDF(slip *packingSlip, fonts FontConfig, images *productImages) ([]byte, error) {
+	doc, err := newInvoiceDoc(fonts)
+	if err != nil {
+		return nil, err
+	}
+	pdf := doc.pdf
+	pdf.AddPage()
+
+	// --- หัวเอกสาร: ผู้ส่ง ---
+	doc.font("B", 12)
+	doc.line(0, 6, slip.Seller.Name, "L")
+	doc.font("", 9)
+	if slip.Seller.Address != "" {
+		pdf.MultiCell(110, 4.5, slip.Seller.Address, "", "L", false)
+	}
+
+	pdf.Ln(3)
+	doc.font("B", 16)
+	doc.line(0, 8, doc.label("ใบแพ็คสินค้า", "Packing Slip"), "C")
+	doc.font("", 10)
+	doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("เลขที่คำสั่งซื้อ", "Order"), slip.OrderRef), "R")
+	doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("วันที่สั่งซื้อ", "Order Date"), slip.OrderDate.Format("02/01/2006")), "R")
+	if slip.ShippingMethod != "" {
+		doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("วิธีจัดส่ง", "Shipping"), slip.ShippingMethod), "R")
+	}
+
+	// --- ผู้รับ ---
+	pdf.Ln(2)
+	doc.font("B", 11)
+	doc.line(0, 6, doc.label("ผู้รับ", "Ship To"), "L")
+	doc.font("", 10)
+	doc.line(0, 5, slip.Recipient, "L")
+	pdf.MultiCell(0, 5, slip.Address, "", "L", false)
+	if slip.Phone != "" {
+		doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("โทร", "Tel"), slip.Phone), "L")
+	}
+
+	// --- ของขวัญ ---
+	if slip.IsGift {
+		pdf.Ln(3)
+		doc.font("B", 11)
+		doc.line(0, 6, doc.label("ของขวัญ", "Gift"), "L")
+		doc.font("", 10)
+		if slip.GiftWrap != "" {
+			doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("ห่อของขวัญ", "Gift wrap"), slip.GiftWrap), "L")
+		}
+		if slip.GiftMessage != "" {
+			pdf.MultiCell(0, 5, slip.GiftMessage, "1", "L", false)
+		}
+	}
+
+	// --- รายการสินค้า ---
+	pdf.Ln(4)
+	widths := []float64{16, 30, 116, 18}
+	headers := []string{doc.label("รูป", "Image"), "SKU", doc.label("รายการ", "Description"), doc.label("จำนวน", "Qty")}
+	aligns := []string{"C", "L", "L", "R"}
+	if !slip.HidePrices {
+		widths = []float64{16, 26, 64, 18, 28, 28}
+		headers = append(headers, doc.label("ราคา/หน่วย", "Unit Price"), doc.label("จำนวนเงิน", "Amount"))
+		aligns = append(aligns, "R", "R")
+	}
+	header := func() { doc.tableHeader(widths, headers) }
+	header()
+	for _, line := range slip.Lines {
+		cells := []string{"", line.SKU, line.Name, fmt.Sprintf("%d", line.Quantity)}
+		if !slip.HidePrices {
+			cells = append(cells, formatBaht(line.UnitPrice), formatBaht(line.Amount))
+		}
+		x, y := doc.tableRow(widths, cells, aligns, 16, header)
+		doc.image(images.register(pdf, line.ImagePath), x+1, y+1, 14)
+	}
+
+	// --- สรุปยอด ---
+	if !slip.HidePrices {
+		type totalRow struct {
+			label  string
+			amount float64
+		}
+		totals := []totalRow{
+			{doc.label("รวมเงิน", "Subtotal"), slip.Subtotal},
+			{doc.label("ส่วนลด", "Discount"), slip.Discount},
+		}
+		if slip.ShippingCost > 0 {
+			totals = append(totals, totalRow{doc.label("ค่าจัดส่ง", "Shipping"), slip.ShippingCost})
+		}
+		if slip.GiftWrapPrice > 0 {
+			totals = append(totals, totalRow{doc.label("ค่าห่อของขวัญ", "Gift wrap"), slip.GiftWrapPrice})
+		}
+		totals = append(totals, totalRow{doc.label("ยอดรวม", "Total"), slip.Total})
+
+		labelWidth := widths[0] + widths[1] + widths[2] + widths[3] + widths[4]
+		for i, t := range totals {
+			style := ""
+			if i == len(totals)-1 {
+				style = "B"
+			}
+			doc.font(style, 10)
+			pdf.CellFormat(labelWidth, 7, t.label, "1", 0, "R", false, 0, "")
+			pdf.CellFormat(widths[5], 7, formatBaht(t.amount), "1", 1, "R", false, 0, "")
+		}
+	}
+
+	pdf.Ln(10)
+	doc.font("", 10)
+	doc.line(0, 6, fmt.Sprintf("%s ____________________", doc.label("ผู้แพ็ค", "Packed by")), "R")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render packing slip pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderPickListPDF สร้าง PDF ใบจัดสินค้ารวม จัดกลุ่มตามสินค้าและ SKU พร้อมรายการ Order ที่อยู่ในรอบนี้
+func renderPickListPDF(list *pickList, fonts FontConfig, images *productImages) ([]byte, error) {
+	doc, err := newInvoiceDoc(fonts)
+	if err != nil {
+		return nil, err
+	}
+	pdf := doc.pdf
+	pdf.AddPage()
+
+	doc.font("B", 16)
+	doc.line(0, 8, doc.label("ใบจัดสินค้า", "Pick List"), "C")
+	doc.font("", 10)
+	doc.line(0, 5, fmt.Sprintf("%s: %s", doc.label("สร้างเมื่อ", "Generated"), list.GeneratedAt.Format("02/01/2006 15:04")), "R")
+	doc.line(0, 5, fmt.Sprintf("%s: %d  %s: %d", doc.label("จำนวนคำสั่งซื้อ", "Orders"), len(list.Orders), doc.label("จำนวนชิ้น", "Units"), list.TotalUnits), "R")
+
+	// --- สินค้าที่ต้องหยิบ ---
+	pdf.Ln(4)
+	widths := []float64{16, 30, 58, 16, 40, 20}
+	headers := []string{doc.label("รูป", "Image"), "SKU", doc.label("รายการ", "Description"), doc.label("จำนวน", "Qty"), doc.label("คำสั่งซื้อ", "Orders"), doc.label("หยิบ", "Pick")}
+	aligns := []string{"C", "L", "L", "R", "L", "C"}
+	header := func() { doc.tableHeader(widths, headers) }
+	header()
+	if len(list.Lines) == 0 {
+		pdf.CellFormat(0, 8, doc.label("ไม่มีสินค้าที่รอจัด", "Nothing to pick"), "1", 1, "C", false, 0, "")
+	}
+	for _, line := range list.Lines {
+		cells := []string{"", line.SKU, line.Name, fmt.Sprintf("%d", line.Quantity), strings.Join(line.Orders, "\n"), ""}
+		x, y := doc.tableRow(widths, cells, aligns, 16, header)
+		doc.image(images.register(pdf, line.ImagePath), x+1, y+1, 14)
+	}
+
+	// --- คำสั่งซื้อในรอบนี้ ---
+	if len(list.Orders) > 0 {
+		pdf.Ln(6)
+		doc.font("B", 11)
+		doc.line(0, 6, doc.label("คำสั่งซื้อในรอบนี้", "Orders in this batch"), "L")
+		orderWidths := []float64{30, 60, 55, 15, 20}
+		orderHeaders := []string{doc.label("เลขที่", "Order"), doc.label("ผู้รับ", "Recipient"), doc.label("วิธีจัดส่ง", "Shipping"), doc.label("ชิ้น", "Units"), doc.label("ห่อของขวัญ", "Gift wrap")}
+		orderAligns := []string{"L", "L", "L", "R", "L"}
+		orderHeader := func() { doc.tableHeader(orderWidths, orderHeaders) }
+		orderHeader()
+		for _, order := range list.Orders {
+			doc.tableRow(orderWidths, []string{order.OrderRef, order.Recipient, order.ShippingMethod, fmt.Sprintf("%d", order.Quantity), order.GiftWrap}, orderAligns, 7, orderHeader)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render pick list pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
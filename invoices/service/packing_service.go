@@ -0,0 +1,350 @@
+package service
+
+import (
+	"backend/config"
+	"backend/domain"
+	"backend/internal/datastore"
+	orderDto "backend/orders/dto"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnsupportedDocumentFormat = errors.New("unsupported document format")
+	ErrOrderNotPackable          = errors.New("order is not ready to be packed")
+)
+
+// pickListBatchSize คือจำนวน Order ที่ดึงจาก Database ต่อครั้งตอนสร้างใบจัดสินค้า
+const pickListBatchSize = 200
+
+// DocumentFormat คือรูปแบบไฟล์ของเอกสารสำหรับคลังสินค้า
+type DocumentFormat string
+
+const (
+	DocumentPDF  DocumentFormat = "pdf"
+	DocumentHTML DocumentFormat = "html"
+)
+
+// Document คือไฟล์เอกสารที่สร้างเสร็จแล้ว พร้อมส่งให้ Client
+type Document struct {
+	Filename    string
+	ContentType string
+	Body        []byte
+}
+
+// PackingService สร้างเอกสารสำหรับคลังสินค้า: ใบแพ็คสินค้าของแต่ละ Order และใบจัดสินค้ารวมหลาย Order
+type PackingService interface {
+	GetPackingSlip(orderID uint, format DocumentFormat) (*Document, error)
+	GetPickList(orderIDs []uint, format DocumentFormat) (*Document, error)
+}
+
+type packingService struct {
+	uow          datastore.UnitOfWork
+	seller       SellerInfo
+	fonts        FontConfig
+	imageBaseURL string
+	httpClient   *http.Client // ใช้โหลดรูปสินค้ามาใส่ใน PDF
+}
+
+func NewPackingService(uow datastore.UnitOfWork, cfg *config.Config) PackingService {
+	return &packingService{
+		uow: uow,
+		seller: SellerInfo{
+			Name:    cfg.SellerName,
+			Address: cfg.SellerAddress,
+			TaxID:   cfg.SellerTaxID,
+			Branch:  cfg.SellerBranch,
+		},
+		fonts: FontConfig{
+			RegularPath: cfg.InvoiceFontPath,
+			BoldPath:    cfg.InvoiceFontBoldPath,
+		},
+		imageBaseURL: strings.TrimSuffix(cfg.ImageBaseURL, "/"),
+		httpClient:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// packingLine คือสินค้า 1 รายการบนใบแพ็คสินค้า
+type packingLine struct {
+	SKU       string
+	Name      string
+	ImagePath string
+	Quantity  uint
+	UnitPrice float64
+	Amount    float64
+}
+
+// packingSlip คือข้อมูลที่พิมพ์บนใบแพ็คสินค้า (ใช้ร่วมกันระหว่าง PDF และ HTML)
+type packingSlip struct {
+	Seller         SellerInfo
+	OrderRef       string
+	OrderDate      time.Time
+	Recipient      string
+	Phone          string
+	Address        string
+	ShippingMethod string
+	Lines          []packingLine
+
+	IsGift      bool
+	GiftWrap    string
+	GiftMessage string
+	HidePrices  bool // Order ของขวัญที่ลูกค้าขอไม่ให้แสดงราคา
+
+	Subtotal      float64
+	Discount      float64
+	ShippingCost  float64
+	GiftWrapPrice float64
+	Total         float64
+}
+
+// pickListLine คือสินค้า 1 รายการ (จัดกลุ่มตามสินค้าและ SKU) พร้อมจำนวนรวมที่ต้องหยิบ
+type pickListLine struct {
+	ProductID uint
+	SKU       string
+	Name      string
+	ImagePath string
+	Quantity  uint
+	Orders    []string // เลขที่ Order และจำนวน เช่น "261017-00420 x2"
+}
+
+// pickListOrder คือ Order 1 รายการที่อยู่ในรอบจัดสินค้านี้
+type pickListOrder struct {
+	OrderRef       string
+	Recipient      string
+	ShippingMethod string
+	Quantity       uint
+	GiftWrap       string
+}
+
+// pickList คือข้อมูลที่พิมพ์บนใบจัดสินค้า (ใช้ร่วมกันระหว่าง PDF และ HTML)
+type pickList struct {
+	GeneratedAt time.Time
+	Lines       []pickListLine
+	Orders      []pickListOrder
+	TotalUnits  uint
+}
+
+// GetPackingSlip สร้างใบแพ็คสินค้าของ Order ที่ชำระแล้ว (หรือเก็บเงินปลายทาง) และยังไม่ถูกยกเลิก
+func (s *packingService) GetPackingSlip(orderID uint, format DocumentFormat) (*Document, error) {
+	if format != DocumentPDF && format != DocumentHTML {
+		return nil, ErrUnsupportedDocumentFormat
+	}
+
+	order, err := s.uow.OrderRepository().FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == domain.StatusPending || order.Status == domain.StatusCancelled {
+		return nil, fmt.Errorf("%w: order status is '%s'", ErrOrderNotPackable, order.Status)
+	}
+	buyer, err := s.uow.UserRepository().FindByID(order.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get buyer: %w", err)
+	}
+
+	slip := s.buildPackingSlip(order, buyer)
+	filename := "packing-slip-" + slip.OrderRef
+	if format == DocumentHTML {
+		body, err := renderPackingSlipHTML(slip, s.imageBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		return &Document{Filename: filename + ".html", ContentType: "text/html; charset=utf-8", Body: body}, nil
+	}
+	body, err := renderPackingSlipPDF(slip, s.fonts, s.newImageLoader())
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Filename: filename + ".pdf", ContentType: "application/pdf", Body: body}, nil
+}
+
+// GetPickList สร้างใบจัดสินค้ารวมของ Order ที่สถานะ processing (orderIDs ว่าง = ทุก Order ที่รอจัดส่ง)
+// จำนวนที่ต้องหยิบคือจำนวนที่สั่งหักด้วยจำนวนที่ส่งไปแล้วในพัสดุก่อนหน้า
+func (s *packingService) GetPickList(orderIDs []uint, format DocumentFormat) (*Document, error) {
+	if format != DocumentPDF && format != DocumentHTML {
+		return nil, ErrUnsupportedDocumentFormat
+	}
+
+	list := &pickList{GeneratedAt: time.Now()}
+	lineIndex := make(map[string]int)
+	params := orderDto.AdminOrderQueryParams{Status: domain.StatusProcessing, OrderIDs: orderIDs}
+	err := s.uow.OrderRepository().FindInBatches(params, pickListBatchSize, func(orders []domain.Order) error {
+		for i := range orders {
+			order := &orders[i]
+			shipped, err := s.shippedQuantities(order.ID)
+			if err != nil {
+				return err
+			}
+
+			ref := orderReference(order)
+			summary := pickListOrder{
+				OrderRef:       ref,
+				Recipient:      recipientName(order, &order.User),
+				ShippingMethod: shippingMethodLabel(order),
+				GiftWrap:       order.GiftWrapName,
+			}
+			for _, item := range order.OrderItems {
+				if shipped[item.ID] >= item.Quantity {
+					continue
+				}
+				qty := item.Quantity - shipped[item.ID]
+				name, sku := itemSnapshot(item)
+
+				key := fmt.Sprintf("%d|%s", item.ProductID, sku)
+				idx, ok := lineIndex[key]
+				if !ok {
+					idx = len(list.Lines)
+					lineIndex[key] = idx
+					list.Lines = append(list.Lines, pickListLine{
+						ProductID: item.ProductID,
+						SKU:       sku,
+						Name:      name,
+						ImagePath: item.ProductImagePath,
+					})
+				}
+				line := &list.Lines[idx]
+				line.Quantity += qty
+				line.Orders = append(line.Orders, fmt.Sprintf("%s x%d", ref, qty))
+				if line.ImagePath == "" {
+					line.ImagePath = item.ProductImagePath
+				}
+				summary.Quantity += qty
+			}
+			if summary.Quantity == 0 {
+				continue // ส่งครบแล้วแต่สถานะยังไม่ถูกอัปเดต
+			}
+			list.TotalUnits += summary.Quantity
+			list.Orders = append(list.Orders, summary)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pick list: %w", err)
+	}
+	// เรียงตาม SKU ให้คนหยิบสินค้าเดินตามชั้นวางได้ง่าย
+	sort.SliceStable(list.Lines, func(i, j int) bool {
+		if list.Lines[i].SKU != list.Lines[j].SKU {
+			return list.Lines[i].SKU < list.Lines[j].SKU
+		}
+		return list.Lines[i].Name < list.Lines[j].Name
+	})
+
+	filename := "pick-list-" + list.GeneratedAt.Format("20060102-1504")
+	if format == DocumentHTML {
+		body, err := renderPickListHTML(list, s.imageBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		return &Document{Filename: filename + ".html", ContentType: "text/html; charset=utf-8", Body: body}, nil
+	}
+	body, err := renderPickListPDF(list, s.fonts, s.newImageLoader())
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Filename: filename + ".pdf", ContentType: "application/pdf", Body: body}, nil
+}
+
+func (s *packingService) buildPackingSlip(order *domain.Order, buyer *domain.User) *packingSlip {
+	address := order.ShippingSnapshot
+	if address.IsEmpty() {
+		address = domain.NewAddressSnapshot(&order.ShippingAddress)
+	}
+
+	slip := &packingSlip{
+		Seller:         s.seller,
+		OrderRef:       orderReference(order),
+		OrderDate:      order.CreatedAt,
+		Recipient:      recipientName(order, buyer),
+		Address:        formatAddress(address),
+		ShippingMethod: shippingMethodLabel(order),
+		IsGift:         order.IsGift(),
+		GiftWrap:       order.GiftWrapName,
+		GiftMessage:    order.GiftMessage,
+		HidePrices:     order.GiftHidePrices,
+		Subtotal:       order.TotalPrice,
+		Discount:       order.Discount,
+		ShippingCost:   order.ShippingCost,
+		GiftWrapPrice:  order.GiftWrapPrice,
+		Total:          order.FinalPrice,
+	}
+	if buyer.PhoneNumber != nil {
+		slip.Phone = *buyer.PhoneNumber
+	}
+	for _, item := range order.OrderItems {
+		name, sku := itemSnapshot(item)
+		slip.Lines = append(slip.Lines, packingLine{
+			SKU:       sku,
+			Name:      name,
+			ImagePath: item.ProductImagePath,
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Amount:    item.Price * float64(item.Quantity),
+		})
+	}
+	return slip
+}
+
+// shippedQuantities คืนจำนวนที่ส่งไปแล้วของแต่ละ OrderItem (Key = OrderItemID)
+// พัสดุที่ตีกลับไม่นับ เพราะสินค้ากลับมาอยู่ในคลังและต้องจัดส่งใหม่
+func (s *packingService) shippedQuantities(orderID uint) (map[uint]uint, error) {
+	shipments, err := s.uow.ShipmentRepository().FindByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	shipped := make(map[uint]uint)
+	for _, shipment := range shipments {
+		if shipment.Status == domain.ShipmentReturned {
+			continue
+		}
+		for _, item := range shipment.Items {
+			shipped[item.OrderItemID] += item.Quantity
+		}
+	}
+	return shipped, nil
+}
+
+func (s *packingService) newImageLoader() *productImages {
+	return &productImages{baseURL: s.imageBaseURL, client: s.httpClient}
+}
+
+// orderReference คืนเลขที่ Order สำหรับพิมพ์บนเอกสาร (Order เก่าที่ไม่มีเลขที่ใช้ ID แทน)
+func orderReference(order *domain.Order) string {
+	if order.OrderNumber != nil {
+		return *order.OrderNumber
+	}
+	return fmt.Sprintf("%d", order.ID)
+}
+
+// recipientName คือชื่อผู้รับบนเอกสาร (ชื่อบริษัทในที่อยู่จัดส่งก่อน ถ้าไม่มีใช้ชื่อผู้สั่งซื้อ)
+func recipientName(order *domain.Order, buyer *domain.User) string {
+	name := strings.TrimSpace(buyer.FirstName + " " + buyer.LastName)
+	if order.ShippingSnapshot.CompanyName != "" {
+		if name == "" {
+			return order.ShippingSnapshot.CompanyName
+		}
+		return order.ShippingSnapshot.CompanyName + " (" + name + ")"
+	}
+	if name == "" {
+		return buyer.Email
+	}
+	return name
+}
+
+func shippingMethodLabel(order *domain.Order) string {
+	if order.ShippingCarrier == "" {
+		return order.ShippingMethodName
+	}
+	return order.ShippingMethodName + " (" + order.ShippingCarrier + ")"
+}
+
+// itemSnapshot คืนชื่อและ SKU ของสินค้า ณ เวลาที่สั่งซื้อ (Order เก่าที่ไม่มี Snapshot ใช้ข้อมูลสินค้าปัจจุบัน)
+func itemSnapshot(item domain.OrderItem) (name, sku string) {
+	if item.ProductName == "" {
+		return item.Product.Name, item.Product.SKU
+	}
+	return item.ProductName, item.ProductSKU
+}
@@ -9,9 +9,15 @@ import (
 	"backend/dashboard"
 	"backend/domain"
 	"backend/internal/datastore"
+	"backend/invoices"
 	"backend/middleware"
 	"backend/orders"
+	"backend/outbox"
+	"backend/payments"
 	"backend/products"
+	"backend/returns"
+	"backend/shipping"
+	"backend/taxes"
 	"backend/users"
 	"github.com/gofiber/fiber/v2"
 
@@ -31,8 +37,18 @@ func main() {
 		&domain.Category{}, &domain.Product{}, &domain.ProductImage{},
 		&domain.User{}, &domain.Address{},
 		&domain.Cart{}, &domain.CartItem{},
-		&domain.Order{}, &domain.OrderItem{},
+		&domain.Order{}, &domain.OrderItem{}, &domain.OrderStatusHistory{}, &domain.OrderEdit{},
+		&domain.OrderNumberSequence{},
 		&domain.Coupon{},
+		&domain.IdempotencyKey{},
+		&domain.PaymentEvent{}, &domain.Payment{},
+		&domain.Invoice{}, &domain.InvoiceLine{}, &domain.InvoiceSequence{},
+		&domain.InvoiceAdjustment{}, &domain.InvoiceAdjustmentSequence{},
+		&domain.TaxClass{}, &domain.TaxRate{}, &domain.OrderTaxLine{},
+		&domain.ShippingZone{}, &domain.ShippingMethod{}, &domain.GiftWrapOption{},
+		&domain.Shipment{}, &domain.ShipmentItem{}, &domain.TrackingEvent{},
+		&domain.ReturnRequest{}, &domain.ReturnItem{}, &domain.ReturnPhoto{}, &domain.OrderRefund{},
+		&domain.OutboxEvent{},
 	)
 
 	// 3. สร้าง Dependencies ส่วนกลาง
@@ -53,9 +69,15 @@ func main() {
 	categories.RegisterModule(api, uow, cfg)
 	users.RegisterModule(api, uow, cfg)
 	carts.RegisterModule(api, uow, cfg)
+	invoices.RegisterModule(api, uow, cfg) // ต้องอยู่ก่อน orders (ดูเหตุผลใน invoices.RegisterModule)
 	orders.RegisterModule(api, uow, cfg)
 	dashboard.RegisterModule(api, uow, cfg)
 	coupons.RegisterModule(api, uow, cfg)
+	payments.RegisterModule(api, uow, cfg)
+	taxes.RegisterModule(api, uow, cfg)
+	shipping.RegisterModule(api, uow, cfg)
+	returns.RegisterModule(api, uow, cfg)
+	outbox.RegisterModule(api, uow, cfg)
 
 	log.Println("Server started on :8080")
 	app.Listen(":8080")
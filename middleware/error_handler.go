@@ -2,7 +2,16 @@
 package middleware
 
 import (
+	"backend/internal/datastore"
+	invoiceService "backend/invoices/service"
+	orderRepo "backend/orders/repository"
+	orderService "backend/orders/service"
+	outboxService "backend/outbox/service"
+	paymentService "backend/payments/service"
 	"backend/products/service"
+	returnService "backend/returns/service"
+	shippingService "backend/shipping/service"
+	taxService "backend/taxes/service"
 	"errors"
 	"github.com/gofiber/fiber/v2"
 	"log"
@@ -23,6 +32,116 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	if errors.Is(err, datastore.ErrIdempotencyKeyInUse) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if errors.Is(err, orderRepo.ErrOrderNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, orderService.ErrOrderAccessDenied) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, orderService.ErrCartIsEmpty) ||
+		errors.Is(err, orderService.ErrInvalidShippingAddress) ||
+		errors.Is(err, orderService.ErrInvalidOrderNumber) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, orderService.ErrProductOutOfStock) ||
+		errors.Is(err, orderService.ErrProductUnavailable) ||
+		errors.Is(err, orderService.ErrCouponNotValid) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, orderRepo.ErrShipmentNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, orderService.ErrOrderItemNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, orderService.ErrInvalidShipmentItems) || errors.Is(err, orderService.ErrInvalidOrderEdit) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, orderService.ErrTrackingNumberInUse) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, orderService.ErrInvalidOrderStatus) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if errors.Is(err, invoiceService.ErrInvoiceAccessDenied) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, invoiceService.ErrInvoiceNotIssued) ||
+		errors.Is(err, invoiceService.ErrOrderNotPackable) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, invoiceService.ErrUnsupportedDocumentFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if errors.Is(err, paymentService.ErrPaymentNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, paymentService.ErrPaymentAccessDenied) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, paymentService.ErrPaymentDeclined) {
+		return c.Status(fiber.StatusPaymentRequired).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, paymentService.ErrPromptPayNotConfigured) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, paymentService.ErrInvalidRefundAmount) ||
+		errors.Is(err, paymentService.ErrInvalidRemittanceFile) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, paymentService.ErrOrderNotPayable) ||
+		errors.Is(err, paymentService.ErrPaymentAmountChanged) ||
+		errors.Is(err, paymentService.ErrPaymentNotCapturable) ||
+		errors.Is(err, paymentService.ErrPaymentNotRefundable) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if errors.Is(err, returnService.ErrReturnNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, returnService.ErrReturnAccessDenied) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, returnService.ErrInvalidReturnItems) ||
+		errors.Is(err, returnService.ErrInvalidReturnPhotos) ||
+		errors.Is(err, returnService.ErrInvalidRefundAmount) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, returnService.ErrOrderNotReturnable) || errors.Is(err, returnService.ErrInvalidReturnStatus) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if errors.Is(err, taxService.ErrTaxClassNotFound) || errors.Is(err, taxService.ErrTaxRateNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, taxService.ErrTaxClassExists) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if errors.Is(err, shippingService.ErrShippingZoneNotFound) ||
+		errors.Is(err, shippingService.ErrShippingMethodNotFound) ||
+		errors.Is(err, shippingService.ErrGiftWrapNotFound) ||
+		errors.Is(err, shippingService.ErrAddressNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, shippingService.ErrShippingMethodUnavailable) ||
+		errors.Is(err, shippingService.ErrGiftWrapUnavailable) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if errors.Is(err, outboxService.ErrOutboxEventNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, outboxService.ErrOutboxEventPublished) || errors.Is(err, outboxService.ErrOutboxEventInFlight) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	// ... สามารถเพิ่มเงื่อนไข if errors.Is(...) สำหรับ custom error อื่นๆ ได้ที่นี่ ...
 
 	// ถ้าเป็น Error ที่ไม่รู้จัก ให้ถือเป็น Internal Server Error
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IdempotencyKeyHeader คือชื่อ Header ที่ Client ใช้ส่งคีย์สำหรับกันการประมวลผลซ้ำ
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Idempotency คือ Middleware ที่ทำให้ Request ที่ถูกส่งซ้ำ (Retry) ด้วย Idempotency-Key เดิม
+// ได้รับ Response แรกกลับไป แทนที่จะสร้างข้อมูลซ้ำ
+//
+// คีย์จะถูกบันทึกลง Database โดย UnitOfWork.ExecuteWithContext ใน Transaction เดียวกับงานหลัก
+// ดังนั้น Handler ที่ใช้ Middleware นี้ต้องส่ง c.UserContext() ต่อไปให้ Service
+//
+// คีย์ที่ยังไม่มี Response นานกว่า inFlightTimeout (เช่น Process ล่มก่อนเก็บ Response) ถือว่าค้าง
+// Request ใหม่จะเคลียร์คีย์นั้นแล้วประมวลผลใหม่ แทนที่จะได้ 409 ไปตลอด
+func Idempotency(repo datastore.IdempotencyRepository, inFlightTimeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// 1. ถ้าไม่ได้ส่ง Header มา ก็ทำงานตามปกติ
+		key := c.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > 255 {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key must not exceed 255 characters")
+		}
+
+		// คีย์ผูกกับ User ที่ Login (ถ้ามี) เพื่อไม่ให้คีย์ของคนอื่นชนกัน
+		var userID uint
+		if claims, ok := c.Locals("user").(*JwtClaims); ok {
+			userID = claims.UserID
+		}
+		requestHash := hashRequest(c)
+
+		// 2. ถ้าเคยเห็นคีย์นี้แล้ว ให้ตอบกลับด้วย Response เดิม
+		existing, err := repo.FindByKey(userID, key)
+		switch {
+		case err == nil:
+			if existing.RequestHash != requestHash {
+				return fiber.NewError(fiber.StatusConflict, "Idempotency-Key has already been used with a different request")
+			}
+			if existing.StatusCode == 0 {
+				released, err := repo.ReleaseStale(userID, key, time.Now().Add(-inFlightTimeout))
+				if err != nil {
+					return err
+				}
+				if !released {
+					return fiber.NewError(fiber.StatusConflict, "A request with this Idempotency-Key is still being processed")
+				}
+				log.Printf("WARNING: released stale idempotency key %s of user %d", key, userID)
+				break
+			}
+			c.Set("Idempotent-Replayed", "true")
+			if existing.ContentType != "" {
+				c.Set(fiber.HeaderContentType, existing.ContentType)
+			}
+			return c.Status(existing.StatusCode).Send(existing.ResponseBody)
+		case !errors.Is(err, datastore.ErrIdempotencyNotFound):
+			return err
+		}
+
+		// 3. คีย์ใหม่: แนบไปกับ Context ให้ Service บันทึกใน Transaction เดียวกับงานหลัก
+		record := &domain.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash,
+		}
+		c.SetUserContext(datastore.WithIdempotencyKey(c.UserContext(), record))
+
+		if err := c.Next(); err != nil {
+			// Error จะถูกส่งไปที่ ErrorHandler และ Transaction ถูก Rollback ไปแล้ว จึงไม่ต้องเก็บ Response
+			return err
+		}
+
+		// 4. เก็บ Response แรกไว้สำหรับ Request ที่จะส่งซ้ำเข้ามา
+		body := append([]byte(nil), c.Response().Body()...)
+		contentType := string(c.Response().Header.ContentType())
+		if err := repo.SaveResponse(userID, key, c.Response().StatusCode(), contentType, body); err != nil {
+			log.Printf("WARNING: failed to store idempotent response for key %s: %v", key, err)
+		}
+		return nil
+	}
+}
+
+// StartIdempotencyCleanup ลบคีย์ที่เก่ากว่า retention ทุกๆ interval ใน Background จนกว่า ctx จะถูกยกเลิก
+func StartIdempotencyCleanup(ctx context.Context, repo datastore.IdempotencyRepository, retention, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		log.Println("Idempotency key cleanup is disabled.")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := repo.DeleteBefore(time.Now().Add(-retention))
+				if err != nil {
+					log.Printf("ERROR: idempotency key cleanup failed: %v", err)
+					continue
+				}
+				if deleted > 0 {
+					log.Printf("Idempotency: deleted %d key(s) older than %s", deleted, retention)
+				}
+			}
+		}
+	}()
+	log.Printf("✅ Idempotency key cleanup started (retention: %s, every %s).", retention, interval)
+}
+
+// hashRequest สร้าง Fingerprint ของ Request เพื่อตรวจว่าคีย์เดิมถูกใช้กับ Payload อื่นหรือไม่
+func hashRequest(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
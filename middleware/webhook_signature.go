@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"backend/outbox/publisher"
+	"crypto/hmac"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Header ใช้ชื่อเดียวกับ Webhook ขาออก (outbox/publisher) เพื่อให้ระบบเราตรวจ Webhook ของตัวเองได้
+const (
+	WebhookTimestampHeader = publisher.WebhookTimestampHeader
+	WebhookSignatureHeader = publisher.WebhookSignatureHeader
+)
+
+// VerifyWebhookSignature คือ Middleware ที่ตรวจว่า Webhook มาจาก Payment Gateway จริง
+// Gateway ต้องส่ง Header:
+//   - X-Webhook-Timestamp: Unix timestamp (วินาที) ตอนที่ส่ง
+//   - X-Webhook-Signature: hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// Request ที่ Timestamp เก่าหรือใหม่เกิน tolerance จะถูกปฏิเสธ เพื่อกันการนำ Request เดิมมายิงซ้ำ
+func VerifyWebhookSignature(secret string, tolerance time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if secret == "" {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Webhook secret not configured",
+			})
+		}
+
+		// 1. ตรวจ Timestamp
+		timestamp := c.Get(WebhookTimestampHeader)
+		signature := c.Get(WebhookSignatureHeader)
+		if timestamp == "" || signature == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing webhook signature headers"})
+		}
+		unixSeconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid webhook timestamp"})
+		}
+		age := time.Since(time.Unix(unixSeconds, 0))
+		if age > tolerance || age < -tolerance {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Webhook timestamp is outside the allowed window"})
+		}
+
+		// 2. ตรวจ Signature (ใช้ hmac.Equal เพื่อกัน Timing Attack)
+		expected := SignWebhookPayload(secret, timestamp, c.Body())
+		if !hmac.Equal([]byte(expected), []byte(signature)) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid webhook signature"})
+		}
+
+		return c.Next()
+	}
+}
+
+// SignWebhookPayload สร้าง Signature ตามรูปแบบที่ VerifyWebhookSignature คาดหวัง
+// ใช้ publisher.SignPayload ตัวเดียวกับ Webhook ขาออก (publisher import middleware ไม่ได้ เพราะจะเกิด Import Cycle)
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	return publisher.SignPayload(secret, timestamp, body)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "test-webhook-secret"
+	body := `{"intent_id":"fake_pi_test_000001","status":"succeeded"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+
+	cases := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      string
+		want      int
+	}{
+		{name: "valid signature", secret: secret, timestamp: now, signature: SignWebhookPayload(secret, now, []byte(body)), body: body, want: fiber.StatusOK},
+		{name: "tampered body", secret: secret, timestamp: now, signature: SignWebhookPayload(secret, now, []byte(body)), body: strings.Replace(body, "succeeded", "failed", 1), want: fiber.StatusUnauthorized},
+		{name: "wrong secret", secret: secret, timestamp: now, signature: SignWebhookPayload("other-secret", now, []byte(body)), body: body, want: fiber.StatusUnauthorized},
+		{name: "timestamp outside tolerance", secret: secret, timestamp: stale, signature: SignWebhookPayload(secret, stale, []byte(body)), body: body, want: fiber.StatusUnauthorized},
+		{name: "invalid timestamp", secret: secret, timestamp: "yesterday", signature: SignWebhookPayload(secret, "yesterday", []byte(body)), body: body, want: fiber.StatusUnauthorized},
+		{name: "missing signature headers", secret: secret, body: body, want: fiber.StatusUnauthorized},
+		{name: "secret not configured", secret: "", timestamp: now, signature: SignWebhookPayload("", now, []byte(body)), body: body, want: fiber.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/webhook", VerifyWebhookSignature(tc.secret, 5*time.Minute), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.timestamp != "" {
+				req.Header.Set(WebhookTimestampHeader, tc.timestamp)
+			}
+			if tc.signature != "" {
+				req.Header.Set(WebhookSignatureHeader, tc.signature)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tc.want)
+			}
+		})
+	}
+}
@@ -7,31 +7,89 @@ import (
 
 // CreateOrderRequest คือ DTO สำหรับรับข้อมูลตอนสร้าง Order
 type CreateOrderRequest struct {
-	ShippingAddressID uint `json:"shipping_address_id" validate:"required"`
+	ShippingAddressID uint                `json:"shipping_address_id" validate:"required"`
+	ShippingMethodID  uint                `json:"shipping_method_id" validate:"required"`
+	PaymentMethod     string              `json:"payment_method" validate:"omitempty,oneof=online cod"` // ว่างหรือ online = ชำระออนไลน์หลังสั่งซื้อ, cod = เก็บเงินปลายทาง
+	Gift              *GiftOptionsRequest `json:"gift,omitempty"`
+}
+
+// GiftOptionsRequest คือตัวเลือกการส่ง Order เป็นของขวัญ (ไม่ระบุ GiftWrapID = ไม่ห่อของขวัญ)
+type GiftOptionsRequest struct {
+	GiftWrapID *uint  `json:"gift_wrap_id"`
+	Message    string `json:"message" validate:"max=500"`
+	HidePrices bool   `json:"hide_prices"` // เอกสารแพ็คสินค้าไม่แสดงราคา
 }
 
 type OrderItemResponse struct {
 	ProductID uint    `json:"product_id"`
 	Name      string  `json:"name"`
 	Sku       string  `json:"sku"`
+	ImageURL  string  `json:"image_url,omitempty"`
 	Price     float64 `json:"price"` // ราคา ณ ตอนที่สั่งซื้อ
 	Quantity  uint    `json:"quantity"`
+	TaxRate   float64 `json:"tax_rate"`
+	TaxAmount float64 `json:"tax_amount"`
+}
+
+// OrderTaxLineResponse คือยอดภาษีของ Order แยกตามอัตรา
+type OrderTaxLineResponse struct {
+	TaxClassCode  string  `json:"tax_class_code"`
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
 }
 
 // OrderResponse คือ DTO สำหรับแสดงข้อมูล Order ฉบับเต็ม
 type OrderResponse struct {
 	ID                uint                `json:"id"`
+	OrderNumber       *string             `json:"order_number,omitempty"`
 	UserID            uint                `json:"user_id"`
 	TotalPrice        float64             `json:"total_price"`
+	Discount          float64             `json:"discount"`
+	FinalPrice        float64             `json:"final_price"`
+	AppliedCouponCode *string             `json:"applied_coupon_code,omitempty"`
 	Status            domain.OrderStatus  `json:"status"`
 	ShippingAddressID uint                `json:"shipping_address_id"`
 	CreatedAt         time.Time           `json:"created_at"`
 	ShippingAddress   *AddressResponse    `json:"shipping_address,omitempty"`
 	PaymentMethod     *string             `json:"payment_method,omitempty"`
+	PaidAt            *time.Time          `json:"paid_at,omitempty"`
 	Items             []OrderItemResponse `json:"items"`
+
+	PricesIncludeTax bool                   `json:"prices_include_tax"`
+	NetAmount        float64                `json:"net_amount"` // ยอดหลังหักส่วนลด ไม่รวมภาษี
+	TaxAmount        float64                `json:"tax_amount"`
+	TaxLines         []OrderTaxLineResponse `json:"tax_lines"`
+
+	ShippingMethodID *uint   `json:"shipping_method_id,omitempty"`
+	ShippingMethod   string  `json:"shipping_method,omitempty"`
+	ShippingCarrier  string  `json:"shipping_carrier,omitempty"`
+	ShippingCost     float64 `json:"shipping_cost"` // รวมอยู่ใน final_price แล้ว
+
+	IsGift         bool    `json:"is_gift"`
+	GiftWrapID     *uint   `json:"gift_wrap_id,omitempty"`
+	GiftWrapName   string  `json:"gift_wrap_name,omitempty"`
+	GiftWrapPrice  float64 `json:"gift_wrap_price"` // รวมอยู่ใน final_price แล้ว
+	GiftMessage    string  `json:"gift_message,omitempty"`
+	GiftHidePrices bool    `json:"gift_hide_prices"`
+
+	RefundedAmount float64 `json:"refunded_amount"` // ยอดที่คืนเงินให้ลูกค้าแล้ว (จากการคืนสินค้า)
+	BalanceDue     float64 `json:"balance_due"`     // ยอดที่ต้องชำระเพิ่มหลัง Order ที่ชำระแล้วถูกแก้ไขจนยอดเพิ่มขึ้น
+}
+
+// OrderStatusHistoryResponse คือ DTO สำหรับแสดง Timeline การเปลี่ยนสถานะของ Order
+type OrderStatusHistoryResponse struct {
+	FromStatus domain.OrderStatus `json:"from_status,omitempty"`
+	ToStatus   domain.OrderStatus `json:"to_status"`
+	ChangedBy  *uint              `json:"changed_by,omitempty"`
+	Reason     string             `json:"reason"`
+	CreatedAt  time.Time          `json:"created_at"`
 }
 
 type PaymentWebhookRequest struct {
+	Provider      string `json:"provider" validate:"required,max=50"`
+	EventID       string `json:"event_id" validate:"required,max=255"` // ID ของ Event ฝั่ง Gateway ใช้กันการประมวลผลซ้ำ
 	OrderID       uint   `json:"order_id" validate:"required"`
 	Status        string `json:"status" validate:"required,oneof=success failed"`
 	PaymentMethod string `json:"payment_method" validate:"required,oneof=credit_card bank_transfer"`
@@ -42,6 +100,11 @@ type ShipOrderRequest struct {
 	TrackingNumber string `json:"tracking_number" validate:"required"`
 }
 
+// CancelOrderRequest คือ DTO สำหรับรับเหตุผลตอนยกเลิก Order (ไม่บังคับ)
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
 type AddressResponse struct {
 	ID           uint   `json:"id"`
 	AddressLine1 string `json:"address_line_1"`
@@ -51,4 +114,7 @@ type AddressResponse struct {
 	State        string `json:"state"`
 	AddressLine2 string `json:"address_line_2,omitempty"` // ใช้ omitempty
 	IsDefault    bool   `json:"is_default"`
+	CompanyName  string `json:"company_name,omitempty"`
+	TaxID        string `json:"tax_id,omitempty"`
+	BranchCode   string `json:"branch_code,omitempty"`
 }
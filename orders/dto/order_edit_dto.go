@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"backend/domain"
+	"time"
+)
+
+// AddOrderItemRequest คือ DTO สำหรับเพิ่มสินค้าเข้า Order ที่ยังไม่จัดส่ง
+// ถ้ามีสินค้านี้ใน Order อยู่แล้วจะเพิ่มจำนวนให้รายการเดิม
+type AddOrderItemRequest struct {
+	ProductID uint   `json:"product_id" validate:"required"`
+	Quantity  uint   `json:"quantity" validate:"required,gt=0"`
+	Reason    string `json:"reason" validate:"max=255"`
+}
+
+// UpdateOrderItemRequest คือ DTO สำหรับเปลี่ยนจำนวน หรือเปลี่ยนเป็นสินค้าตัวอื่น (ระบุ ProductID)
+type UpdateOrderItemRequest struct {
+	ProductID uint   `json:"product_id"` // ว่างหรือเท่ากับสินค้าเดิม = เปลี่ยนแค่จำนวน
+	Quantity  uint   `json:"quantity" validate:"required,gt=0"`
+	Reason    string `json:"reason" validate:"max=255"`
+}
+
+// RemoveOrderItemRequest คือ DTO สำหรับลบรายการสินค้าออกจาก Order (Body ว่างได้)
+type RemoveOrderItemRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// OrderEditResponse คือ DTO สำหรับแสดงประวัติการแก้ไขรายการสินค้าของ Order
+type OrderEditResponse struct {
+	ID                uint                   `json:"id"`
+	Action            domain.OrderEditAction `json:"action"`
+	OrderItemID       uint                   `json:"order_item_id"`
+	ProductID         uint                   `json:"product_id"`
+	PreviousProductID *uint                  `json:"previous_product_id,omitempty"`
+	ProductName       string                 `json:"product_name"`
+	OldQuantity       uint                   `json:"old_quantity"`
+	NewQuantity       uint                   `json:"new_quantity"`
+	OldFinalPrice     float64                `json:"old_final_price"`
+	NewFinalPrice     float64                `json:"new_final_price"`
+	RefundAmount      float64                `json:"refund_amount,omitempty"`             // ยอดที่คืนลูกค้าจากการแก้ไข Order ที่ชำระแล้ว
+	BalanceDue        float64                `json:"balance_due,omitempty"`               // ยอดที่ลูกค้าต้องชำระเพิ่มจากการแก้ไขนี้
+	InvoiceAdjustment string                 `json:"invoice_adjustment_number,omitempty"` // เลขที่ใบลดหนี้/ใบเพิ่มหนี้
+	EditedBy          uint                   `json:"edited_by"`
+	Reason            string                 `json:"reason,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+}
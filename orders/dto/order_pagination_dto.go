@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"backend/domain"
+	"time"
+)
+
+// AdminOrderQueryParams คือ Struct สำหรับรับค่า Filter และการแบ่งหน้าจาก URL Query ของหน้า Admin
+type AdminOrderQueryParams struct {
+	Page   int
+	Limit  int
+	SortBy string
+	Order  string
+
+	Status        domain.OrderStatus // ว่าง = ทุกสถานะ
+	DateFrom      *time.Time         // รวมวันนี้ด้วย
+	DateTo        *time.Time         // ไม่รวมเวลานี้ (Handler บวกไปแล้ว 1 วัน)
+	OrderNumber   string             // รูปแบบมาตรฐาน (Handler Normalize แล้ว)
+	CustomerEmail string             // ค้นหาแบบบางส่วนของอีเมล
+	CouponCode    string
+	MinTotal      *float64 // เทียบกับ FinalPrice
+	MaxTotal      *float64
+	OrderIDs      []uint // ว่าง = ทุก Order
+}
+
+// AdminOrderListItem คือข้อมูลสรุปของ Order แต่ละรายการในหน้า Admin
+type AdminOrderListItem struct {
+	ID                uint               `json:"id"`
+	OrderNumber       *string            `json:"order_number,omitempty"`
+	UserID            uint               `json:"user_id"`
+	CustomerEmail     string             `json:"customer_email"`
+	CustomerName      string             `json:"customer_name"`
+	Status            domain.OrderStatus `json:"status"`
+	ItemCount         int                `json:"item_count"`
+	TotalPrice        float64            `json:"total_price"`
+	Discount          float64            `json:"discount"`
+	FinalPrice        float64            `json:"final_price"`
+	AppliedCouponCode *string            `json:"applied_coupon_code,omitempty"`
+	PaymentMethod     *string            `json:"payment_method,omitempty"`
+	TrackingNumber    *string            `json:"tracking_number,omitempty"`
+	CreatedAt         time.Time          `json:"created_at"`
+}
+
+// PaginatedOrdersDTO คือ DTO สำหรับ Response ที่มีข้อมูลการแบ่งหน้าของ Order
+type PaginatedOrdersDTO struct {
+	Data        []AdminOrderListItem `json:"data"`
+	TotalItems  int64                `json:"total_items"`
+	TotalPages  int                  `json:"total_pages"`
+	CurrentPage int                  `json:"current_page"`
+	Limit       int                  `json:"limit"`
+}
@@ -0,0 +1,62 @@
+package dto
+
+// CheckoutProblemCode คือรหัสปัญหาที่พบตอนตรวจตะกร้าก่อนสั่งซื้อ
+type CheckoutProblemCode string
+
+const (
+	ProblemProductUnavailable  CheckoutProblemCode = "product_unavailable"   // สินค้าถูกลบหรือเลิกขายแล้ว
+	ProblemOutOfStock          CheckoutProblemCode = "out_of_stock"          // สต็อกไม่พอกับจำนวนในตะกร้า
+	ProblemPriceChanged        CheckoutProblemCode = "price_changed"         // ราคาเปลี่ยนหลังจากหยิบใส่ตะกร้า
+	ProblemCouponInvalid       CheckoutProblemCode = "coupon_invalid"        // คูปองถูกลบหรือถูกปิดใช้งาน
+	ProblemCouponExpired       CheckoutProblemCode = "coupon_expired"        // คูปองหมดอายุ
+	ProblemCouponUsageLimit    CheckoutProblemCode = "coupon_usage_limit"    // คูปองถูกใช้ครบจำนวนแล้ว
+	ProblemShippingUnavailable CheckoutProblemCode = "shipping_unavailable"  // วิธีจัดส่งที่เลือกใช้กับที่อยู่นี้ไม่ได้
+	ProblemGiftWrapUnavailable CheckoutProblemCode = "gift_wrap_unavailable" // บริการห่อของขวัญที่เลือกถูกลบหรือปิดใช้งาน
+)
+
+// CheckoutProblem คือปัญหา 1 รายการที่พบตอนตรวจตะกร้า
+// Blocking = true หมายถึงถ้ากดสั่งซื้อตอนนี้จะไม่สำเร็จ ส่วน false เป็นแค่การแจ้งเตือน (เช่น ราคาเปลี่ยน)
+type CheckoutProblem struct {
+	Code      CheckoutProblemCode `json:"code"`
+	Message   string              `json:"message"`
+	ProductID *uint               `json:"product_id,omitempty"`
+	Blocking  bool                `json:"blocking"`
+}
+
+// QuoteItemResponse คือสินค้า 1 รายการในใบเสนอราคา
+type QuoteItemResponse struct {
+	ProductID      uint    `json:"product_id"`
+	Name           string  `json:"name"`
+	Sku            string  `json:"sku"`
+	ImageURL       string  `json:"image_url,omitempty"`
+	Quantity       uint    `json:"quantity"`
+	AvailableStock int     `json:"available_stock"`
+	Price          float64 `json:"price"`                   // ราคาปัจจุบันที่จะถูกเรียกเก็บ
+	PriceInCart    float64 `json:"price_in_cart,omitempty"` // ราคาตอนหยิบใส่ตะกร้า
+	LineTotal      float64 `json:"line_total"`
+	TaxRate        float64 `json:"tax_rate"`
+	TaxAmount      float64 `json:"tax_amount"`
+	Available      bool    `json:"available"` // false = ไม่ถูกนับรวมในยอด และต้องแก้ก่อนสั่งซื้อ
+}
+
+// OrderQuoteResponse คือผลการคำนวณยอดของตะกร้าแบบเดียวกับตอนสั่งซื้อจริง แต่ไม่บันทึกอะไรลง Database
+type OrderQuoteResponse struct {
+	Items             []QuoteItemResponse    `json:"items"`
+	TotalPrice        float64                `json:"total_price"`
+	Discount          float64                `json:"discount"`
+	AppliedCouponCode *string                `json:"applied_coupon_code,omitempty"`
+	PricesIncludeTax  bool                   `json:"prices_include_tax"`
+	NetAmount         float64                `json:"net_amount"`
+	TaxAmount         float64                `json:"tax_amount"`
+	TaxLines          []OrderTaxLineResponse `json:"tax_lines"`
+	ShippingMethodID  *uint                  `json:"shipping_method_id,omitempty"`
+	ShippingMethod    string                 `json:"shipping_method,omitempty"`
+	ShippingCarrier   string                 `json:"shipping_carrier,omitempty"`
+	ShippingCost      float64                `json:"shipping_cost"`
+	GiftWrapID        *uint                  `json:"gift_wrap_id,omitempty"`
+	GiftWrapName      string                 `json:"gift_wrap_name,omitempty"`
+	GiftWrapPrice     float64                `json:"gift_wrap_price"`
+	FinalPrice        float64                `json:"final_price"`
+	CanPlaceOrder     bool                   `json:"can_place_order"`
+	Problems          []CheckoutProblem      `json:"problems"`
+}
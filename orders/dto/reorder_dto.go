@@ -0,0 +1,28 @@
+package dto
+
+import cartDto "backend/carts/dto"
+
+// ReorderItemStatus คือผลของการคัดลอกสินค้า 1 รายการจาก Order เดิมลงตะกร้า
+type ReorderItemStatus string
+
+const (
+	ReorderAdded    ReorderItemStatus = "added"    // เพิ่มครบตามจำนวนเดิม
+	ReorderAdjusted ReorderItemStatus = "adjusted" // สต็อกไม่พอ เพิ่มได้บางส่วน
+	ReorderSkipped  ReorderItemStatus = "skipped"  // สินค้าถูกลบหรือหมดสต็อก ไม่ได้เพิ่ม
+)
+
+type ReorderItemResult struct {
+	ProductID         uint              `json:"product_id"`
+	Name              string            `json:"name"`
+	RequestedQuantity uint              `json:"requested_quantity"` // จำนวนใน Order เดิม
+	AddedQuantity     uint              `json:"added_quantity"`
+	Status            ReorderItemStatus `json:"status"`
+	Reason            string            `json:"reason,omitempty"`
+}
+
+// ReorderResponse คือรายงานการสั่งซื้อซ้ำ พร้อมตะกร้าหลังเพิ่มสินค้าแล้ว
+type ReorderResponse struct {
+	OrderID uint                  `json:"order_id"`
+	Items   []ReorderItemResult   `json:"items"`
+	Cart    *cartDto.CartResponse `json:"cart"`
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"backend/domain"
+	"time"
+)
+
+type ShipmentItemRequest struct {
+	OrderItemID uint `json:"order_item_id" validate:"required"`
+	Quantity    uint `json:"quantity" validate:"required,gt=0"`
+}
+
+// CreateShipmentRequest คือ DTO สำหรับสร้างพัสดุ 1 กล่อง ถ้าไม่ระบุ Items จะส่งสินค้าที่เหลือทั้งหมด
+type CreateShipmentRequest struct {
+	Carrier        string                `json:"carrier" validate:"required,max=100"`
+	TrackingNumber string                `json:"tracking_number" validate:"required,max=100"`
+	Origin         string                `json:"origin" validate:"max=100"`
+	Items          []ShipmentItemRequest `json:"items" validate:"dive"`
+}
+
+// TrackingWebhookRequest คือข้อมูลอัปเดตสถานะพัสดุที่ขนส่งส่งเข้ามา
+type TrackingWebhookRequest struct {
+	Carrier        string                `json:"carrier" validate:"required,max=100"`
+	TrackingNumber string                `json:"tracking_number" validate:"required,max=100"`
+	EventID        string                `json:"event_id" validate:"required,max=255"` // ID ของ Event ฝั่งขนส่ง ใช้กันการบันทึกซ้ำ
+	Status         domain.ShipmentStatus `json:"status" validate:"required,oneof=in_transit out_for_delivery delivered failed returned"`
+	Description    string                `json:"description" validate:"max=255"`
+	Location       string                `json:"location" validate:"max=255"`
+	OccurredAt     time.Time             `json:"occurred_at" validate:"required"`
+}
+
+type ShipmentItemResponse struct {
+	OrderItemID uint   `json:"order_item_id"`
+	ProductID   uint   `json:"product_id"`
+	Name        string `json:"name"`
+	Quantity    uint   `json:"quantity"`
+}
+
+type TrackingEventResponse struct {
+	Status      domain.ShipmentStatus `json:"status"`
+	Description string                `json:"description,omitempty"`
+	Location    string                `json:"location,omitempty"`
+	OccurredAt  time.Time             `json:"occurred_at"`
+}
+
+type ShipmentResponse struct {
+	ID             uint                    `json:"id"`
+	OrderID        uint                    `json:"order_id"`
+	Carrier        string                  `json:"carrier"`
+	TrackingNumber string                  `json:"tracking_number"`
+	Origin         string                  `json:"origin,omitempty"`
+	Status         domain.ShipmentStatus   `json:"status"`
+	DeliveredAt    *time.Time              `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time               `json:"created_at"`
+	Items          []ShipmentItemResponse  `json:"items"`
+	Events         []TrackingEventResponse `json:"events"`
+
+	CODAmount         float64          `json:"cod_amount,omitempty"` // ยอดที่ขนส่งต้องเก็บปลายทาง
+	CODStatus         domain.CODStatus `json:"cod_status,omitempty"`
+	CODRemittedAmount float64          `json:"cod_remitted_amount,omitempty"`
+	CODRemittedAt     *time.Time       `json:"cod_remitted_at,omitempty"`
+}
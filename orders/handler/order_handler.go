@@ -2,10 +2,16 @@ package handler
 
 import (
 	"backend/domain"
+	"backend/internal/datastore"
 	"backend/middleware"
 	"backend/orders/dto"
 	"backend/orders/service"
+	"bufio"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -30,7 +36,7 @@ func (h *OrderHandler) HandleCreateOrder(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
 	}
 
-	orderResponse, err := h.orderSvc.CreateOrderFromCart(c.Context(), claims.UserID, req)
+	orderResponse, err := h.orderSvc.CreateOrderFromCart(c.UserContext(), claims.UserID, req)
 	if err != nil {
 		// สามารถเช็ค error ประเภทต่างๆ แล้วส่ง status code ที่เหมาะสมได้
 		return err
@@ -39,6 +45,25 @@ func (h *OrderHandler) HandleCreateOrder(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(orderResponse)
 }
 
+// HandleQuoteOrder คืนยอดที่จะถูกเรียกเก็บถ้าสั่งซื้อตอนนี้ พร้อมรายการปัญหาที่ต้องแก้ก่อน (ไม่สร้าง Order)
+func (h *OrderHandler) HandleQuoteOrder(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	var req dto.CreateOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	quote, err := h.orderSvc.QuoteOrder(claims.UserID, req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(quote)
+}
+
 func (h *OrderHandler) HandleGetMyOrders(c *fiber.Ctx) error {
 	// ดึงข้อมูล user ที่ login อยู่จาก token
 	claims := c.Locals("user").(*middleware.JwtClaims)
@@ -69,13 +94,155 @@ func (h *OrderHandler) HandleGetOrderByID(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(order)
 }
 
+// HandleGetOrderByNumber ค้นหา Order ด้วยเลขที่ Order (Admin ค้นหา Order ของทุกคนได้)
+func (h *OrderHandler) HandleGetOrderByNumber(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+	isAdmin := claims.Role == string(domain.RoleAdmin)
+
+	order, err := h.orderSvc.GetOrderByNumber(claims.UserID, isAdmin, c.Params("number"))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// HandleAdminListOrders สำหรับให้ Admin ดู Order ทั้งหมดพร้อม Filter และการแบ่งหน้า
+func (h *OrderHandler) HandleAdminListOrders(c *fiber.Ctx) error {
+	// 1. สร้าง QueryParams พร้อมตั้งค่า Default
+	params := dto.AdminOrderQueryParams{
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 20),
+		SortBy: c.Query("sort_by", "created_at"),
+		Order:  c.Query("order", "desc"),
+	}
+
+	// 2. Validate ค่าที่รับเข้ามา
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 { // กำหนด Limit สูงสุด
+		params.Limit = 20
+	}
+	allowedSorts := map[string]bool{"id": true, "created_at": true, "status": true, "total_price": true, "final_price": true}
+	if !allowedSorts[params.SortBy] {
+		params.SortBy = "created_at"
+	}
+	if params.Order != "asc" && params.Order != "desc" {
+		params.Order = "desc"
+	}
+	if err := parseAdminOrderFilters(c, &params); err != nil {
+		return err
+	}
+
+	// 3. เรียกใช้ Service
+	paginatedResult, err := h.orderSvc.FindAllOrders(params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(paginatedResult)
+}
+
+// HandleAdminExportOrders สำหรับให้ Admin ดาวน์โหลด Order และรายการสินค้าเป็น CSV หรือ XLSX
+// ใช้ Filter เดียวกับ HandleAdminListOrders และ Stream ข้อมูลออกไปทีละ Batch
+func (h *OrderHandler) HandleAdminExportOrders(c *fiber.Ctx) error {
+	format := service.ExportFormat(c.Query("format", string(service.ExportCSV)))
+
+	var contentType string
+	switch format {
+	case service.ExportCSV:
+		contentType = "text/csv; charset=utf-8"
+	case service.ExportXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid format, expected csv or xlsx")
+	}
+
+	var params dto.AdminOrderQueryParams
+	if err := parseAdminOrderFilters(c, &params); err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("orders-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// Header ถูกส่งไปแล้วเมื่อเริ่ม Stream ถ้าเกิด Error ระหว่างทางทำได้แค่บันทึก Log
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.orderSvc.ExportOrders(params, format, w); err != nil {
+			log.Printf("ERROR: order export failed: %v", err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("ERROR: order export flush failed: %v", err)
+		}
+	})
+	return nil
+}
+
+// parseAdminOrderFilters อ่าน Filter จาก Query String
+// (status, date_from, date_to รูปแบบ YYYY-MM-DD, email, coupon_code, min_total, max_total)
+func parseAdminOrderFilters(c *fiber.Ctx, params *dto.AdminOrderQueryParams) error {
+	if status := c.Query("status"); status != "" {
+		params.Status = domain.OrderStatus(status)
+		switch params.Status {
+		case domain.StatusPending, domain.StatusProcessing, domain.StatusShipped, domain.StatusCompleted, domain.StatusCancelled:
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid status filter")
+		}
+	}
+
+	if from := c.Query("date_from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid date_from, expected YYYY-MM-DD")
+		}
+		params.DateFrom = &t
+	}
+	if to := c.Query("date_to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid date_to, expected YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1) // ให้รวม Order ทั้งวันของ date_to
+		params.DateTo = &t
+	}
+
+	if number := c.Query("order_number"); number != "" {
+		normalized, ok := domain.NormalizeOrderNumber(number)
+		if !ok {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid order_number filter")
+		}
+		params.OrderNumber = normalized
+	}
+	params.CustomerEmail = c.Query("email")
+	params.CouponCode = c.Query("coupon_code")
+
+	if min := c.Query("min_total"); min != "" {
+		v, err := strconv.ParseFloat(min, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid min_total")
+		}
+		params.MinTotal = &v
+	}
+	if max := c.Query("max_total"); max != "" {
+		v, err := strconv.ParseFloat(max, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid max_total")
+		}
+		params.MaxTotal = &v
+	}
+	return nil
+}
+
 func (h *OrderHandler) HandleConfirmPayment(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
 	orderID, err := c.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
 	}
 
-	if err := h.orderSvc.ConfirmPayment(uint(orderID)); err != nil {
+	if err := h.orderSvc.ConfirmPayment(claims.UserID, uint(orderID)); err != nil {
 		return err // ส่งต่อให้ Error Middleware จัดการ
 	}
 
@@ -84,31 +251,33 @@ func (h *OrderHandler) HandleConfirmPayment(c *fiber.Ctx) error {
 	})
 }
 
+// HandlePaymentWebhook รับ Webhook จาก Payment Gateway
+// Signature และ Timestamp ถูกตรวจแล้วโดย middleware.VerifyWebhookSignature
 func (h *OrderHandler) HandlePaymentWebhook(c *fiber.Ctx) error {
 	var req dto.PaymentWebhookRequest
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid webhook payload")
 	}
-
-	// ในระบบจริง จะต้องมีการตรวจสอบ Signature ของ Webhook ก่อนเสมอ
-	// เพื่อให้แน่ใจว่า Request นี้มาจาก Payment Gateway จริงๆ
-
-	var newStatus domain.OrderStatus
-	if req.Status == "success" {
-		newStatus = domain.StatusProcessing // หรือ StatusProcessing ตาม Flow ของคุณ
-	} else {
-		newStatus = domain.StatusCancelled
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
 	}
 
-	if err := h.orderSvc.UpdateOrderStatus(req.OrderID, newStatus, req.PaymentMethod); err != nil {
-		// ถ้าเกิด error ให้ตอบกลับ 500 เพื่อให้ Payment Gateway รู้ว่ามีปัญหา
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update order status")
+	result, err := h.orderSvc.ProcessPaymentEvent(c.UserContext(), req, c.Body())
+	if err != nil {
+		if errors.Is(err, datastore.ErrIdempotencyKeyInUse) {
+			return err
+		}
+		// ถ้าเกิด error ให้ตอบกลับ 500 เพื่อให้ Payment Gateway รู้ว่ามีปัญหาและส่งมาใหม่
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to process payment event")
 	}
 
-	return c.SendStatus(fiber.StatusOK) // ตอบกลับ 200 OK เพื่อบอกว่ารับทราบแล้ว
+	// ตอบกลับ 200 OK เพื่อบอกว่ารับทราบแล้ว (รวมถึง Event ที่ซ้ำหรือถูกข้าม)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"result": result})
 }
 
 func (h *OrderHandler) HandleShipOrder(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
 	orderID, err := c.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
@@ -119,7 +288,7 @@ func (h *OrderHandler) HandleShipOrder(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if err := h.orderSvc.ShipOrder(uint(orderID), req.TrackingNumber); err != nil {
+	if err := h.orderSvc.ShipOrder(claims.UserID, uint(orderID), req.TrackingNumber); err != nil {
 		return err // ส่งต่อให้ Error Middleware จัดการ
 	}
 
@@ -127,3 +296,247 @@ func (h *OrderHandler) HandleShipOrder(c *fiber.Ctx) error {
 		"message": fmt.Sprintf("Order %d has been shipped with tracking number %s", orderID, req.TrackingNumber),
 	})
 }
+
+// HandleGetOrderHistory สำหรับให้ Admin/Support ดู Timeline การเปลี่ยนสถานะของ Order
+func (h *OrderHandler) HandleGetOrderHistory(c *fiber.Ctx) error {
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	history, err := h.orderSvc.GetOrderHistory(uint(orderID))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(history)
+}
+
+// HandleCancelOrder สำหรับให้ User ยกเลิก Order ของตัวเอง
+func (h *OrderHandler) HandleCancelOrder(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	req, err := parseCancelOrderRequest(c)
+	if err != nil {
+		return err
+	}
+
+	order, err := h.orderSvc.CancelOrder(c.UserContext(), claims.UserID, uint(orderID), req.Reason)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// HandleAdminCancelOrder สำหรับให้ Admin ยกเลิก Order (รวมถึง Order ที่จัดส่งแล้ว)
+func (h *OrderHandler) HandleAdminCancelOrder(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	req, err := parseCancelOrderRequest(c)
+	if err != nil {
+		return err
+	}
+
+	order, err := h.orderSvc.AdminCancelOrder(claims.UserID, uint(orderID), req.Reason)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// parseCancelOrderRequest อ่าน Body ของการยกเลิก Order ซึ่งอาจจะว่างก็ได้
+func parseCancelOrderRequest(c *fiber.Ctx) (dto.CancelOrderRequest, error) {
+	var req dto.CancelOrderRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return req, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return req, fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	return req, nil
+}
+
+// HandleCreateShipment สร้างพัสดุ 1 กล่องของ Order (แบ่งส่งได้หลายกล่อง)
+func (h *OrderHandler) HandleCreateShipment(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var req dto.CreateShipmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	shipment, err := h.orderSvc.CreateShipment(claims.UserID, uint(orderID), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(shipment)
+}
+
+// HandleGetShipments คืนพัสดุทั้งหมดของ Order พร้อมประวัติสถานะจากขนส่ง
+func (h *OrderHandler) HandleGetShipments(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+	isAdmin := claims.Role == string(domain.RoleAdmin)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	shipments, err := h.orderSvc.GetShipments(claims.UserID, isAdmin, uint(orderID))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(shipments)
+}
+
+// HandleTrackingWebhook รับการอัปเดตสถานะพัสดุจากขนส่ง
+func (h *OrderHandler) HandleTrackingWebhook(c *fiber.Ctx) error {
+	var req dto.TrackingWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid webhook payload")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	result, err := h.orderSvc.ProcessTrackingEvent(req)
+	if err != nil {
+		return err
+	}
+	// ตอบกลับ 200 OK รวมถึง Event ที่ซ้ำ เพื่อไม่ให้ขนส่งส่งมาใหม่
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"result": result})
+}
+
+// HandleAddOrderItem ให้ Admin เพิ่มสินค้าเข้า Order ที่ยังไม่จัดส่ง
+func (h *OrderHandler) HandleAddOrderItem(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var req dto.AddOrderItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	order, err := h.orderSvc.AddOrderItem(c.UserContext(), claims.UserID, uint(orderID), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// HandleUpdateOrderItem ให้ Admin เปลี่ยนจำนวนหรือเปลี่ยนสินค้าของรายการใน Order
+func (h *OrderHandler) HandleUpdateOrderItem(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+	itemID, err := c.ParamsInt("itemId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order item ID")
+	}
+
+	var req dto.UpdateOrderItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	order, err := h.orderSvc.UpdateOrderItem(c.UserContext(), claims.UserID, uint(orderID), uint(itemID), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// HandleRemoveOrderItem ให้ Admin ลบรายการสินค้าออกจาก Order (Body ว่างได้)
+func (h *OrderHandler) HandleRemoveOrderItem(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+	itemID, err := c.ParamsInt("itemId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order item ID")
+	}
+
+	var req dto.RemoveOrderItemRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	order, err := h.orderSvc.RemoveOrderItem(c.UserContext(), claims.UserID, uint(orderID), uint(itemID), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// HandleGetOrderEdits ให้ Admin ดูประวัติการแก้ไขรายการสินค้าของ Order
+func (h *OrderHandler) HandleGetOrderEdits(c *fiber.Ctx) error {
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	edits, err := h.orderSvc.GetOrderEdits(uint(orderID))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(edits)
+}
+
+// HandleReorder คัดลอกสินค้าจาก Order เดิมลงตะกร้า แล้วคืนรายงานพร้อมตะกร้าล่าสุด
+func (h *OrderHandler) HandleReorder(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	result, err := h.orderSvc.Reorder(claims.UserID, uint(orderID))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
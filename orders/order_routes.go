@@ -4,32 +4,86 @@ import (
 	"backend/middleware"
 	"backend/orders/handler"
 
+	cartService "backend/carts/service"
 	"backend/config"
 	"backend/internal/datastore"
 	"backend/orders/service"
+	"backend/payments/gateway"
+	paymentService "backend/payments/service"
+	taxService "backend/taxes/service"
 	"github.com/gofiber/fiber/v2"
 
+	"context"
 	"log"
+	"time"
 )
 
 func RegisterModule(api fiber.Router, uow datastore.UnitOfWork, cfg *config.Config) {
+	// ไม่มี Key ลับ เลขที่ Order จะถูกย้อนกลับเป็นลำดับได้ (เปิดเผยยอดขายต่อวัน) จึงไม่ยอมเริ่มระบบ
+	if cfg.OrderNumberSecret == "" {
+		log.Fatal("FATAL: ORDER_NUMBER_SECRET must be set")
+	}
 
-	orderSvc := service.NewOrderService(uow)
+	taxEngine := taxService.NewEngine(taxService.SettingsFromConfig(cfg))
+	cartSvc := cartService.NewCartService(uow, cfg.ImageBaseURL, taxEngine)
+	// คืนเงินตอนยกเลิกหรือแก้ไข Order ที่ชำระแล้ว ผ่าน Gateway เดียวกับโมดูล payments
+	gw, err := gateway.New(cfg.PaymentProvider)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	paymentSvc := paymentService.NewPaymentService(uow, gw, cfg.PromptPayID)
+	orderSvc := service.NewOrderService(uow, cfg.ImageBaseURL, taxEngine, cartSvc, paymentSvc, cfg.OrderNumberSecret)
 	orderHdl := handler.NewOrderHandler(orderSvc)
 
+	// Idempotency-Key ป้องกัน Order ซ้ำเมื่อ Client Retry
+	idempotent := middleware.Idempotency(uow.IdempotencyRepository(), cfg.IdempotencyInFlightTimeout)
+
+	// --- Webhook จาก Payment Gateway เป็น Public แต่ต้องมี HMAC Signature ที่ถูกต้อง ---
+	// ต้องลงทะเบียนก่อนกลุ่ม /orders ที่ใช้ Protected() ไม่อย่างนั้นจะโดนบังคับ JWT ไปด้วย
+	api.Post("/orders/payments/webhook",
+		middleware.VerifyWebhookSignature(cfg.PaymentWebhookSecret, cfg.PaymentWebhookTolerance),
+		idempotent,
+		orderHdl.HandlePaymentWebhook,
+	)
+	// อัปเดตสถานะพัสดุจากขนส่ง ใช้ HMAC Signature แบบเดียวกับ Payment Webhook แต่คนละ Secret
+	api.Post("/orders/shipments/tracking",
+		middleware.VerifyWebhookSignature(cfg.CarrierWebhookSecret, cfg.CarrierWebhookTolerance),
+		orderHdl.HandleTrackingWebhook,
+	)
+
 	// สร้างกลุ่ม Route และป้องกันด้วย Middleware
 	orderAPI := api.Group("/orders", middleware.Protected())
 
-	orderAPI.Post("/", orderHdl.HandleCreateOrder)
+	orderAPI.Post("/", idempotent, orderHdl.HandleCreateOrder)
+	orderAPI.Post("/quote", orderHdl.HandleQuoteOrder)
 	orderAPI.Get("/", orderHdl.HandleGetMyOrders)
+	// ต้องอยู่ก่อน /:id ไม่อย่างนั้น "admin" จะถูกจับเป็น :id
+	orderAPI.Get("/admin", middleware.AdminRequired(), orderHdl.HandleAdminListOrders)
+	orderAPI.Get("/admin/export", middleware.AdminRequired(), orderHdl.HandleAdminExportOrders)
+	orderAPI.Get("/number/:number", orderHdl.HandleGetOrderByNumber)
 	orderAPI.Get("/:id", orderHdl.HandleGetOrderByID)
-	// --- เพิ่ม Route สำหรับ Webhook (เป็น Public แต่ในระบบจริงต้องมี Signature Verification) ---
-	orderAPI.Post("/payments/webhook", orderHdl.HandlePaymentWebhook)
+	orderAPI.Post("/:id/cancel", orderHdl.HandleCancelOrder)
+	orderAPI.Get("/:id/shipments", orderHdl.HandleGetShipments)
+	orderAPI.Post("/:id/reorder", orderHdl.HandleReorder)
 	// --- เพิ่ม Route สำหรับ Ship Order ---
 
 	adminOrderAPI := orderAPI.Group("", middleware.AdminRequired())
 	adminOrderAPI.Post("/:id/confirm-payment", orderHdl.HandleConfirmPayment)
 	adminOrderAPI.Post("/:id/ship", orderHdl.HandleShipOrder)
+	adminOrderAPI.Post("/:id/shipments", orderHdl.HandleCreateShipment)
+	adminOrderAPI.Get("/:id/history", orderHdl.HandleGetOrderHistory)
+	// แก้ไขรายการสินค้าของ Order ที่ยังไม่จัดส่ง (Order ที่ชำระแล้วจะคืนเงินหรือเรียกเก็บส่วนต่าง)
+	adminOrderAPI.Post("/:id/items", orderHdl.HandleAddOrderItem)
+	adminOrderAPI.Patch("/:id/items/:itemId", orderHdl.HandleUpdateOrderItem)
+	adminOrderAPI.Delete("/:id/items/:itemId", orderHdl.HandleRemoveOrderItem)
+	adminOrderAPI.Get("/:id/edits", orderHdl.HandleGetOrderEdits)
+	adminOrderAPI.Post("/admin/:id/cancel", orderHdl.HandleAdminCancelOrder)
+
+	// ยกเลิก Order ที่ไม่ชำระเงินภายในเวลาที่กำหนดอัตโนมัติ เพื่อคืนสต็อกที่ถูกจองไว้
+	service.StartOrderExpiryScheduler(context.Background(), orderSvc, cfg.PendingOrderTTL, cfg.OrderExpiryInterval)
+
+	// ลบ Idempotency-Key ที่หมดอายุแล้ว (Client ไม่ Retry ด้วยคีย์เดิมหลังจากนี้)
+	middleware.StartIdempotencyCleanup(context.Background(), uow.IdempotencyRepository(), cfg.IdempotencyRetention, time.Hour)
 
 	log.Println("✅ Order module registered successfully.")
 }
@@ -2,19 +2,44 @@ package repository
 
 import (
 	"backend/domain"
+	"backend/orders/dto"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var ErrOrderNotFound = errors.New("order not found")
 var ErrNotFound = errors.New("record not found")
+var ErrDuplicatePaymentEvent = errors.New("payment event has already been received")
+var ErrRefundExceedsPaid = errors.New("refund exceeds the amount paid for the order")
 
 type OrderRepository interface {
 	Create(order *domain.Order) error
 	FindByID(orderID uint) (*domain.Order, error)
+	FindByOrderNumber(number string) (*domain.Order, error)
+	NextOrderNumberSequence(date string) (uint, error)
 	FindAllByUserID(userID uint) ([]domain.Order, error)
+	FindAll(params dto.AdminOrderQueryParams) ([]domain.Order, error)
+	Count(params dto.AdminOrderQueryParams) (int64, error)
+	FindInBatches(params dto.AdminOrderQueryParams, batchSize int, fn func(orders []domain.Order) error) error
+	FindByStatusAndFinalPrice(status domain.OrderStatus, finalPrice float64) ([]domain.Order, error)
+	LockNextExpiredPending(cutoff time.Time, exclude []uint) (*domain.Order, error)
+	LockByID(orderID uint) (*domain.Order, error)
 	Update(order *domain.Order) error
+	AddRefund(orderID uint, amount, taxAmount float64) error
+	ReleaseRefund(orderID uint, amount, taxAmount float64) error
+	CreateItem(item *domain.OrderItem) error
+	UpdateItem(item *domain.OrderItem) error
+	DeleteItem(item *domain.OrderItem) error
+	ReplaceTaxLines(orderID uint, lines []domain.OrderTaxLine) error
+	CreateEdit(edit *domain.OrderEdit) error
+	FindEdits(orderID uint) ([]domain.OrderEdit, error)
+	CreateStatusHistory(history *domain.OrderStatusHistory) error
+	FindStatusHistory(orderID uint) ([]domain.OrderStatusHistory, error)
+	CreatePaymentEvent(event *domain.PaymentEvent) error
+	UpdatePaymentEvent(event *domain.PaymentEvent) error
 }
 
 type orderRepository struct {
@@ -33,7 +58,7 @@ func (r *orderRepository) Create(order *domain.Order) error {
 // FindByID ค้นหา Order ตาม ID พร้อมข้อมูลสินค้า
 func (r *orderRepository) FindByID(orderID uint) (*domain.Order, error) {
 	var order domain.Order
-	err := r.db.Preload("OrderItems.Product").Preload("ShippingAddress").First(&order, orderID).Error
+	err := r.db.Preload("OrderItems.Product").Preload("ShippingAddress").Preload("TaxLines").First(&order, orderID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrOrderNotFound
@@ -43,18 +68,257 @@ func (r *orderRepository) FindByID(orderID uint) (*domain.Order, error) {
 	return &order, nil
 }
 
+// FindByOrderNumber ค้นหา Order ตามเลขที่ Order (รูปแบบมาตรฐาน) พร้อมข้อมูลเดียวกับ FindByID
+func (r *orderRepository) FindByOrderNumber(number string) (*domain.Order, error) {
+	var order domain.Order
+	err := r.db.Preload("OrderItems.Product").Preload("ShippingAddress").Preload("TaxLines").
+		Where("order_number = ?", number).First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// NextOrderNumberSequence เพิ่มเลขลำดับ Order ของวันนั้นแล้วคืนค่าเลขใหม่ในคำสั่งเดียว
+// ควรเรียกนอก Transaction ของ Checkout เพื่อไม่ให้แถวของวันถูก Lock ค้างจน Checkout อื่นต้องรอ (เลขขาดช่วงได้)
+func (r *orderRepository) NextOrderNumberSequence(date string) (uint, error) {
+	var next uint
+	err := r.db.Raw(
+		`INSERT INTO order_number_sequences (date, last_number) VALUES (?, 1)
+		ON CONFLICT (date) DO UPDATE SET last_number = order_number_sequences.last_number + 1
+		RETURNING last_number`, date,
+	).Scan(&next).Error
+	return next, err
+}
+
 // FindAllByUserID ค้นหาทุก Order ของ User คนนั้น
 func (r *orderRepository) FindAllByUserID(userID uint) ([]domain.Order, error) {
 	var orders []domain.Order
-	err := r.db.Preload("OrderItems.Product").Where("user_id = ?", userID).Order("created_at desc").Find(&orders).Error
+	err := r.db.Preload("OrderItems.Product").Preload("TaxLines").Where("user_id = ?", userID).Order("created_at desc").Find(&orders).Error
+	return orders, err
+}
+
+// FindAll ดึง Order ทั้งหมดตาม Filter และการแบ่งหน้าสำหรับหน้า Admin
+func (r *orderRepository) FindAll(params dto.AdminOrderQueryParams) ([]domain.Order, error) {
+	var orders []domain.Order
+	offset := (params.Page - 1) * params.Limit
+	orderBy := "orders." + params.SortBy + " " + params.Order
+	err := r.adminOrderFilter(params).
+		Preload("User").Preload("OrderItems").
+		Offset(offset).Limit(params.Limit).Order(orderBy).
+		Find(&orders).Error
+	return orders, err
+}
+
+// Count นับจำนวน Order ที่ตรงกับ Filter (ใช้คำนวณจำนวนหน้า)
+func (r *orderRepository) Count(params dto.AdminOrderQueryParams) (int64, error) {
+	var count int64
+	err := r.adminOrderFilter(params).Count(&count).Error
+	return count, err
+}
+
+// FindInBatches ดึง Order ที่ตรงกับ Filter ทีละ batchSize รายการ (เรียงตาม ID) แล้วส่งให้ fn
+// ใช้กับการ Export ข้อมูลจำนวนมากโดยไม่ต้องโหลดทั้งหมดเข้า Memory
+func (r *orderRepository) FindInBatches(params dto.AdminOrderQueryParams, batchSize int, fn func(orders []domain.Order) error) error {
+	var orders []domain.Order
+	return r.adminOrderFilter(params).
+		Preload("User").Preload("OrderItems.Product").Preload("ShippingAddress").
+		FindInBatches(&orders, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(orders)
+		}).Error
+}
+
+// adminOrderFilter สร้าง Query ที่ใส่เงื่อนไขจาก params แล้ว ใช้ร่วมกันระหว่าง FindAll และ Count
+func (r *orderRepository) adminOrderFilter(params dto.AdminOrderQueryParams) *gorm.DB {
+	query := r.db.Model(&domain.Order{})
+
+	if params.Status != "" {
+		query = query.Where("orders.status = ?", params.Status)
+	}
+	if params.DateFrom != nil {
+		query = query.Where("orders.created_at >= ?", *params.DateFrom)
+	}
+	if params.DateTo != nil {
+		query = query.Where("orders.created_at < ?", *params.DateTo)
+	}
+	if params.OrderNumber != "" {
+		query = query.Where("orders.order_number = ?", params.OrderNumber)
+	}
+	if params.CustomerEmail != "" {
+		query = query.Joins("JOIN users ON users.id = orders.user_id").
+			Where("users.email ILIKE ?", "%"+params.CustomerEmail+"%")
+	}
+	if params.CouponCode != "" {
+		query = query.Where("orders.applied_coupon_code = ?", params.CouponCode)
+	}
+	if params.MinTotal != nil {
+		query = query.Where("orders.final_price >= ?", *params.MinTotal)
+	}
+	if params.MaxTotal != nil {
+		query = query.Where("orders.final_price <= ?", *params.MaxTotal)
+	}
+	if len(params.OrderIDs) > 0 {
+		query = query.Where("orders.id IN ?", params.OrderIDs)
+	}
+	return query
+}
+
+// FindByStatusAndFinalPrice ค้นหา Order ตามสถานะและยอดที่ต้องชำระ (เทียบถึงหลักสตางค์)
+// ใช้สำหรับกระทบยอดเงินโอนที่ไม่มีเลขอ้างอิง
+func (r *orderRepository) FindByStatusAndFinalPrice(status domain.OrderStatus, finalPrice float64) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := r.db.Where("status = ? AND ROUND(final_price::numeric, 2) = ROUND(?::numeric, 2)", status, finalPrice).
+		Order("created_at asc").Find(&orders).Error
 	return orders, err
 }
 
+// LockNextExpiredPending หา Order ที่ค้างสถานะ pending ตั้งแต่ก่อน cutoff มา 1 รายการ และ Lock แถวไว้
+// ใช้ FOR UPDATE SKIP LOCKED ทำให้หลาย Instance รันพร้อมกันได้โดยไม่หยิบ Order เดียวกัน
+// exclude คือ ID ของ Order ที่ไม่ต้องหยิบ (เช่น Order ที่ยกเลิกไม่สำเร็จไปแล้วในรอบเดียวกัน)
+// ต้องเรียกภายใน Transaction (uow.Execute) เท่านั้น ถ้าไม่เจอจะคืน ErrOrderNotFound
+func (r *orderRepository) LockNextExpiredPending(cutoff time.Time, exclude []uint) (*domain.Order, error) {
+	var order domain.Order
+	query := r.db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND created_at < ?", domain.StatusPending, cutoff)
+	if len(exclude) > 0 {
+		query = query.Where("id NOT IN ?", exclude)
+	}
+	err := query.
+		Order("created_at asc").
+		First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if err := r.db.Model(&order).Association("OrderItems").Find(&order.OrderItems); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// LockByID ดึง Order พร้อม OrderItems และ TaxLines แล้ว Lock แถว Order ไว้ (SELECT ... FOR UPDATE)
+// ใช้เมื่อหลาย Request อาจแก้ Order เดียวกันพร้อมกัน ต้องเรียกภายใน Transaction เท่านั้น
+// TaxLines ถูกโหลดด้วยเพราะการออกใบกำกับภาษีตอนเปลี่ยนสถานะต้องใช้อัตราภาษีของ Order
+func (r *orderRepository) LockByID(orderID uint) (*domain.Order, error) {
+	var order domain.Order
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if err := r.db.Model(&order).Association("OrderItems").Find(&order.OrderItems); err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&order).Association("TaxLines").Find(&order.TaxLines); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 func (r *orderRepository) Update(order *domain.Order) error {
 	// Save จะทำการอัปเดตทุกฟิลด์ของ object ที่มี Primary Key อยู่แล้ว
 	return r.db.Save(order).Error
 }
 
+// AddRefund เพิ่มยอดคืนเงินของ Order แบบ Atomic ถ้ายอดรวมจะเกิน FinalPrice จะคืน ErrRefundExceedsPaid
+func (r *orderRepository) AddRefund(orderID uint, amount, taxAmount float64) error {
+	tx := r.db.Model(&domain.Order{}).
+		Where("id = ? AND refunded_amount + ? <= final_price + 0.005", orderID, amount).
+		Updates(map[string]interface{}{
+			"refunded_amount":     gorm.Expr("refunded_amount + ?", amount),
+			"refunded_tax_amount": gorm.Expr("refunded_tax_amount + ?", taxAmount),
+		})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrRefundExceedsPaid
+	}
+	return nil
+}
+
+// ReleaseRefund ปล่อยยอดคืนเงินที่จองไว้ด้วย AddRefund กลับคืน (ใช้เมื่อ Gateway คืนเงินไม่สำเร็จ)
+func (r *orderRepository) ReleaseRefund(orderID uint, amount, taxAmount float64) error {
+	return r.db.Model(&domain.Order{}).
+		Where("id = ?", orderID).
+		Updates(map[string]interface{}{
+			"refunded_amount":     gorm.Expr("GREATEST(refunded_amount - ?, 0)", amount),
+			"refunded_tax_amount": gorm.Expr("GREATEST(refunded_tax_amount - ?, 0)", taxAmount),
+		}).Error
+}
+
+func (r *orderRepository) CreateItem(item *domain.OrderItem) error {
+	return r.db.Omit(clause.Associations).Create(item).Error
+}
+
+func (r *orderRepository) UpdateItem(item *domain.OrderItem) error {
+	return r.db.Omit(clause.Associations).Save(item).Error
+}
+
+func (r *orderRepository) DeleteItem(item *domain.OrderItem) error {
+	return r.db.Delete(item).Error
+}
+
+// ReplaceTaxLines ลบยอดภาษีแยกตามอัตราเดิมของ Order แล้วบันทึกชุดใหม่แทน (ใช้ตอนแก้ไขรายการสินค้า)
+func (r *orderRepository) ReplaceTaxLines(orderID uint, lines []domain.OrderTaxLine) error {
+	if err := r.db.Where("order_id = ?", orderID).Delete(&domain.OrderTaxLine{}).Error; err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	for i := range lines {
+		lines[i].OrderID = orderID
+	}
+	return r.db.Create(&lines).Error
+}
+
+func (r *orderRepository) CreateEdit(edit *domain.OrderEdit) error {
+	return r.db.Create(edit).Error
+}
+
+// FindEdits ดึงประวัติการแก้ไขรายการสินค้าของ Order เรียงจากเก่าไปใหม่
+func (r *orderRepository) FindEdits(orderID uint) ([]domain.OrderEdit, error) {
+	var edits []domain.OrderEdit
+	err := r.db.Where("order_id = ?", orderID).Order("created_at asc, id asc").Find(&edits).Error
+	return edits, err
+}
+
+// CreateStatusHistory บันทึกประวัติการเปลี่ยนสถานะของ Order
+func (r *orderRepository) CreateStatusHistory(history *domain.OrderStatusHistory) error {
+	return r.db.Create(history).Error
+}
+
+// FindStatusHistory ดึงประวัติสถานะทั้งหมดของ Order เรียงจากเก่าไปใหม่
+func (r *orderRepository) FindStatusHistory(orderID uint) ([]domain.OrderStatusHistory, error) {
+	var histories []domain.OrderStatusHistory
+	err := r.db.Where("order_id = ?", orderID).Order("created_at asc, id asc").Find(&histories).Error
+	return histories, err
+}
+
+// CreatePaymentEvent บันทึก Webhook Event ถ้าเคยได้รับ Event ID นี้แล้วจะคืน ErrDuplicatePaymentEvent
+func (r *orderRepository) CreatePaymentEvent(event *domain.PaymentEvent) error {
+	tx := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrDuplicatePaymentEvent
+	}
+	return nil
+}
+
+func (r *orderRepository) UpdatePaymentEvent(event *domain.PaymentEvent) error {
+	return r.db.Save(event).Error
+}
+
 func (r *orderRepository) UpdateTrackingInfo(orderID uint, trackingNumber string) error {
 	var order domain.Order
 	err := r.db.First(&order, orderID).Error
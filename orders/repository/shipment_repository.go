@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrShipmentNotFound = errors.New("shipment not found")
+var ErrDuplicateTrackingEvent = errors.New("tracking event has already been received")
+
+// ShipmentRepository จัดการพัสดุ (Shipment) ของ Order และประวัติสถานะจากขนส่ง
+type ShipmentRepository interface {
+	Create(shipment *domain.Shipment) error
+	Update(shipment *domain.Shipment) error
+	FindByOrderID(orderID uint) ([]domain.Shipment, error)
+	FindByTracking(carrier, trackingNumber string) (*domain.Shipment, error)
+	FindByCODStatus(status domain.CODStatus) ([]domain.Shipment, error)
+	CreateTrackingEvent(event *domain.TrackingEvent) error
+}
+
+type shipmentRepository struct {
+	db *gorm.DB
+}
+
+func NewShipmentRepository(db *gorm.DB) ShipmentRepository {
+	return &shipmentRepository{db: db}
+}
+
+// Create สร้าง Shipment พร้อม Items และ Events ที่แนบมา
+func (r *shipmentRepository) Create(shipment *domain.Shipment) error {
+	return r.db.Create(shipment).Error
+}
+
+// Update อัปเดตเฉพาะข้อมูลของ Shipment (ไม่แตะ Items และ Events)
+func (r *shipmentRepository) Update(shipment *domain.Shipment) error {
+	return r.db.Omit(clause.Associations).Save(shipment).Error
+}
+
+// FindByOrderID ดึงพัสดุทั้งหมดของ Order พร้อมรายการสินค้าและประวัติสถานะ (เรียงจากเก่าไปใหม่)
+func (r *shipmentRepository) FindByOrderID(orderID uint) ([]domain.Shipment, error) {
+	var shipments []domain.Shipment
+	err := r.db.
+		Preload("Items").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("occurred_at asc, id asc") }).
+		Where("order_id = ?", orderID).
+		Order("created_at asc, id asc").
+		Find(&shipments).Error
+	return shipments, err
+}
+
+// FindByTracking หา Shipment จากชื่อขนส่งและเลขพัสดุ (ไม่สนตัวพิมพ์เล็กใหญ่ของชื่อขนส่ง)
+func (r *shipmentRepository) FindByTracking(carrier, trackingNumber string) (*domain.Shipment, error) {
+	var shipment domain.Shipment
+	err := r.db.Where("LOWER(carrier) = LOWER(?) AND tracking_number = ?", carrier, trackingNumber).First(&shipment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShipmentNotFound
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// FindByCODStatus ดึงพัสดุเก็บเงินปลายทางตามสถานะการโอนเงิน (ว่าง = ทุกสถานะ) เรียงจากเก่าไปใหม่
+func (r *shipmentRepository) FindByCODStatus(status domain.CODStatus) ([]domain.Shipment, error) {
+	var shipments []domain.Shipment
+	query := r.db.Where("cod_status <> ''")
+	if status != "" {
+		query = query.Where("cod_status = ?", status)
+	}
+	err := query.Order("created_at asc, id asc").Find(&shipments).Error
+	return shipments, err
+}
+
+// CreateTrackingEvent บันทึก Event จากขนส่ง ถ้าเคยได้รับ Event ID นี้ของพัสดุนี้แล้วจะคืน ErrDuplicateTrackingEvent
+func (r *shipmentRepository) CreateTrackingEvent(event *domain.TrackingEvent) error {
+	tx := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrDuplicateTrackingEvent
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package service_test
+
+// ทดสอบการตัดสต็อกภายใต้ Checkout พร้อมกันหลาย Request
+// สร้างสินค้าที่มีสต็อกน้อยๆ 1 ชิ้น และผู้ซื้อหลายคนที่มีสินค้านั้นในตะกร้า
+// แล้วยิง CreateOrderFromCart พร้อมกันทั้งหมด ก่อนตรวจว่าสต็อกไม่ติดลบและไม่มีการขายเกิน
+//
+// ต้องใช้ PostgreSQL จริง จึงรันเฉพาะเมื่อกำหนด CHECKOUT_RACE_DSN (ควรเป็น Database สำหรับทดสอบเท่านั้น)
+// ข้อมูลที่สร้างขึ้นทั้งหมดจะถูกลบทิ้งเมื่อจบการทดสอบ
+//
+//	CHECKOUT_RACE_DSN="host=localhost user=... dbname=shop_test sslmode=disable" go test ./orders/service -run TestCheckoutRace -v
+
+import (
+	cartService "backend/carts/service"
+	"backend/config"
+	"backend/domain"
+	"backend/internal/datastore"
+	"backend/orders/dto"
+	"backend/orders/service"
+	taxService "backend/taxes/service"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	raceInitialStock = 3  // สต็อกเริ่มต้นของสินค้าที่ใช้ทดสอบ
+	raceBuyers       = 50 // จำนวน Checkout ที่ยิงพร้อมกัน
+)
+
+func TestCheckoutRace(t *testing.T) {
+	dsn := os.Getenv("CHECKOUT_RACE_DSN")
+	if dsn == "" {
+		t.Skip("CHECKOUT_RACE_DSN is not set")
+	}
+
+	db, err := config.ConnectDatabase(dsn)
+	if err != nil {
+		t.Fatalf("could not connect to database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&domain.Category{}, &domain.Product{}, &domain.ProductImage{},
+		&domain.User{}, &domain.Address{},
+		&domain.Cart{}, &domain.CartItem{},
+		&domain.Order{}, &domain.OrderItem{}, &domain.OrderStatusHistory{}, &domain.OrderNumberSequence{},
+		&domain.Coupon{}, &domain.IdempotencyKey{},
+		&domain.TaxClass{}, &domain.TaxRate{}, &domain.OrderTaxLine{},
+		&domain.ShippingZone{}, &domain.ShippingMethod{}, &domain.GiftWrapOption{},
+		&domain.OutboxEvent{},
+	); err != nil {
+		t.Fatalf("could not migrate test database: %v", err)
+	}
+
+	uow := datastore.NewUnitOfWork(db, nil) // Checkout ไม่ได้ใช้ UploadRepository
+	taxEngine := taxService.NewEngine(taxService.Settings{
+		PricesIncludeTax: true,
+		DefaultClassCode: "standard",
+		DefaultCountry:   "TH",
+	})
+	orderSvc := service.NewOrderService(uow, "", taxEngine, cartService.NewCartService(uow, "", taxEngine), nil, "checkout-race") // Checkout ไม่คืนเงิน
+
+	// 1. เตรียมข้อมูล: สินค้า 1 ชิ้นที่สต็อกน้อย และผู้ซื้อที่มีสินค้านั้นในตะกร้าคนละ 1 ชิ้น
+	fx := &raceFixtures{db: db}
+	t.Cleanup(func() { fx.cleanup(t) })
+
+	runID := time.Now().UnixNano()
+	category := &domain.Category{Name: fmt.Sprintf("checkout-race-%d", runID)}
+	fx.create(t, category)
+	fx.categoryID = category.ID
+	product := &domain.Product{
+		Name:       fmt.Sprintf("Checkout Race %d", runID),
+		Price:      100,
+		Quantity:   raceInitialStock,
+		SKU:        fmt.Sprintf("RACE-%d", runID),
+		CategoryID: category.ID,
+	}
+	fx.create(t, product)
+	fx.productID = product.ID
+
+	// วิธีจัดส่งแบบรับเองที่ร้าน (ไม่ผูกโซน) เพื่อให้ Checkout ไม่ต้องพึ่งการตั้งค่าค่าจัดส่ง
+	pickup := &domain.ShippingMethod{
+		Name:     fmt.Sprintf("Checkout Race Pickup %d", runID),
+		Type:     domain.ShippingStorePickup,
+		IsActive: true,
+	}
+	fx.create(t, pickup)
+	fx.shippingMethodID = pickup.ID
+
+	type buyer struct {
+		userID    uint
+		addressID uint
+	}
+	buyers := make([]buyer, 0, raceBuyers)
+	for i := 0; i < raceBuyers; i++ {
+		user := &domain.User{
+			FirstName: "Race",
+			LastName:  fmt.Sprintf("Buyer %d", i),
+			Email:     fmt.Sprintf("race-%d-%d@example.com", runID, i),
+			Password:  "-",
+			Role:      domain.RoleCustomer,
+			IsActive:  true,
+		}
+		fx.create(t, user)
+		fx.userIDs = append(fx.userIDs, user.ID)
+		address := &domain.Address{
+			UserID:       user.ID,
+			AddressLine1: "1 Test Road",
+			City:         "Bangkok",
+			State:        "Bangkok",
+			PostalCode:   "10100",
+			Country:      "TH",
+		}
+		fx.create(t, address)
+		cart, err := uow.CartRepository().GetOrCreateCart(user.ID)
+		if err != nil {
+			t.Fatalf("could not create cart: %v", err)
+		}
+		if _, err := uow.CartRepository().AddItem(cart.ID, product.ID, 1, product.Price); err != nil {
+			t.Fatalf("could not add item to cart: %v", err)
+		}
+		buyers = append(buyers, buyer{userID: user.ID, addressID: address.ID})
+	}
+
+	// 2. ยิง Checkout พร้อมกันทั้งหมด (ใช้ channel เป็นสัญญาณเริ่มให้ทุก goroutine ออกตัวพร้อมกัน)
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		succeeded  int
+		outOfStock int
+		failures   []error
+	)
+	start := make(chan struct{})
+	for _, b := range buyers {
+		wg.Add(1)
+		go func(b buyer) {
+			defer wg.Done()
+			<-start
+			_, err := orderSvc.CreateOrderFromCart(context.Background(), b.userID, dto.CreateOrderRequest{
+				ShippingAddressID: b.addressID,
+				ShippingMethodID:  pickup.ID,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, service.ErrProductOutOfStock):
+				outOfStock++
+			default:
+				failures = append(failures, err)
+			}
+		}(b)
+	}
+	close(start)
+	wg.Wait()
+
+	// 3. ตรวจผลลัพธ์
+	var finalStock int
+	if err := db.Model(&domain.Product{}).Where("id = ?", product.ID).Select("quantity").Row().Scan(&finalStock); err != nil {
+		t.Fatalf("could not read final stock: %v", err)
+	}
+	t.Logf("buyers=%d initial_stock=%d succeeded=%d out_of_stock=%d other_errors=%d final_stock=%d",
+		raceBuyers, raceInitialStock, succeeded, outOfStock, len(failures), finalStock)
+
+	for _, err := range failures {
+		t.Errorf("unexpected checkout error: %v", err)
+	}
+	if finalStock < 0 {
+		t.Errorf("stock went negative: %d", finalStock)
+	}
+	if succeeded > raceInitialStock {
+		t.Errorf("oversold %d orders for %d items in stock", succeeded, raceInitialStock)
+	}
+	if finalStock != raceInitialStock-succeeded {
+		t.Errorf("final stock %d does not match %d successful orders", finalStock, succeeded)
+	}
+	if expected := min(raceInitialStock, raceBuyers); succeeded != expected {
+		t.Errorf("expected %d successful orders, got %d", expected, succeeded)
+	}
+}
+
+// raceFixtures เก็บ ID ของข้อมูลที่การทดสอบสร้างขึ้น เพื่อลบทิ้งทั้งหมดตอนจบ
+type raceFixtures struct {
+	db               *gorm.DB
+	categoryID       uint
+	productID        uint
+	shippingMethodID uint
+	userIDs          []uint
+}
+
+func (f *raceFixtures) create(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := f.db.Create(value).Error; err != nil {
+		t.Fatalf("could not create %T: %v", value, err)
+	}
+}
+
+// cleanup ลบข้อมูลถาวร (Unscoped) โดยลบตารางลูกก่อนตารางแม่ตาม Foreign Key
+// ทุก Query เริ่มจาก f.db ใหม่ เพราะ *gorm.DB ที่ Chain แล้วจะสะสมเงื่อนไขข้ามคำสั่ง
+func (f *raceFixtures) cleanup(t *testing.T) {
+	users := nonEmpty(f.userIDs)
+	orderIDs := f.db.Unscoped().Model(&domain.Order{}).Select("id").Where("user_id IN ?", users)
+	cartIDs := f.db.Unscoped().Model(&domain.Cart{}).Select("id").Where("user_id IN ?", users)
+
+	steps := []struct {
+		name  string
+		model interface{}
+		query string
+		args  []interface{}
+	}{
+		{"outbox events", &domain.OutboxEvent{}, "(aggregate_type = ? AND aggregate_id IN (?)) OR (aggregate_type = ? AND aggregate_id = ?)",
+			[]interface{}{domain.AggregateOrder, orderIDs, domain.AggregateProduct, f.productID}},
+		{"order tax lines", &domain.OrderTaxLine{}, "order_id IN (?)", []interface{}{orderIDs}},
+		{"order status history", &domain.OrderStatusHistory{}, "order_id IN (?)", []interface{}{orderIDs}},
+		{"order items", &domain.OrderItem{}, "order_id IN (?)", []interface{}{orderIDs}},
+		{"orders", &domain.Order{}, "user_id IN ?", []interface{}{users}},
+		{"cart items", &domain.CartItem{}, "cart_id IN (?)", []interface{}{cartIDs}},
+		{"carts", &domain.Cart{}, "user_id IN ?", []interface{}{users}},
+		{"addresses", &domain.Address{}, "user_id IN ?", []interface{}{users}},
+		{"users", &domain.User{}, "id IN ?", []interface{}{users}},
+		{"product", &domain.Product{}, "id = ?", []interface{}{f.productID}},
+		{"category", &domain.Category{}, "id = ?", []interface{}{f.categoryID}},
+		{"shipping method", &domain.ShippingMethod{}, "id = ?", []interface{}{f.shippingMethodID}},
+	}
+	for _, step := range steps {
+		if err := f.db.Unscoped().Where(step.query, step.args...).Delete(step.model).Error; err != nil {
+			t.Errorf("could not clean up %s: %v", step.name, err)
+		}
+	}
+}
+
+// nonEmpty กัน IN () ว่างซึ่ง PostgreSQL ไม่รองรับ (0 ไม่ตรงกับ ID ใดเลย)
+func nonEmpty(ids []uint) []uint {
+	if len(ids) == 0 {
+		return []uint{0}
+	}
+	return ids
+}
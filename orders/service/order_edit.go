@@ -0,0 +1,505 @@
+package service
+
+import (
+	couponRepo "backend/coupons/repository"
+	"backend/domain"
+	"backend/internal/datastore"
+	invoiceService "backend/invoices/service"
+	"backend/orders/dto"
+	outboxService "backend/outbox/service"
+	productRepo "backend/products/repository"
+	shippingService "backend/shipping/service"
+	taxService "backend/taxes/service"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+)
+
+var (
+	ErrOrderItemNotFound = errors.New("order item not found")
+	ErrInvalidOrderEdit  = errors.New("order item edit is not valid")
+)
+
+// editableStatuses คือสถานะของ Order ที่ Admin ยังแก้ไขรายการสินค้าได้ (ต้องยังไม่มีพัสดุด้วย)
+// Order ที่ชำระแล้วจะถูกปรับยอดตามการแก้ไข: ยอดลดคืนเงินส่วนต่าง ยอดเพิ่มเรียกเก็บเป็น BalanceDue
+// และออกใบลดหนี้/ใบเพิ่มหนี้อ้างอิงใบกำกับภาษีเดิม (ดู settleEdit)
+var editableStatuses = map[domain.OrderStatus]bool{
+	domain.StatusPending:    true,
+	domain.StatusProcessing: true,
+}
+
+// AddOrderItem เพิ่มสินค้าเข้า Order ในราคาปัจจุบัน ถ้ามีสินค้านี้อยู่แล้วจะเพิ่มจำนวนให้รายการเดิม
+func (s *orderService) AddOrderItem(ctx context.Context, adminID, orderID uint, req dto.AddOrderItemRequest) (*dto.OrderResponse, error) {
+	return s.editOrder(ctx, adminID, orderID, req.Reason, func(repos *datastore.Repositories, order *domain.Order) (*domain.OrderEdit, error) {
+		for i := range order.OrderItems {
+			item := &order.OrderItems[i]
+			if item.ProductID != req.ProductID {
+				continue
+			}
+			if err := decreaseStock(repos, order.ID, item.ProductID, item.ProductName, req.Quantity); err != nil {
+				return nil, err
+			}
+			edit := &domain.OrderEdit{
+				Action:      domain.OrderEditAddItem,
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				OldQuantity: item.Quantity,
+				NewQuantity: item.Quantity + req.Quantity,
+			}
+			item.Quantity += req.Quantity
+			return edit, nil
+		}
+
+		product, err := findOrderableProduct(repos, req.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if err := decreaseStock(repos, order.ID, product.ID, product.Name, req.Quantity); err != nil {
+			return nil, err
+		}
+		item := domain.OrderItem{
+			OrderID:          order.ID,
+			ProductID:        product.ID,
+			Quantity:         req.Quantity,
+			Price:            product.Price,
+			ProductName:      product.Name,
+			ProductSKU:       product.SKU,
+			ProductImagePath: product.PrimaryImagePath(),
+		}
+		if err := repos.Order.CreateItem(&item); err != nil {
+			return nil, fmt.Errorf("failed to add order item: %w", err)
+		}
+		order.OrderItems = append(order.OrderItems, item)
+
+		return &domain.OrderEdit{
+			Action:      domain.OrderEditAddItem,
+			OrderItemID: item.ID,
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			NewQuantity: req.Quantity,
+		}, nil
+	})
+}
+
+// UpdateOrderItem เปลี่ยนจำนวนของรายการ หรือเปลี่ยนเป็นสินค้าตัวอื่นในราคาปัจจุบัน
+// การเปลี่ยนแค่จำนวนจะคงราคาเดิม ณ เวลาที่สั่งซื้อไว้
+func (s *orderService) UpdateOrderItem(ctx context.Context, adminID, orderID, itemID uint, req dto.UpdateOrderItemRequest) (*dto.OrderResponse, error) {
+	return s.editOrder(ctx, adminID, orderID, req.Reason, func(repos *datastore.Repositories, order *domain.Order) (*domain.OrderEdit, error) {
+		item, err := findOrderItem(order, itemID)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.ProductID == 0 || req.ProductID == item.ProductID {
+			if req.Quantity == item.Quantity {
+				return nil, fmt.Errorf("%w: quantity is unchanged", ErrInvalidOrderEdit)
+			}
+			if req.Quantity > item.Quantity {
+				err = decreaseStock(repos, order.ID, item.ProductID, item.ProductName, req.Quantity-item.Quantity)
+			} else {
+				err = restoreStock(repos, order.ID, item.ProductID, item.Quantity-req.Quantity, outboxService.StockReasonOrderEdited)
+			}
+			if err != nil {
+				return nil, err
+			}
+			edit := &domain.OrderEdit{
+				Action:      domain.OrderEditChangeQuantity,
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				OldQuantity: item.Quantity,
+				NewQuantity: req.Quantity,
+			}
+			item.Quantity = req.Quantity
+			return edit, nil
+		}
+
+		// เปลี่ยนสินค้า: สินค้าใหม่ต้องยังไม่อยู่ใน Order (ถ้ามีแล้วให้ลบรายการนี้แล้วเพิ่มจำนวนรายการนั้นแทน)
+		for _, other := range order.OrderItems {
+			if other.ProductID == req.ProductID {
+				return nil, fmt.Errorf("%w: product %d is already in this order", ErrInvalidOrderEdit, req.ProductID)
+			}
+		}
+		product, err := findOrderableProduct(repos, req.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if err := restoreStock(repos, order.ID, item.ProductID, item.Quantity, outboxService.StockReasonOrderEdited); err != nil {
+			return nil, err
+		}
+		if err := decreaseStock(repos, order.ID, product.ID, product.Name, req.Quantity); err != nil {
+			return nil, err
+		}
+
+		previousProductID := item.ProductID
+		edit := &domain.OrderEdit{
+			Action:            domain.OrderEditSwapItem,
+			OrderItemID:       item.ID,
+			ProductID:         product.ID,
+			PreviousProductID: &previousProductID,
+			ProductName:       product.Name,
+			OldQuantity:       item.Quantity,
+			NewQuantity:       req.Quantity,
+		}
+		item.ProductID = product.ID
+		item.Product = domain.Product{}
+		item.Quantity = req.Quantity
+		item.Price = product.Price
+		item.ProductName = product.Name
+		item.ProductSKU = product.SKU
+		item.ProductImagePath = product.PrimaryImagePath()
+		return edit, nil
+	})
+}
+
+// RemoveOrderItem ลบรายการสินค้าออกจาก Order และคืนสต็อก (ต้องเหลืออย่างน้อย 1 รายการ ถ้าไม่ต้องการเลยให้ยกเลิก Order แทน)
+func (s *orderService) RemoveOrderItem(ctx context.Context, adminID, orderID, itemID uint, req dto.RemoveOrderItemRequest) (*dto.OrderResponse, error) {
+	return s.editOrder(ctx, adminID, orderID, req.Reason, func(repos *datastore.Repositories, order *domain.Order) (*domain.OrderEdit, error) {
+		item, err := findOrderItem(order, itemID)
+		if err != nil {
+			return nil, err
+		}
+		if len(order.OrderItems) == 1 {
+			return nil, fmt.Errorf("%w: cannot remove the last item, cancel the order instead", ErrInvalidOrderEdit)
+		}
+
+		if err := restoreStock(repos, order.ID, item.ProductID, item.Quantity, outboxService.StockReasonOrderEdited); err != nil {
+			return nil, err
+		}
+		if err := repos.Order.DeleteItem(item); err != nil {
+			return nil, fmt.Errorf("failed to remove order item: %w", err)
+		}
+
+		edit := &domain.OrderEdit{
+			Action:      domain.OrderEditRemoveItem,
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			OldQuantity: item.Quantity,
+		}
+		remaining := make([]domain.OrderItem, 0, len(order.OrderItems)-1)
+		for _, other := range order.OrderItems {
+			if other.ID != itemID {
+				remaining = append(remaining, other)
+			}
+		}
+		order.OrderItems = remaining
+		return edit, nil
+	})
+}
+
+// GetOrderEdits ดึงประวัติการแก้ไขรายการสินค้าของ Order (สำหรับ Admin)
+func (s *orderService) GetOrderEdits(orderID uint) ([]dto.OrderEditResponse, error) {
+	if _, err := s.uow.OrderRepository().FindByID(orderID); err != nil {
+		return nil, err
+	}
+	edits, err := s.uow.OrderRepository().FindEdits(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OrderEditResponse, 0, len(edits))
+	for _, edit := range edits {
+		responses = append(responses, dto.OrderEditResponse{
+			ID:                edit.ID,
+			Action:            edit.Action,
+			OrderItemID:       edit.OrderItemID,
+			ProductID:         edit.ProductID,
+			PreviousProductID: edit.PreviousProductID,
+			ProductName:       edit.ProductName,
+			OldQuantity:       edit.OldQuantity,
+			NewQuantity:       edit.NewQuantity,
+			OldFinalPrice:     edit.OldFinalPrice,
+			NewFinalPrice:     edit.NewFinalPrice,
+			RefundAmount:      edit.RefundAmount,
+			BalanceDue:        edit.BalanceDue,
+			InvoiceAdjustment: edit.InvoiceAdjustmentNumber,
+			EditedBy:          edit.EditedBy,
+			Reason:            edit.Reason,
+			CreatedAt:         edit.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// editOrder คือขั้นตอนร่วมของการแก้ไขรายการสินค้า: Lock Order, ตรวจสถานะ, ให้ apply แก้รายการและสต็อก
+// แล้วคำนวณยอดใหม่ ปรับยอดของ Order ที่ชำระแล้ว และบันทึก OrderEdit ใน Transaction เดียวกัน
+// ส่วนต่างที่ต้องคืนเงินถูกจองไว้ใน Transaction แล้วคืนผ่าน Gateway หลัง Commit
+func (s *orderService) editOrder(ctx context.Context, adminID, orderID uint, reason string, apply func(repos *datastore.Repositories, order *domain.Order) (*domain.OrderEdit, error)) (*dto.OrderResponse, error) {
+	var (
+		order     *domain.Order
+		refunds   []domain.OrderRefund
+		cancelled []domain.Payment
+	)
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		order, err = repos.Order.LockByID(orderID)
+		if err != nil {
+			return err
+		}
+		if !editableStatuses[order.Status] {
+			return fmt.Errorf("%w: cannot edit items of an order in '%s' status", ErrInvalidOrderStatus, order.Status)
+		}
+		// แบ่งส่งไปแล้วบางส่วนก็แก้ไม่ได้ เพราะรายการที่อยู่กับขนส่งแล้วต้องตรงกับ Order
+		shipments, err := repos.Shipment.FindByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
+		if len(shipments) > 0 {
+			return fmt.Errorf("%w: order already has shipments", ErrInvalidOrderStatus)
+		}
+
+		oldFinalPrice, oldTaxAmount := order.FinalPrice, order.TaxAmount
+		edit, err := apply(repos, order)
+		if err != nil {
+			return err
+		}
+		if err := s.repriceOrder(repos, order); err != nil {
+			return err
+		}
+		if refunds, err = s.settleEdit(repos, order, edit, oldFinalPrice, oldTaxAmount, reason); err != nil {
+			return err
+		}
+
+		if cancelled, err = cancelOpenPayments(repos, order); err != nil {
+			return err
+		}
+
+		edit.OrderID = order.ID
+		edit.EditedBy = adminID
+		edit.OldFinalPrice = oldFinalPrice
+		edit.NewFinalPrice = order.FinalPrice
+		edit.Reason = reason
+		if err := repos.Order.CreateEdit(edit); err != nil {
+			return fmt.Errorf("failed to record order edit: %w", err)
+		}
+		return outboxService.RecordOrderEvent(repos, domain.EventOrderUpdated, order, "", reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cancelled) > 0 {
+		s.payments.CancelIntents(ctx, cancelled)
+	}
+	if len(refunds) > 0 {
+		// ส่วนที่คืนผ่าน Gateway ไม่สำเร็จถูกบันทึกเป็นการคืนเงินนอกระบบไว้แล้ว Admin ต้องโอนคืนเอง
+		if _, err := s.payments.SettleRefunds(ctx, adminID, order, nil, refunds); err != nil {
+			log.Printf("WARNING: order %d was edited but the difference could not be refunded via the gateway: %v", orderID, err)
+		}
+	}
+
+	updated, err := s.uow.OrderRepository().FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapOrderToOrderResponse(updated), nil
+}
+
+// settleEdit ปรับยอดของ Order ที่ชำระแล้วหลังการแก้ไข (Order ที่ยังไม่ชำระไม่ต้องทำอะไร)
+// ยอดเพิ่มบวกเข้า BalanceDue ให้ลูกค้าชำระเพิ่ม ยอดลดหักจาก BalanceDue ที่ค้างก่อน ส่วนที่เหลือจองคืนเงิน
+// แล้วออกใบลดหนี้/ใบเพิ่มหนี้ถ้า Order มีใบกำกับภาษีแล้ว คืนค่ารายการคืนเงินที่ต้อง Settle หลัง Commit
+func (s *orderService) settleEdit(repos *datastore.Repositories, order *domain.Order, edit *domain.OrderEdit, oldFinalPrice, oldTaxAmount float64, reason string) ([]domain.OrderRefund, error) {
+	if order.PaidAt == nil {
+		return nil, nil
+	}
+
+	var refunds []domain.OrderRefund
+	delta := domain.RoundMoney(order.FinalPrice - oldFinalPrice)
+	switch {
+	case delta > 0:
+		order.BalanceDue = domain.RoundMoney(order.BalanceDue + delta)
+		edit.BalanceDue = delta
+	case delta < 0:
+		credit := -delta
+		fromBalance := math.Min(credit, order.BalanceDue)
+		order.BalanceDue = domain.RoundMoney(order.BalanceDue - fromBalance)
+		if refund := domain.RoundMoney(credit - fromBalance); refund > 0 {
+			taxAmount := domain.RoundMoney((oldTaxAmount - order.TaxAmount) * refund / credit)
+			var err error
+			refunds, err = s.payments.ReserveAdjustmentRefund(repos, order, refund, taxAmount, orderEditRefundReason(reason))
+			if err != nil {
+				return nil, fmt.Errorf("failed to reserve refund: %w", err)
+			}
+			edit.RefundAmount = refund
+		}
+	default:
+		return nil, nil
+	}
+	if err := repos.Order.Update(order); err != nil {
+		return nil, fmt.Errorf("failed to update order balance: %w", err)
+	}
+
+	adjustment, err := invoiceService.IssueInvoiceAdjustment(repos, order, oldFinalPrice, oldTaxAmount, reason)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment != nil {
+		edit.InvoiceAdjustmentNumber = adjustment.Number
+	}
+	return refunds, nil
+}
+
+func orderEditRefundReason(reason string) string {
+	if reason == "" {
+		return "order edited"
+	}
+	return "order edited: " + reason
+}
+
+// repriceOrder คำนวณ TotalPrice, Discount, ภาษี, ค่าจัดส่ง และ FinalPrice ของ Order ใหม่จากรายการสินค้าปัจจุบัน
+// ใช้ราคาที่บันทึกไว้ในแต่ละ OrderItem, คูปองเดิมของ Order และที่อยู่จัดส่งตาม Snapshot
+// ค่าจัดส่งคิดใหม่ด้วยวิธีจัดส่งเดิมที่ลูกค้าเลือก ถ้าวิธีนั้นใช้ไม่ได้แล้วจะคงค่าจัดส่งเดิมไว้
+func (s *orderService) repriceOrder(repos *datastore.Repositories, order *domain.Order) error {
+	var totalPrice, totalWeight float64
+	taxLines := make([]taxService.Line, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		// สินค้าที่ถูกลบไปแล้วจะใช้ Tax Class เริ่มต้น
+		var taxClassID *uint
+		product, err := repos.Product.FindByID(item.ProductID)
+		switch {
+		case err == nil:
+			taxClassID = product.TaxClassID
+			totalWeight += product.Weight * float64(item.Quantity)
+		case !errors.Is(err, productRepo.ErrNotFound):
+			return fmt.Errorf("could not get product %d: %w", item.ProductID, err)
+		}
+		taxLines = append(taxLines, taxService.Line{
+			ProductID:  item.ProductID,
+			TaxClassID: taxClassID,
+			UnitPrice:  item.Price,
+			Quantity:   item.Quantity,
+		})
+		totalPrice += item.Price * float64(item.Quantity)
+	}
+
+	// คูปองใช้สิทธิ์ไปแล้วตอน Checkout จึงคิดส่วนลดตามเงื่อนไขคูปองโดยไม่ตรวจวันหมดอายุหรือจำนวนสิทธิ์อีก
+	var discount float64
+	if order.AppliedCouponCode != nil {
+		coupon, err := repos.Coupon.FindByCode(*order.AppliedCouponCode)
+		switch {
+		case err == nil:
+			discount = coupon.CalculateDiscount(totalPrice)
+		case errors.Is(err, couponRepo.ErrNotFound):
+			discount = math.Min(order.Discount, totalPrice)
+		default:
+			return fmt.Errorf("could not get coupon: %w", err)
+		}
+	}
+
+	address := &domain.Address{
+		Country:    order.ShippingSnapshot.Country,
+		State:      order.ShippingSnapshot.State,
+		PostalCode: order.ShippingSnapshot.PostalCode,
+	}
+	tax, err := s.taxEngine.Calculate(repos.Tax, s.taxEngine.RegionFor(address), taxLines, discount)
+	if err != nil {
+		return fmt.Errorf("failed to calculate tax: %w", err)
+	}
+
+	if order.ShippingMethodID != nil {
+		rate, err := shippingService.RateForMethod(repos.Shipping, *order.ShippingMethodID, address, shippingService.Parcel{
+			Subtotal: totalPrice - tax.Discount,
+			WeightKg: totalWeight,
+		})
+		switch {
+		case err == nil:
+			order.ShippingCost = rate.Cost
+		case !errors.Is(err, shippingService.ErrShippingMethodUnavailable):
+			return fmt.Errorf("failed to calculate shipping: %w", err)
+		}
+	}
+
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		item.TaxRate = tax.Lines[i].Rate
+		item.TaxAmount = tax.Lines[i].Tax
+		if err := repos.Order.UpdateItem(item); err != nil {
+			return fmt.Errorf("failed to update order item: %w", err)
+		}
+	}
+
+	orderTaxLines := make([]domain.OrderTaxLine, 0, len(tax.Summaries))
+	for _, summary := range tax.Summaries {
+		orderTaxLines = append(orderTaxLines, domain.OrderTaxLine{
+			TaxClassCode:  summary.TaxClassCode,
+			Name:          summary.Name,
+			Rate:          summary.Rate,
+			TaxableAmount: summary.Taxable,
+			TaxAmount:     summary.Tax,
+		})
+	}
+	if err := repos.Order.ReplaceTaxLines(order.ID, orderTaxLines); err != nil {
+		return fmt.Errorf("failed to update order tax lines: %w", err)
+	}
+
+	order.TotalPrice = totalPrice
+	order.Discount = tax.Discount
+	order.PricesIncludeTax = tax.PricesIncludeTax
+	order.NetAmount = tax.Net
+	order.TaxAmount = tax.Tax
+	order.FinalPrice = tax.Total + order.AddOnCost()
+	order.TaxLines = orderTaxLines
+	if err := repos.Order.Update(order); err != nil {
+		return fmt.Errorf("failed to update order totals: %w", err)
+	}
+	return nil
+}
+
+// cancelOpenPayments ยกเลิก Payment ที่ยังรอ Capture ของ Order ที่ยอดเปลี่ยน
+// ลูกค้าต้องสร้างการชำระเงินใหม่ตามยอดใหม่ (Capture ตรวจยอดซ้ำอีกชั้นอยู่แล้ว)
+// คืนค่า Payment ที่ถูกยกเลิก เพื่อยกเลิก Intent ที่ Gateway หลัง Commit
+func cancelOpenPayments(repos *datastore.Repositories, order *domain.Order) ([]domain.Payment, error) {
+	payments, err := repos.Payment.FindByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	var cancelled []domain.Payment
+	for i := range payments {
+		payment := &payments[i]
+		if payment.Status != domain.PaymentStatusPending {
+			continue
+		}
+		payment.Status = domain.PaymentStatusFailed
+		payment.FailureReason = "order was edited, total changed"
+		if err := repos.Payment.Update(payment); err != nil {
+			return nil, fmt.Errorf("failed to cancel payment %d: %w", payment.ID, err)
+		}
+		cancelled = append(cancelled, *payment)
+	}
+	return cancelled, nil
+}
+
+func findOrderItem(order *domain.Order, itemID uint) (*domain.OrderItem, error) {
+	for i := range order.OrderItems {
+		if order.OrderItems[i].ID == itemID {
+			return &order.OrderItems[i], nil
+		}
+	}
+	return nil, ErrOrderItemNotFound
+}
+
+func findOrderableProduct(repos *datastore.Repositories, productID uint) (*domain.Product, error) {
+	product, err := repos.Product.FindByID(productID)
+	if err != nil {
+		if errors.Is(err, productRepo.ErrNotFound) {
+			return nil, fmt.Errorf("%w: product %d", ErrProductUnavailable, productID)
+		}
+		return nil, fmt.Errorf("could not get product %d: %w", productID, err)
+	}
+	return product, nil
+}
+
+// decreaseStock ตัดสต็อกแบบ Atomic เหมือนตอน Checkout และบันทึก inventory.stock_changed
+func decreaseStock(repos *datastore.Repositories, orderID, productID uint, name string, quantity uint) error {
+	if err := repos.Product.DecreaseStock(productID, quantity); err != nil {
+		if errors.Is(err, productRepo.ErrInsufficientStock) {
+			return fmt.Errorf("%w: %s", ErrProductOutOfStock, name)
+		}
+		return fmt.Errorf("failed to update stock for product %d: %w", productID, err)
+	}
+	return outboxService.RecordStockChanged(repos, productID, -int(quantity), outboxService.StockReasonOrderEdited, orderID)
+}
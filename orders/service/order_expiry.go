@@ -0,0 +1,82 @@
+package service
+
+import (
+	"backend/internal/datastore"
+	"backend/orders/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// expiryBatchSize คือจำนวน Order สูงสุดที่ยกเลิกต่อการทำงาน 1 รอบ
+const expiryBatchSize = 100
+
+// ExpirePendingOrders ยกเลิก Order ที่ค้างสถานะ pending นานเกิน ttl พร้อมคืนสต็อกและคูปอง
+// แต่ละ Order ทำใน Transaction ของตัวเอง และถูก Lock ด้วย SKIP LOCKED จึงรันพร้อมกันหลาย Instance ได้
+// Order ที่ยกเลิกไม่สำเร็จจะถูกบันทึก Log แล้วข้ามไปจนจบรอบ (รอบถัดไปจะลองใหม่) ไม่ทำให้ Order อื่นค้าง
+// คืนค่าจำนวน Order ที่ถูกยกเลิก และ Error เฉพาะเมื่อหา Order ที่หมดอายุไม่ได้เลย (เช่น Database ล่ม)
+func (s *orderService) ExpirePendingOrders(ttl time.Duration) (int, error) {
+	cutoff := time.Now().Add(-ttl)
+	reason := fmt.Sprintf("expired: unpaid for more than %s", ttl)
+
+	expired := 0
+	var failed []uint
+	for expired+len(failed) < expiryBatchSize {
+		var orderID uint
+		err := s.uow.Execute(func(repos *datastore.Repositories) error {
+			order, err := repos.Order.LockNextExpiredPending(cutoff, failed)
+			if err != nil {
+				return err
+			}
+			orderID = order.ID
+			return CancelAndRestock(repos, order, nil, reason)
+		})
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			break // ไม่มี Order ที่หมดอายุเหลือแล้ว (หรือ Instance อื่นกำลังจัดการอยู่)
+		}
+		if err != nil {
+			if orderID == 0 {
+				return expired, fmt.Errorf("failed to find expired orders: %w", err)
+			}
+			log.Printf("ERROR: order expiry: could not cancel order %d, skipping: %v", orderID, err)
+			failed = append(failed, orderID)
+			continue
+		}
+
+		log.Printf("Order expiry: cancelled order %d (%s)", orderID, reason)
+		expired++
+	}
+	return expired, nil
+}
+
+// StartOrderExpiryScheduler รัน ExpirePendingOrders ทุกๆ interval ใน Background จนกว่า ctx จะถูกยกเลิก
+func StartOrderExpiryScheduler(ctx context.Context, orderSvc OrderService, ttl, interval time.Duration) {
+	if ttl <= 0 || interval <= 0 {
+		log.Println("Order expiry scheduler is disabled.")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := orderSvc.ExpirePendingOrders(ttl)
+				if err != nil {
+					log.Printf("ERROR: order expiry run failed after %d orders: %v", count, err)
+					continue
+				}
+				if count > 0 {
+					log.Printf("Order expiry: cancelled %d unpaid order(s) older than %s", count, ttl)
+				}
+			}
+		}
+	}()
+	log.Printf("✅ Order expiry scheduler started (ttl: %s, every %s).", ttl, interval)
+}
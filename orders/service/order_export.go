@@ -0,0 +1,198 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/orders/dto"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// exportBatchSize คือจำนวน Order ที่ดึงจาก Database ต่อครั้งตอน Export
+const exportBatchSize = 500
+
+// ExportFormat คือรูปแบบไฟล์ที่รองรับการ Export
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportXLSX ExportFormat = "xlsx"
+)
+
+var ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+// exportHeader คือหัวคอลัมน์ของไฟล์ Export (1 แถวต่อ 1 OrderItem ข้อมูล Order จะซ้ำในทุกแถวของ Order นั้น)
+var exportHeader = []string{
+	"order_id", "order_number", "created_at", "status", "customer_email",
+	"payment_method", "tracking_number", "coupon_code",
+	"total_price", "discount", "shipping_method", "shipping_cost", "gift_wrap_price", "final_price",
+	"address_line_1", "address_line_2", "city", "state", "postal_code", "country",
+	"product_id", "sku", "product_name", "quantity", "unit_price", "line_total",
+}
+
+// exportNumericColumns คือ Index ของคอลัมน์ที่เป็นตัวเลข (ใช้ตอนเขียน XLSX)
+var exportNumericColumns = map[int]bool{0: true, 8: true, 9: true, 11: true, 12: true, 13: true, 20: true, 23: true, 24: true, 25: true}
+
+// ExportOrders เขียน Order และ OrderItem ที่ตรงกับ Filter ลง w ในรูปแบบ CSV หรือ XLSX
+// ข้อมูลถูกดึงจาก Database ทีละ Batch
+func (s *orderService) ExportOrders(params dto.AdminOrderQueryParams, format ExportFormat, w io.Writer) error {
+	switch format {
+	case ExportCSV:
+		return s.exportCSV(params, w)
+	case ExportXLSX:
+		return s.exportXLSX(params, w)
+	default:
+		return ErrUnsupportedExportFormat
+	}
+}
+
+func (s *orderService) exportCSV(params dto.AdminOrderQueryParams, w io.Writer) error {
+	// ใส่ BOM เพื่อให้ Excel เปิดไฟล์ภาษาไทยได้ถูกต้อง
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader); err != nil {
+		return err
+	}
+
+	err := s.uow.OrderRepository().FindInBatches(params, exportBatchSize, func(orders []domain.Order) error {
+		for i := range orders {
+			for _, row := range exportRows(&orders[i]) {
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+		// เขียนออกไปทีละ Batch ไม่ต้องรอจนครบทุกแถว
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (s *orderService) exportXLSX(params dto.AdminOrderQueryParams, w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Sheet1"
+	// StreamWriter จะพักแถวที่เกินขนาด Buffer ลงไฟล์ชั่วคราวแทนการเก็บใน Memory
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	rowNum := 1
+	writeRow := func(values []string) error {
+		cells := make([]interface{}, len(values))
+		for i, v := range values {
+			cells[i] = v
+			// คอลัมน์ตัวเลขเขียนเป็นตัวเลขจริงเพื่อให้ฝ่ายบัญชีนำไปคำนวณต่อได้
+			if rowNum > 1 && exportNumericColumns[i] {
+				if n, err := strconv.ParseFloat(v, 64); err == nil {
+					cells[i] = n
+				}
+			}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, rowNum)
+		if err != nil {
+			return err
+		}
+		rowNum++
+		return sw.SetRow(cell, cells)
+	}
+
+	if err := writeRow(exportHeader); err != nil {
+		return err
+	}
+
+	err = s.uow.OrderRepository().FindInBatches(params, exportBatchSize, func(orders []domain.Order) error {
+		for i := range orders {
+			for _, row := range exportRows(&orders[i]) {
+				if err := writeRow(row); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+// exportRows แปลง Order 1 รายการเป็นแถวข้อมูล (1 แถวต่อ 1 OrderItem)
+func exportRows(order *domain.Order) [][]string {
+	address := order.ShippingSnapshot
+	if address.IsEmpty() {
+		address = domain.NewAddressSnapshot(&order.ShippingAddress)
+	}
+
+	orderCols := []string{
+		strconv.FormatUint(uint64(order.ID), 10),
+		stringValue(order.OrderNumber),
+		order.CreatedAt.Format("2006-01-02 15:04:05"),
+		string(order.Status),
+		order.User.Email,
+		stringValue(order.PaymentMethod),
+		stringValue(order.TrackingNumber),
+		stringValue(order.AppliedCouponCode),
+		formatAmount(order.TotalPrice),
+		formatAmount(order.Discount),
+		order.ShippingMethodName,
+		formatAmount(order.ShippingCost),
+		formatAmount(order.GiftWrapPrice),
+		formatAmount(order.FinalPrice),
+		address.AddressLine1,
+		address.AddressLine2,
+		address.City,
+		address.State,
+		address.PostalCode,
+		address.Country,
+	}
+
+	rows := make([][]string, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		name, sku := item.ProductName, item.ProductSKU
+		if name == "" {
+			name, sku = item.Product.Name, item.Product.SKU
+		}
+
+		row := append(append([]string(nil), orderCols...),
+			strconv.FormatUint(uint64(item.ProductID), 10),
+			sku,
+			name,
+			strconv.FormatUint(uint64(item.Quantity), 10),
+			formatAmount(item.Price),
+			formatAmount(item.Price*float64(item.Quantity)),
+		)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
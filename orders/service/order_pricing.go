@@ -0,0 +1,299 @@
+package service
+
+import (
+	cartRepo "backend/carts/repository"
+	couponRepo "backend/coupons/repository"
+	"backend/domain"
+	"backend/internal/datastore"
+	"backend/orders/dto"
+	shippingService "backend/shipping/service"
+	taxService "backend/taxes/service"
+	userRepo "backend/users/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// pricedLine คือสินค้า 1 รายการในตะกร้าหลังผ่าน Pricing Pipeline
+type pricedLine struct {
+	item      domain.CartItem // item.Product คือข้อมูลล่าสุดที่อ่านใน Transaction เดียวกัน
+	available bool            // false = สินค้าถูกลบหรือสต็อกไม่พอ ไม่ถูกนับรวมในยอด
+	tax       *taxService.LineTax
+}
+
+// checkoutPricing คือผลลัพธ์ของ Pricing Pipeline ที่ใช้ร่วมกันระหว่าง Quote และ Checkout
+// Quote นำไปแสดงผล ส่วน Checkout จะสร้าง Order จากค่าชุดเดียวกันนี้ (ถ้าไม่มีปัญหาที่ Blocking)
+type checkoutPricing struct {
+	cart        *domain.Cart
+	address     *domain.Address
+	lines       []pricedLine // เรียงตาม ProductID
+	coupon      *domain.Coupon
+	totalPrice  float64
+	totalWeight float64
+	tax         *taxService.Result
+	shipping    *shippingService.Rate  // nil = วิธีจัดส่งที่เลือกใช้กับที่อยู่นี้ไม่ได้
+	giftWrap    *domain.GiftWrapOption // nil = ไม่ห่อของขวัญ
+	problems    []dto.CheckoutProblem
+}
+
+// finalPrice คือยอดที่ต้องชำระ (ยอดรวมภาษีแล้ว + ค่าจัดส่งและค่าห่อของขวัญที่ไม่มีภาษี)
+func (p *checkoutPricing) finalPrice() float64 {
+	total := p.tax.Total
+	if p.shipping != nil {
+		total += p.shipping.Cost
+	}
+	if p.giftWrap != nil {
+		total += p.giftWrap.Price
+	}
+	return total
+}
+
+func (p *checkoutPricing) addProblem(code dto.CheckoutProblemCode, productID *uint, blocking bool, format string, args ...interface{}) {
+	p.problems = append(p.problems, dto.CheckoutProblem{
+		Code:      code,
+		Message:   fmt.Sprintf(format, args...),
+		ProductID: productID,
+		Blocking:  blocking,
+	})
+}
+
+// blockingError คืน Error ของปัญหาแรกที่ทำให้สั่งซื้อไม่ได้ (nil = สั่งซื้อได้)
+func (p *checkoutPricing) blockingError() error {
+	for _, problem := range p.problems {
+		if !problem.Blocking {
+			continue
+		}
+		switch problem.Code {
+		case dto.ProblemProductUnavailable:
+			return fmt.Errorf("%w: %s", ErrProductUnavailable, problem.Message)
+		case dto.ProblemOutOfStock:
+			return fmt.Errorf("%w: %s", ErrProductOutOfStock, problem.Message)
+		case dto.ProblemShippingUnavailable:
+			return fmt.Errorf("%w: %s", shippingService.ErrShippingMethodUnavailable, problem.Message)
+		case dto.ProblemGiftWrapUnavailable:
+			return fmt.Errorf("%w: %s", shippingService.ErrGiftWrapUnavailable, problem.Message)
+		default:
+			return fmt.Errorf("%w: %s", ErrCouponNotValid, problem.Message)
+		}
+	}
+	return nil
+}
+
+// priceCheckout คือ Pricing Pipeline ของการสั่งซื้อ: ตรวจสต็อก, คูปอง, คำนวณภาษีและค่าจัดส่ง
+// ฟังก์ชันนี้อ่านข้อมูลอย่างเดียว ไม่ลดสต็อกหรือใช้สิทธิ์คูปอง (Checkout ทำเองหลังจากนี้)
+// ปัญหาที่แก้ได้ฝั่งลูกค้าจะถูกเก็บไว้ใน problems ส่วน Error ที่คืนมาคือกรณีที่คำนวณต่อไม่ได้เลย
+func (s *orderService) priceCheckout(repos *datastore.Repositories, userID uint, req dto.CreateOrderRequest) (*checkoutPricing, error) {
+	// 1. ดึงข้อมูลตะกร้าล่าสุดของผู้ใช้
+	cart, err := repos.Cart.GetCartByUserID(userID)
+	if err != nil {
+		if errors.Is(err, cartRepo.ErrNotFound) {
+			return nil, ErrCartIsEmpty
+		}
+		return nil, fmt.Errorf("could not get user cart: %w", err)
+	}
+	if len(cart.Items) == 0 {
+		return nil, ErrCartIsEmpty
+	}
+
+	// 2. ตรวจสอบที่อยู่จัดส่งว่าเป็นของผู้ใช้คนนี้
+	address, err := repos.Address.FindByID(req.ShippingAddressID)
+	if err != nil {
+		if errors.Is(err, userRepo.ErrNotFound) {
+			return nil, ErrInvalidShippingAddress
+		}
+		return nil, fmt.Errorf("could not get shipping address: %w", err)
+	}
+	if address.UserID != userID {
+		return nil, ErrInvalidShippingAddress
+	}
+
+	p := &checkoutPricing{cart: cart, address: address}
+
+	// 3. ตรวจสินค้าแต่ละรายการ เรียงตาม ProductID เพื่อให้ Checkout Lock แถวสินค้าในลำดับเดียวกันทุก Transaction (ป้องกัน Deadlock)
+	cartItems := append([]domain.CartItem(nil), cart.Items...)
+	sort.Slice(cartItems, func(i, j int) bool { return cartItems[i].ProductID < cartItems[j].ProductID })
+
+	taxLines := make([]taxService.Line, 0, len(cartItems))
+	for _, item := range cartItems {
+		line := pricedLine{item: item}
+		productID := item.ProductID
+		product := &item.Product
+
+		switch {
+		case product.ID == 0:
+			// Preload ไม่เจอสินค้า แปลว่าสินค้าถูกลบไปแล้ว
+			p.addProblem(dto.ProblemProductUnavailable, &productID, true, "product %d is no longer available", productID)
+		case product.Quantity < int(item.Quantity):
+			p.addProblem(dto.ProblemOutOfStock, &productID, true, "%s has only %d in stock", product.Name, max(product.Quantity, 0))
+		default:
+			line.available = true
+		}
+
+		if line.available {
+			if item.UnitPrice > 0 && math.Abs(item.UnitPrice-product.Price) >= 0.005 {
+				p.addProblem(dto.ProblemPriceChanged, &productID, false, "price of %s changed from %.2f to %.2f", product.Name, item.UnitPrice, product.Price)
+			}
+			taxLines = append(taxLines, taxService.Line{
+				ProductID:  product.ID,
+				TaxClassID: product.TaxClassID,
+				UnitPrice:  product.Price,
+				Quantity:   item.Quantity,
+			})
+			p.totalPrice += product.Price * float64(item.Quantity)
+			p.totalWeight += product.Weight * float64(item.Quantity)
+		}
+		p.lines = append(p.lines, line)
+	}
+
+	// 4. ตรวจสอบคูปองในตะกร้าและคำนวณส่วนลด (Checkout จะใช้สิทธิ์คูปองแบบ Atomic อีกครั้ง)
+	var discount float64
+	if cart.CouponID != nil {
+		coupon, err := repos.Coupon.FindByID(*cart.CouponID)
+		switch {
+		case errors.Is(err, couponRepo.ErrNotFound):
+			p.addProblem(dto.ProblemCouponInvalid, nil, true, "the coupon applied to the cart no longer exists")
+		case err != nil:
+			return nil, fmt.Errorf("could not get coupon: %w", err)
+		case !coupon.IsActive:
+			p.addProblem(dto.ProblemCouponInvalid, nil, true, "coupon %s is no longer active", coupon.Code)
+		case !time.Now().Before(coupon.ExpiryDate):
+			p.addProblem(dto.ProblemCouponExpired, nil, true, "coupon %s has expired", coupon.Code)
+		case coupon.UsageCount >= coupon.UsageLimit:
+			p.addProblem(dto.ProblemCouponUsageLimit, nil, true, "coupon %s has reached its usage limit", coupon.Code)
+		default:
+			p.coupon = coupon
+			discount = coupon.CalculateDiscount(p.totalPrice)
+		}
+	}
+
+	// 5. คำนวณภาษีตามที่อยู่จัดส่ง (Engine เดียวกับที่ตะกร้าใช้)
+	tax, err := s.taxEngine.Calculate(repos.Tax, s.taxEngine.RegionFor(address), taxLines, discount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate tax: %w", err)
+	}
+	p.tax = tax
+	next := 0
+	for i := range p.lines {
+		if p.lines[i].available {
+			p.lines[i].tax = &tax.Lines[next]
+			next++
+		}
+	}
+
+	// 6. คิดค่าจัดส่งตามวิธีที่เลือก
+	rate, err := shippingService.RateForMethod(repos.Shipping, req.ShippingMethodID, address, shippingService.Parcel{
+		Subtotal: p.totalPrice - tax.Discount,
+		WeightKg: p.totalWeight,
+	})
+	switch {
+	case errors.Is(err, shippingService.ErrShippingMethodUnavailable):
+		p.addProblem(dto.ProblemShippingUnavailable, nil, true, "shipping method %d is not available for this address", req.ShippingMethodID)
+	case err != nil:
+		return nil, fmt.Errorf("failed to calculate shipping: %w", err)
+	default:
+		p.shipping = rate
+	}
+
+	// 7. บริการห่อของขวัญ (ถ้าเลือก)
+	if req.Gift != nil && req.Gift.GiftWrapID != nil {
+		giftWrap, err := shippingService.GiftWrapForCheckout(repos.Shipping, *req.Gift.GiftWrapID)
+		switch {
+		case errors.Is(err, shippingService.ErrGiftWrapUnavailable):
+			p.addProblem(dto.ProblemGiftWrapUnavailable, nil, true, "gift wrap option %d is no longer available", *req.Gift.GiftWrapID)
+		case err != nil:
+			return nil, fmt.Errorf("failed to get gift wrap option: %w", err)
+		default:
+			p.giftWrap = giftWrap
+		}
+	}
+
+	return p, nil
+}
+
+// QuoteOrder คำนวณยอดของตะกร้าด้วย Pipeline เดียวกับ CreateOrderFromCart โดยไม่บันทึกอะไร
+func (s *orderService) QuoteOrder(userID uint, req dto.CreateOrderRequest) (*dto.OrderQuoteResponse, error) {
+	var pricing *checkoutPricing
+	// ใช้ Transaction เพื่อให้อ่านข้อมูลทุกตารางจากจังหวะเดียวกัน (Pipeline ไม่มีการเขียน)
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		pricing, err = s.priceCheckout(repos, userID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.mapPricingToQuoteResponse(pricing), nil
+}
+
+func (s *orderService) mapPricingToQuoteResponse(p *checkoutPricing) *dto.OrderQuoteResponse {
+	items := make([]dto.QuoteItemResponse, 0, len(p.lines))
+	for _, line := range p.lines {
+		product := line.item.Product
+		item := dto.QuoteItemResponse{
+			ProductID:      line.item.ProductID,
+			Name:           product.Name,
+			Sku:            product.SKU,
+			Quantity:       line.item.Quantity,
+			AvailableStock: max(product.Quantity, 0),
+			Price:          product.Price,
+			PriceInCart:    line.item.UnitPrice,
+			Available:      line.available,
+		}
+		if imagePath := product.PrimaryImagePath(); imagePath != "" {
+			item.ImageURL = s.imageBaseURL + "/" + imagePath
+		}
+		if line.available {
+			item.LineTotal = product.Price * float64(line.item.Quantity)
+			item.TaxRate = line.tax.Rate
+			item.TaxAmount = line.tax.Tax
+		}
+		items = append(items, item)
+	}
+
+	res := &dto.OrderQuoteResponse{
+		Items:            items,
+		TotalPrice:       p.totalPrice,
+		Discount:         p.tax.Discount,
+		PricesIncludeTax: p.tax.PricesIncludeTax,
+		NetAmount:        p.tax.Net,
+		TaxAmount:        p.tax.Tax,
+		TaxLines:         mapTaxSummariesToResponse(p.tax.Summaries),
+		FinalPrice:       p.finalPrice(),
+		CanPlaceOrder:    p.blockingError() == nil,
+		Problems:         p.problems,
+	}
+	if res.Problems == nil {
+		res.Problems = []dto.CheckoutProblem{}
+	}
+	if p.coupon != nil {
+		res.AppliedCouponCode = &p.coupon.Code
+	}
+	if p.shipping != nil {
+		res.ShippingMethodID = &p.shipping.Method.ID
+		res.ShippingMethod = p.shipping.Method.Name
+		res.ShippingCarrier = p.shipping.Method.Carrier
+		res.ShippingCost = p.shipping.Cost
+	}
+	if p.giftWrap != nil {
+		res.GiftWrapID = &p.giftWrap.ID
+		res.GiftWrapName = p.giftWrap.Name
+		res.GiftWrapPrice = p.giftWrap.Price
+	}
+	return res
+}
+
+func mapTaxSummariesToResponse(summaries []taxService.RateSummary) []dto.OrderTaxLineResponse {
+	lines := make([]dto.OrderTaxLineResponse, 0, len(summaries))
+	for _, summary := range summaries {
+		lines = append(lines, dto.OrderTaxLineResponse{
+			TaxClassCode:  summary.TaxClassCode,
+			Name:          summary.Name,
+			Rate:          summary.Rate,
+			TaxableAmount: summary.Taxable,
+			TaxAmount:     summary.Tax,
+		})
+	}
+	return lines
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"backend/internal/datastore"
+	"backend/orders/dto"
+	productRepo "backend/products/repository"
+	"errors"
+	"fmt"
+)
+
+// Reorder คัดลอกสินค้าจาก Order เดิมลงตะกร้าของผู้ใช้ในราคาปัจจุบัน
+// สินค้าที่ถูกลบหรือหมดสต็อกจะถูกข้าม ส่วนที่สต็อกไม่พอจะเพิ่มเท่าที่มี (นับรวมจำนวนที่อยู่ในตะกร้าแล้ว)
+func (s *orderService) Reorder(userID, orderID uint) (*dto.ReorderResponse, error) {
+	order, err := s.uow.OrderRepository().FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderAccessDenied
+	}
+
+	results := make([]dto.ReorderItemResult, 0, len(order.OrderItems))
+	err = s.uow.Execute(func(repos *datastore.Repositories) error {
+		if _, err := repos.Cart.GetOrCreateCart(userID); err != nil {
+			return err
+		}
+		cart, err := repos.Cart.GetCartByUserID(userID)
+		if err != nil {
+			return err
+		}
+		inCart := make(map[uint]uint, len(cart.Items))
+		for _, item := range cart.Items {
+			inCart[item.ProductID] = item.Quantity
+		}
+
+		for _, item := range order.OrderItems {
+			result := dto.ReorderItemResult{
+				ProductID:         item.ProductID,
+				Name:              item.ProductName,
+				RequestedQuantity: item.Quantity,
+			}
+			if result.Name == "" {
+				result.Name = item.Product.Name
+			}
+
+			product, err := repos.Product.FindByID(item.ProductID)
+			if errors.Is(err, productRepo.ErrNotFound) {
+				result.Status = dto.ReorderSkipped
+				result.Reason = "product is no longer available"
+				results = append(results, result)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not get product %d: %w", item.ProductID, err)
+			}
+			result.Name = product.Name
+
+			// สต็อกที่ยังเพิ่มได้ = สต็อกปัจจุบัน - จำนวนที่อยู่ในตะกร้าแล้ว
+			available := max(product.Quantity-int(inCart[product.ID]), 0)
+			quantity := min(item.Quantity, uint(available))
+			switch {
+			case quantity == 0:
+				result.Status = dto.ReorderSkipped
+				result.Reason = "out of stock"
+				results = append(results, result)
+				continue
+			case quantity < item.Quantity:
+				result.Status = dto.ReorderAdjusted
+				result.Reason = fmt.Sprintf("only %d available", quantity)
+			default:
+				result.Status = dto.ReorderAdded
+			}
+
+			if _, err := repos.Cart.AddItem(cart.ID, product.ID, quantity, product.Price); err != nil {
+				return fmt.Errorf("failed to add product %d to cart: %w", product.ID, err)
+			}
+			inCart[product.ID] += quantity
+			result.AddedQuantity = quantity
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.cartSvc.GetCart(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReorderResponse{OrderID: order.ID, Items: results, Cart: cart}, nil
+}
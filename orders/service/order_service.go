@@ -1,13 +1,24 @@
 package service
 
 import (
+	cartService "backend/carts/service"
+	couponRepo "backend/coupons/repository"
 	"backend/domain"
 	"backend/internal/datastore"
+	invoiceService "backend/invoices/service"
 	"backend/orders/dto"
 	"backend/orders/repository"
+	outboxService "backend/outbox/service"
+	productRepo "backend/products/repository"
+	taxService "backend/taxes/service"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+	"time"
 )
 
 var (
@@ -15,89 +26,220 @@ var (
 	ErrProductOutOfStock  = errors.New("a product in the cart is out of stock")
 	ErrOrderAccessDenied  = errors.New("you do not have permission to view this order")
 	ErrInvalidOrderStatus = errors.New("order status is not valid for this operation")
+	ErrInvalidOrderNumber = errors.New("order number is not valid, please check it again")
+	ErrCouponNotValid     = errors.New("the coupon applied to the cart is no longer valid")
+
+	ErrInvalidShippingAddress = errors.New("shipping address not found")
+	ErrProductUnavailable     = errors.New("a product in the cart is no longer available")
 )
 
+// customerCancellableStatuses คือสถานะที่ลูกค้ายกเลิก Order เองได้ (processing ต้องยังไม่มีพัสดุ)
+// Order ที่ชำระแล้วจะถูกคืนเงินอัตโนมัติ (Admin ยกเลิกได้ทุกสถานะที่ตาราง Transition อนุญาต)
+var customerCancellableStatuses = map[domain.OrderStatus]bool{
+	domain.StatusPending:    true,
+	domain.StatusProcessing: true,
+}
+
 type OrderService interface {
 	CreateOrderFromCart(ctx context.Context, userID uint, req dto.CreateOrderRequest) (*dto.OrderResponse, error)
+	QuoteOrder(userID uint, req dto.CreateOrderRequest) (*dto.OrderQuoteResponse, error)
 	GetMyOrders(userID uint) ([]dto.OrderResponse, error)
 	GetOrderByID(userID, orderID uint) (*dto.OrderResponse, error)
-	ConfirmPayment(orderID uint) error
-	UpdateOrderStatus(orderID uint, status domain.OrderStatus, paymentMethod string) error
-	ShipOrder(orderID uint, trackingNumber string) error
+	GetOrderByNumber(userID uint, isAdmin bool, number string) (*dto.OrderResponse, error)
+	FindAllOrders(params dto.AdminOrderQueryParams) (*dto.PaginatedOrdersDTO, error)
+	ExportOrders(params dto.AdminOrderQueryParams, format ExportFormat, w io.Writer) error
+	ConfirmPayment(adminID, orderID uint) error
+	ProcessPaymentEvent(ctx context.Context, req dto.PaymentWebhookRequest, payload []byte) (domain.PaymentEventResult, error)
+	ShipOrder(adminID, orderID uint, trackingNumber string) error
+	CreateShipment(adminID, orderID uint, req dto.CreateShipmentRequest) (*dto.ShipmentResponse, error)
+	GetShipments(userID uint, isAdmin bool, orderID uint) ([]dto.ShipmentResponse, error)
+	ProcessTrackingEvent(req dto.TrackingWebhookRequest) (TrackingEventResult, error)
+	GetOrderHistory(orderID uint) ([]dto.OrderStatusHistoryResponse, error)
+	AddOrderItem(ctx context.Context, adminID, orderID uint, req dto.AddOrderItemRequest) (*dto.OrderResponse, error)
+	UpdateOrderItem(ctx context.Context, adminID, orderID, itemID uint, req dto.UpdateOrderItemRequest) (*dto.OrderResponse, error)
+	RemoveOrderItem(ctx context.Context, adminID, orderID, itemID uint, req dto.RemoveOrderItemRequest) (*dto.OrderResponse, error)
+	GetOrderEdits(orderID uint) ([]dto.OrderEditResponse, error)
+	CancelOrder(ctx context.Context, userID, orderID uint, reason string) (*dto.OrderResponse, error)
+	AdminCancelOrder(adminID, orderID uint, reason string) (*dto.OrderResponse, error)
+	Reorder(userID, orderID uint) (*dto.ReorderResponse, error)
+	ExpirePendingOrders(ttl time.Duration) (int, error)
+}
+
+// PaymentSettler คืนเงินและยกเลิกการชำระเงินของ Order ทำงานโดย payments/service.PaymentService
+// ฉีดเข้ามาผ่าน Interface เพราะ payments/service import แพ็กเกจนี้อยู่แล้ว
+type PaymentSettler interface {
+	// ReserveOrderRefund จองยอดคืนเงินของ Order (นับเข้า Order.RefundedAmount) ต้องเรียกภายใน uow.Execute
+	ReserveOrderRefund(repos *datastore.Repositories, order *domain.Order, amount float64, reason string) ([]domain.OrderRefund, error)
+	// ReserveAdjustmentRefund จองยอดคืนส่วนต่างหลังแก้ไข Order ที่ยอดลดลง ต้องเรียกภายใน uow.Execute
+	ReserveAdjustmentRefund(repos *datastore.Repositories, order *domain.Order, amount, taxAmount float64, reason string) ([]domain.OrderRefund, error)
+	// SettleRefunds คืนเงินที่จองไว้ผ่าน Gateway หลัง Commit แล้วบันทึกลง OrderRefund
+	SettleRefunds(ctx context.Context, adminID uint, order *domain.Order, returnRequestID *uint, refunds []domain.OrderRefund) ([]domain.OrderRefund, error)
+	// CancelIntents ยกเลิก Intent ที่ Gateway ของ Payment ที่ถูกบันทึกเป็น failed แล้ว เรียกหลัง Commit
+	CancelIntents(ctx context.Context, payments []domain.Payment)
 }
 
 type orderService struct {
-	uow datastore.UnitOfWork
+	uow            datastore.UnitOfWork
+	imageBaseURL   string
+	taxEngine      *taxService.Engine
+	cartSvc        cartService.CartService // ใช้สร้าง CartResponse หลังสั่งซื้อซ้ำ
+	payments       PaymentSettler          // คืนเงินหรือยกเลิกการชำระเงินเมื่อยกเลิกหรือแก้ไข Order
+	orderNumberKey []byte                  // Key ลับสำหรับสลับลำดับในเลขที่ Order (ดู domain.NewOrderNumber)
 }
 
-func NewOrderService(uow datastore.UnitOfWork) OrderService {
-	return &orderService{uow: uow}
+func NewOrderService(uow datastore.UnitOfWork, imageBaseURL string, taxEngine *taxService.Engine, cartSvc cartService.CartService, payments PaymentSettler, orderNumberSecret string) OrderService {
+	return &orderService{
+		uow:            uow,
+		imageBaseURL:   imageBaseURL,
+		taxEngine:      taxEngine,
+		cartSvc:        cartSvc,
+		payments:       payments,
+		orderNumberKey: []byte(orderNumberSecret),
+	}
 }
 
 func (s *orderService) CreateOrderFromCart(ctx context.Context, userID uint, req dto.CreateOrderRequest) (*dto.OrderResponse, error) {
 	var createdOrder *domain.Order
 
-	// ทำทุกอย่างใน Transaction เดียวผ่าน Unit of Work
-	err := s.uow.Execute(func(repos *datastore.Repositories) error {
-		// 1. ดึงข้อมูลตะกร้าล่าสุดของผู้ใช้
-		cart, err := repos.Cart.GetCartByUserID(userID)
+	// ทำทุกอย่างใน Transaction เดียวผ่าน Unit of Work (รวมถึง Idempotency Key ถ้ามีแนบมากับ ctx)
+	err := s.uow.ExecuteWithContext(ctx, func(repos *datastore.Repositories) error {
+		// 1. คำนวณยอดด้วย Pipeline เดียวกับ Quote แล้วปฏิเสธถ้ามีปัญหาที่ทำให้สั่งซื้อไม่ได้
+		pricing, err := s.priceCheckout(repos, userID, req)
 		if err != nil {
-			if errors.Is(err, repository.ErrNotFound) {
-				return ErrCartIsEmpty
-			}
-			return fmt.Errorf("could not get user cart: %w", err)
+			return err
 		}
-		if len(cart.Items) == 0 {
-			return ErrCartIsEmpty
+		if err := pricing.blockingError(); err != nil {
+			return err
 		}
 
-		// 2. เตรียมข้อมูล Order และคำนวณราคารวม
-		orderItems := make([]domain.OrderItem, 0)
-		var totalPrice float64
-
-		for _, cartItem := range cart.Items {
-			product, err := repos.Product.FindProductByID(cartItem.ProductID)
-			if err != nil {
-				return fmt.Errorf("product with id %d not found: %w", cartItem.ProductID, err)
+		// 2. ลดสต็อกแบบ Atomic (ค่าที่ Pipeline อ่านมาอาจเก่าไปแล้วถ้ามี Checkout อื่นพร้อมกัน)
+		orderItems := make([]domain.OrderItem, 0, len(pricing.lines))
+		for _, line := range pricing.lines {
+			product := &line.item.Product
+			if err := repos.Product.DecreaseStock(product.ID, line.item.Quantity); err != nil {
+				if errors.Is(err, productRepo.ErrInsufficientStock) {
+					return fmt.Errorf("%w: %s", ErrProductOutOfStock, product.Name)
+				}
+				return fmt.Errorf("failed to update stock for product %d: %w", product.ID, err)
 			}
 
-			if product.Quantity < int(cartItem.Quantity) {
-				return fmt.Errorf("%w: %s has only %d in stock", ErrProductOutOfStock, product.Name, product.Quantity)
-			}
+			orderItems = append(orderItems, domain.OrderItem{
+				ProductID:        product.ID,
+				Quantity:         line.item.Quantity,
+				Price:            product.Price,
+				TaxRate:          line.tax.Rate,
+				TaxAmount:        line.tax.Tax,
+				ProductName:      product.Name,
+				ProductSKU:       product.SKU,
+				ProductImagePath: product.PrimaryImagePath(),
+			})
+		}
 
-			// [แก้ไข] ลดสต็อกสินค้า
-			newQuantity := product.Quantity - int(cartItem.Quantity)
-			// [แก้ไข] เรียกใช้เมธอด Update ที่ถูกต้อง
-			if err := repos.Product.Update(product.ID, map[string]interface{}{"quantity": newQuantity}); err != nil {
-				return fmt.Errorf("failed to update stock for product %d: %w", product.ID, err)
+		// 3. ใช้สิทธิ์คูปอง ConsumeUsage ตรวจ active/expiry/usage limit ซ้ำและเพิ่ม usage_count แบบ Atomic
+		var appliedCouponCode *string
+		if pricing.coupon != nil {
+			if err := repos.Coupon.ConsumeUsage(pricing.coupon.ID); err != nil {
+				if errors.Is(err, couponRepo.ErrCouponUnavailable) {
+					return fmt.Errorf("%w: %s", ErrCouponNotValid, pricing.coupon.Code)
+				}
+				return fmt.Errorf("failed to redeem coupon %s: %w", pricing.coupon.Code, err)
 			}
+			appliedCouponCode = &pricing.coupon.Code
+		}
 
-			orderItems = append(orderItems, domain.OrderItem{
-				ProductID: product.ID,
-				Quantity:  cartItem.Quantity,
-				Price:     product.Price,
+		orderTaxLines := make([]domain.OrderTaxLine, 0, len(pricing.tax.Summaries))
+		for _, summary := range pricing.tax.Summaries {
+			orderTaxLines = append(orderTaxLines, domain.OrderTaxLine{
+				TaxClassCode:  summary.TaxClassCode,
+				Name:          summary.Name,
+				Rate:          summary.Rate,
+				TaxableAmount: summary.Taxable,
+				TaxAmount:     summary.Tax,
 			})
-			totalPrice += product.Price * float64(cartItem.Quantity)
 		}
 
-		// 3. สร้าง Order หลัก
+		// 4. จองเลขที่ Order ผ่าน Repository นอก Transaction (ดู NextOrderNumberSequence)
+		orderNumber, err := s.nextOrderNumber()
+		if err != nil {
+			return err
+		}
+
+		var paymentMethod *string
+		if req.PaymentMethod == domain.PaymentMethodCOD {
+			cod := domain.PaymentMethodCOD
+			paymentMethod = &cod
+		}
+
+		// 5. สร้าง Order หลัก พร้อมสำเนาที่อยู่จัดส่ง
 		order := &domain.Order{
+			OrderNumber:       &orderNumber,
 			UserID:            userID,
 			OrderItems:        orderItems,
-			TotalPrice:        totalPrice,
-			ShippingAddressID: req.ShippingAddressID,
+			TotalPrice:        pricing.totalPrice,
+			Discount:          pricing.tax.Discount,
+			FinalPrice:        pricing.finalPrice(),
+			AppliedCouponCode: appliedCouponCode,
+			ShippingAddressID: pricing.address.ID,
+			ShippingSnapshot:  domain.NewAddressSnapshot(pricing.address),
 			Status:            domain.StatusPending,
+			PaymentMethod:     paymentMethod,
+			PricesIncludeTax:  pricing.tax.PricesIncludeTax,
+			NetAmount:         pricing.tax.Net,
+			TaxAmount:         pricing.tax.Tax,
+			TaxLines:          orderTaxLines,
+
+			ShippingMethodID:   &pricing.shipping.Method.ID,
+			ShippingMethodName: pricing.shipping.Method.Name,
+			ShippingCarrier:    pricing.shipping.Method.Carrier,
+			ShippingCost:       pricing.shipping.Cost,
+		}
+		if req.Gift != nil {
+			order.GiftMessage = strings.TrimSpace(req.Gift.Message)
+			order.GiftHidePrices = req.Gift.HidePrices
+		}
+		if pricing.giftWrap != nil {
+			order.GiftWrapOptionID = &pricing.giftWrap.ID
+			order.GiftWrapName = pricing.giftWrap.Name
+			order.GiftWrapPrice = pricing.giftWrap.Price
 		}
 
 		if err := repos.Order.Create(order); err != nil {
 			return fmt.Errorf("failed to create order: %w", err)
 		}
+		if err := repos.Order.CreateStatusHistory(&domain.OrderStatusHistory{
+			OrderID:   order.ID,
+			ToStatus:  domain.StatusPending,
+			ChangedBy: &userID,
+			Reason:    "order created",
+		}); err != nil {
+			return fmt.Errorf("failed to record order status: %w", err)
+		}
+		if err := outboxService.RecordOrderEvent(repos, domain.EventOrderCreated, order, "", ""); err != nil {
+			return err
+		}
+		for _, item := range order.OrderItems {
+			if err := outboxService.RecordStockChanged(repos, item.ProductID, -int(item.Quantity), outboxService.StockReasonOrderPlaced, order.ID); err != nil {
+				return err
+			}
+		}
+		// เก็บเงินปลายทางไม่ต้องรอชำระออนไลน์ เตรียมจัดส่งได้ทันที
+		if order.IsCOD() {
+			if err := TransitionOrderStatus(repos, order, domain.StatusProcessing, &userID, "cash on delivery"); err != nil {
+				return err
+			}
+		}
 		createdOrder = order
 
-		// 4. ล้างตะกร้าสินค้า
+		// 6. ล้างตะกร้าสินค้าและถอดคูปองที่ใช้ไปแล้วออก
+		cart := pricing.cart
 		if err := repos.Cart.ClearCart(cart.ID); err != nil {
 			return fmt.Errorf("failed to clear cart: %w", err)
 		}
+		if cart.CouponID != nil {
+			if err := repos.Cart.RemoveCoupon(cart.ID); err != nil {
+				return fmt.Errorf("failed to remove coupon from cart: %w", err)
+			}
+		}
 		return nil // Commit Transaction
 	})
 
@@ -105,8 +247,8 @@ func (s *orderService) CreateOrderFromCart(ctx context.Context, userID uint, req
 		return nil, err
 	}
 
-	// 5. แปลงข้อมูลเป็น DTO เพื่อส่งกลับ
-	return mapOrderToOrderResponse(createdOrder), nil
+	// 7. แปลงข้อมูลเป็น DTO เพื่อส่งกลับ
+	return s.mapOrderToOrderResponse(createdOrder), nil
 }
 
 func (s *orderService) GetMyOrders(userID uint) ([]dto.OrderResponse, error) {
@@ -118,7 +260,7 @@ func (s *orderService) GetMyOrders(userID uint) ([]dto.OrderResponse, error) {
 
 	responses := make([]dto.OrderResponse, 0, len(orders))
 	for _, order := range orders {
-		responses = append(responses, *mapOrderToOrderResponse(&order))
+		responses = append(responses, *s.mapOrderToOrderResponse(&order))
 	}
 	return responses, nil
 }
@@ -134,88 +276,463 @@ func (s *orderService) GetOrderByID(userID, orderID uint) (*dto.OrderResponse, e
 	if order.UserID != userID {
 		return nil, ErrOrderAccessDenied
 	}
-	return mapOrderToOrderResponse(order), nil
+	return s.mapOrderToOrderResponse(order), nil
+}
+
+// GetOrderByNumber ค้นหา Order ตามเลขที่ Order ที่ลูกค้าหรือ Support พิมพ์มา (ลูกค้าดูได้เฉพาะ Order ของตัวเอง)
+func (s *orderService) GetOrderByNumber(userID uint, isAdmin bool, number string) (*dto.OrderResponse, error) {
+	normalized, ok := domain.NormalizeOrderNumber(number)
+	if !ok {
+		return nil, ErrInvalidOrderNumber
+	}
+	order, err := s.uow.OrderRepository().FindByOrderNumber(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && order.UserID != userID {
+		return nil, ErrOrderAccessDenied
+	}
+	return s.mapOrderToOrderResponse(order), nil
 }
 
-// Helper function สำหรับแปลงข้อมูล
-func mapOrderToOrderResponse(order *domain.Order) *dto.OrderResponse {
+// nextOrderNumber สร้างเลขที่ Order ถัดไปของวันนี้
+func (s *orderService) nextOrderNumber() (string, error) {
+	date := domain.OrderNumberDate(time.Now())
+	seq, err := s.uow.OrderRepository().NextOrderNumberSequence(date)
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate order number: %w", err)
+	}
+	return domain.NewOrderNumber(s.orderNumberKey, date, seq), nil
+}
+
+// FindAllOrders ดึงรายการ Order ทั้งหมดสำหรับ Admin พร้อม Filter และการแบ่งหน้า
+func (s *orderService) FindAllOrders(params dto.AdminOrderQueryParams) (*dto.PaginatedOrdersDTO, error) {
+	// 1. ดึงจำนวน Order ที่ตรงกับ Filter
+	totalItems, err := s.uow.OrderRepository().Count(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. ดึงข้อมูล Order ในหน้าที่ต้องการ
+	orders, err := s.uow.OrderRepository().FindAll(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. แปลง Domain Model เป็น DTO
+	items := make([]dto.AdminOrderListItem, 0, len(orders))
+	for _, order := range orders {
+		items = append(items, dto.AdminOrderListItem{
+			ID:                order.ID,
+			OrderNumber:       order.OrderNumber,
+			UserID:            order.UserID,
+			CustomerEmail:     order.User.Email,
+			CustomerName:      strings.TrimSpace(order.User.FirstName + " " + order.User.LastName),
+			Status:            order.Status,
+			ItemCount:         len(order.OrderItems),
+			TotalPrice:        order.TotalPrice,
+			Discount:          order.Discount,
+			FinalPrice:        order.FinalPrice,
+			AppliedCouponCode: order.AppliedCouponCode,
+			PaymentMethod:     order.PaymentMethod,
+			TrackingNumber:    order.TrackingNumber,
+			CreatedAt:         order.CreatedAt,
+		})
+	}
+
+	// 4. คำนวณค่า Pagination และสร้าง Response
+	return &dto.PaginatedOrdersDTO{
+		Data:        items,
+		TotalItems:  totalItems,
+		TotalPages:  int(math.Ceil(float64(totalItems) / float64(params.Limit))),
+		CurrentPage: params.Page,
+		Limit:       params.Limit,
+	}, nil
+}
+
+// mapOrderToOrderResponse อ่านชื่อสินค้าและที่อยู่จาก Snapshot ที่บันทึกตอน Checkout
+// Order เก่าที่ยังไม่มี Snapshot จะใช้ข้อมูลปัจจุบันของ Product และ Address แทน
+func (s *orderService) mapOrderToOrderResponse(order *domain.Order) *dto.OrderResponse {
 	items := make([]dto.OrderItemResponse, 0, len(order.OrderItems))
 	for _, item := range order.OrderItems {
+		name, sku, imagePath := item.ProductName, item.ProductSKU, item.ProductImagePath
+		if name == "" {
+			name, sku, imagePath = item.Product.Name, item.Product.SKU, item.Product.PrimaryImagePath()
+		}
+
+		var imageURL string
+		if imagePath != "" {
+			imageURL = s.imageBaseURL + "/" + imagePath
+		}
+
 		items = append(items, dto.OrderItemResponse{
 			ProductID: item.ProductID,
-			Name:      item.Product.Name,
-			Sku:       item.Product.SKU,
+			Name:      name,
+			Sku:       sku,
+			ImageURL:  imageURL,
 			Price:     item.Price,
 			Quantity:  item.Quantity,
+			TaxRate:   item.TaxRate,
+			TaxAmount: item.TaxAmount,
 		})
 	}
+
+	taxLines := make([]dto.OrderTaxLineResponse, 0, len(order.TaxLines))
+	for _, line := range order.TaxLines {
+		taxLines = append(taxLines, dto.OrderTaxLineResponse{
+			TaxClassCode:  line.TaxClassCode,
+			Name:          line.Name,
+			Rate:          line.Rate,
+			TaxableAmount: line.TaxableAmount,
+			TaxAmount:     line.TaxAmount,
+		})
+	}
+
+	shippingAddress := mapAddressToResponse(&order.ShippingAddress)
+	if !order.ShippingSnapshot.IsEmpty() {
+		shippingAddress = mapSnapshotToAddressResponse(order.ShippingAddressID, order.ShippingSnapshot)
+	}
+
 	return &dto.OrderResponse{
 		ID:                order.ID,
+		OrderNumber:       order.OrderNumber,
 		UserID:            order.UserID,
 		TotalPrice:        order.TotalPrice,
+		Discount:          order.Discount,
+		FinalPrice:        order.FinalPrice,
+		AppliedCouponCode: order.AppliedCouponCode,
 		Status:            order.Status,
 		ShippingAddressID: order.ShippingAddressID,
 		CreatedAt:         order.CreatedAt,
-		ShippingAddress:   mapAddressToResponse(&order.ShippingAddress),
+		ShippingAddress:   shippingAddress,
 		PaymentMethod:     order.PaymentMethod,
+		PaidAt:            order.PaidAt,
 		Items:             items,
+		PricesIncludeTax:  order.PricesIncludeTax,
+		NetAmount:         order.NetAmount,
+		TaxAmount:         order.TaxAmount,
+		TaxLines:          taxLines,
+
+		ShippingMethodID: order.ShippingMethodID,
+		ShippingMethod:   order.ShippingMethodName,
+		ShippingCarrier:  order.ShippingCarrier,
+		ShippingCost:     order.ShippingCost,
+
+		IsGift:         order.IsGift(),
+		GiftWrapID:     order.GiftWrapOptionID,
+		GiftWrapName:   order.GiftWrapName,
+		GiftWrapPrice:  order.GiftWrapPrice,
+		GiftMessage:    order.GiftMessage,
+		GiftHidePrices: order.GiftHidePrices,
+
+		RefundedAmount: order.RefundedAmount,
+		BalanceDue:     order.BalanceDue,
 	}
 }
 
-func (s *orderService) ConfirmPayment(orderID uint) error {
+func (s *orderService) ConfirmPayment(adminID, orderID uint) error {
 	return s.uow.Execute(func(repos *datastore.Repositories) error {
-		// 1. ค้นหา Order ที่ต้องการ
-		order, err := repos.Order.FindByID(orderID)
+		// 1. ค้นหา Order ที่ต้องการและ Lock ไว้จนจบ Transaction
+		order, err := repos.Order.LockByID(orderID)
 		if err != nil {
 			return err // จะคืนค่า ErrOrderNotFound ถ้าไม่มี
 		}
 
-		if order.Status != domain.StatusShipped {
-			return fmt.Errorf("%w: current status is '%s'", ErrInvalidOrderStatus, order.Status)
+		// 2. เปลี่ยนสถานะผ่าน State Machine (shipped -> completed เท่านั้น)
+		return TransitionOrderStatus(repos, order, domain.StatusCompleted, &adminID, "delivery confirmed by admin")
+	})
+}
+
+// ProcessPaymentEvent ประมวลผล Webhook จาก Payment Gateway (ผ่านการตรวจ Signature มาแล้ว)
+// Event ทุกตัวถูกบันทึกลง PaymentEvent และ Event ID ที่เคยได้รับแล้วจะถูกข้าม
+// การเปลี่ยนสถานะทำผ่าน State Machine ถ้า Transition ไม่ถูกต้อง Event จะถูกบันทึกเป็น ignored
+// (ไม่คืน Error เพื่อไม่ให้ Gateway ส่ง Event เดิมซ้ำไปเรื่อยๆ)
+func (s *orderService) ProcessPaymentEvent(ctx context.Context, req dto.PaymentWebhookRequest, payload []byte) (domain.PaymentEventResult, error) {
+	var result domain.PaymentEventResult
+	err := s.uow.ExecuteWithContext(ctx, func(repos *datastore.Repositories) error {
+		event := &domain.PaymentEvent{
+			Provider:      req.Provider,
+			EventID:       req.EventID,
+			OrderID:       req.OrderID,
+			Status:        req.Status,
+			PaymentMethod: req.PaymentMethod,
+			Payload:       payload,
+		}
+		if err := repos.Order.CreatePaymentEvent(event); err != nil {
+			if errors.Is(err, repository.ErrDuplicatePaymentEvent) {
+				result = domain.PaymentEventDuplicate
+				return nil
+			}
+			return err
 		}
 
-		// 3. อัปเดตสถานะใหม่
-		order.Status = domain.StatusCompleted
+		applyErr := applyPaymentEvent(repos, event)
+		if applyErr != nil {
+			if !errors.Is(applyErr, ErrInvalidOrderStatus) && !errors.Is(applyErr, repository.ErrOrderNotFound) {
+				return applyErr
+			}
+			event.Result = domain.PaymentEventIgnored
+			event.Note = applyErr.Error()
+		} else {
+			event.Result = domain.PaymentEventApplied
+		}
 
-		// 4. บันทึกการเปลี่ยนแปลงลง Database
-		return repos.Order.Update(order)
+		result = event.Result
+		return repos.Order.UpdatePaymentEvent(event)
 	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
 }
 
-func (s *orderService) UpdateOrderStatus(orderID uint, status domain.OrderStatus, paymentMethod string) error {
+// applyPaymentEvent เปลี่ยนสถานะ Order ตามผลการชำระเงิน
+// success: pending -> processing, failed: ยกเลิก Order พร้อมคืนสต็อกและคูปอง
+// ใช้ได้กับ Order ที่ยัง pending เท่านั้น Event ที่มาช้าหรือถูกส่งซ้ำหลังชำระเงินแล้วจะถูกบันทึกเป็น ignored
+// (เช่น Event failed ของ Intent เก่าต้องไม่ยกเลิก Order ที่จ่ายเงินและออกใบกำกับภาษีไปแล้ว)
+func applyPaymentEvent(repos *datastore.Repositories, event *domain.PaymentEvent) error {
+	order, err := repos.Order.LockByID(event.OrderID)
+	if err != nil {
+		return err
+	}
+
+	target := domain.StatusProcessing
+	if event.Status != "success" {
+		target = domain.StatusCancelled
+	}
+	if order.Status != domain.StatusPending {
+		return fmt.Errorf("%w: order is '%s', not awaiting payment", ErrInvalidOrderStatus, order.Status)
+	}
+
+	order.PaymentMethod = &event.PaymentMethod
+	reason := fmt.Sprintf("payment webhook %s/%s (%s)", event.Provider, event.EventID, event.PaymentMethod)
+	if target == domain.StatusCancelled {
+		return CancelAndRestock(repos, order, nil, reason)
+	}
+	return TransitionOrderStatus(repos, order, target, nil, reason)
+}
+
+// ShipOrder ส่งสินค้าที่เหลือทั้งหมดของ Order ในพัสดุกล่องเดียว (ใช้ผู้ให้บริการตามวิธีจัดส่งที่ลูกค้าเลือก)
+// ถ้าต้องแบ่งส่งหลายกล่องให้ใช้ CreateShipment แทน
+func (s *orderService) ShipOrder(adminID, orderID uint, trackingNumber string) error {
 	return s.uow.Execute(func(repos *datastore.Repositories) error {
-		order, err := repos.Order.FindByID(orderID)
+		order, err := repos.Order.LockByID(orderID)
 		if err != nil {
 			return err
 		}
 
-		if status == domain.StatusProcessing {
-			order.Status = domain.StatusProcessing
+		carrier := order.ShippingCarrier
+		if carrier == "" {
+			carrier = "unspecified"
 		}
-
-		order.Status = status
-		order.PaymentMethod = &paymentMethod
-		return repos.Order.Update(order)
+		_, err = createShipment(repos, order, dto.CreateShipmentRequest{Carrier: carrier, TrackingNumber: trackingNumber}, &adminID)
+		return err
 	})
 }
 
-func (s *orderService) ShipOrder(orderID uint, trackingNumber string) error {
-	return s.uow.Execute(func(repos *datastore.Repositories) error {
-		order, err := repos.Order.FindByID(orderID)
+// GetOrderHistory ดึง Timeline การเปลี่ยนสถานะทั้งหมดของ Order (สำหรับ Admin/Support)
+func (s *orderService) GetOrderHistory(orderID uint) ([]dto.OrderStatusHistoryResponse, error) {
+	if _, err := s.uow.OrderRepository().FindByID(orderID); err != nil {
+		return nil, err
+	}
+
+	histories, err := s.uow.OrderRepository().FindStatusHistory(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OrderStatusHistoryResponse, 0, len(histories))
+	for _, h := range histories {
+		responses = append(responses, dto.OrderStatusHistoryResponse{
+			FromStatus: h.FromStatus,
+			ToStatus:   h.ToStatus,
+			ChangedBy:  h.ChangedBy,
+			Reason:     h.Reason,
+			CreatedAt:  h.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// CancelOrder ให้ลูกค้ายกเลิก Order ของตัวเองระหว่างรอชำระเงินหรือเตรียมของ (ยังไม่มีพัสดุ)
+// ถ้าชำระเงินแล้ว ยอดที่ได้รับทั้งหมดถูกจองคืนใน Transaction เดียวกับการยกเลิก แล้วคืนผ่าน Gateway หลัง Commit
+// ส่วนที่คืนผ่าน Gateway ไม่ได้ (เช่น โอนเงินผ่าน PromptPay) จะถูกบันทึกเป็นการคืนเงินนอกระบบให้ Admin โอนคืน
+func (s *orderService) CancelOrder(ctx context.Context, userID, orderID uint, reason string) (*dto.OrderResponse, error) {
+	var (
+		order   *domain.Order
+		refunds []domain.OrderRefund
+	)
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		// Lock Order กันการยกเลิกซ้อนกับการชำระเงิน, Webhook หรือการยกเลิกอัตโนมัติ
+		var err error
+		order, err = repos.Order.LockByID(orderID)
 		if err != nil {
 			return err
 		}
+		if order.UserID != userID {
+			return ErrOrderAccessDenied
+		}
+		if !customerCancellableStatuses[order.Status] {
+			return fmt.Errorf("%w: cannot cancel order in status '%s'", ErrInvalidOrderStatus, order.Status)
+		}
+		shipments, err := repos.Shipment.FindByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
+		if len(shipments) > 0 {
+			return fmt.Errorf("%w: order already has shipments, please contact support", ErrInvalidOrderStatus)
+		}
 
-		if order.Status != domain.StatusProcessing {
-			return fmt.Errorf("%w: cannot ship order in status '%s'", ErrInvalidOrderStatus, order.Status)
+		if reason == "" {
+			reason = "cancelled by customer"
 		}
+		if err := CancelAndRestock(repos, order, &userID, reason); err != nil {
+			return err
+		}
+
+		// ยอดที่ได้รับจริง = FinalPrice ที่ยังไม่ได้คืน หักยอดค้างชำระจากการแก้ไข Order
+		if order.PaidAt == nil {
+			return nil
+		}
+		if paid := domain.RoundMoney(order.FinalPrice - order.BalanceDue - order.RefundedAmount); paid > 0 {
+			refunds, err = s.payments.ReserveOrderRefund(repos, order, paid, reason)
+			if err != nil {
+				return fmt.Errorf("failed to reserve refund: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		order.Status = domain.StatusShipped
-		order.TrackingNumber = &trackingNumber
+	if len(refunds) > 0 {
+		// Order ถูกยกเลิกไปแล้ว ถ้าคืนเงินผ่าน Gateway ไม่สำเร็จ ยอดจะถูกปล่อยคืนให้ Admin คืนเงินผ่าน /payments/:id/refund
+		if _, err := s.payments.SettleRefunds(ctx, userID, order, nil, refunds); err != nil {
+			log.Printf("WARNING: order %d was cancelled by the customer but could not be refunded automatically: %v", orderID, err)
+		}
+	}
+	return s.GetOrderByID(userID, orderID)
+}
 
-		return repos.Order.Update(order)
+// AdminCancelOrder ให้ Admin ยกเลิก Order ได้ทุกสถานะที่ตาราง Transition อนุญาต (รวมถึงหลังจัดส่ง)
+func (s *orderService) AdminCancelOrder(adminID, orderID uint, reason string) (*dto.OrderResponse, error) {
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		order, err := repos.Order.LockByID(orderID)
+		if err != nil {
+			return err
+		}
+
+		if reason == "" {
+			reason = "cancelled by admin"
+		}
+		return CancelAndRestock(repos, order, &adminID, reason)
 	})
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.uow.OrderRepository().FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapOrderToOrderResponse(order), nil
+}
+
+// CancelAndRestock เปลี่ยนสถานะเป็น cancelled แล้วคืนสต็อกส่วนที่ยังไม่ได้ส่ง และคืนสิทธิ์คูปอง
+// สินค้าที่อยู่กับขนส่งแล้วไม่คืนสต็อก (ถ้าตีกลับจะถูกนับเป็นยังไม่ได้ส่ง ดู fulfilledQuantities)
+// ต้องเรียกภายใน uow.Execute เสมอ เพื่อให้ทุกอย่าง Commit หรือ Rollback พร้อมกัน
+func CancelAndRestock(repos *datastore.Repositories, order *domain.Order, changedBy *uint, reason string) error {
+	if err := TransitionOrderStatus(repos, order, domain.StatusCancelled, changedBy, reason); err != nil {
+		return err
+	}
+
+	shipments, err := repos.Shipment.FindByOrderID(order.ID)
+	if err != nil {
+		return err
+	}
+	shipped, _ := fulfilledQuantities(shipments)
+	for _, item := range order.OrderItems {
+		if item.Quantity <= shipped[item.ID] {
+			continue
+		}
+		if err := restoreStock(repos, order.ID, item.ProductID, item.Quantity-shipped[item.ID], outboxService.StockReasonOrderCancelled); err != nil {
+			return err
+		}
+	}
+
+	if order.AppliedCouponCode != nil {
+		if err := repos.Coupon.ReleaseUsage(*order.AppliedCouponCode); err != nil {
+			return fmt.Errorf("failed to release coupon %s: %w", *order.AppliedCouponCode, err)
+		}
+	}
+	return nil
+}
+
+// TransitionOrderStatus คือจุดเดียวที่ใช้เปลี่ยนสถานะ Order
+// จะตรวจสอบกับตาราง Transition ใน domain และบันทึก OrderStatusHistory ไปพร้อมกัน
+// ต้องเรียกภายใน uow.Execute เสมอ เพื่อให้สถานะและประวัติถูก Commit พร้อมกัน (Module อื่นเช่น payments ก็ใช้ได้)
+func TransitionOrderStatus(repos *datastore.Repositories, order *domain.Order, next domain.OrderStatus, changedBy *uint, reason string) error {
+	if !order.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: cannot change status from '%s' to '%s'", ErrInvalidOrderStatus, order.Status, next)
+	}
+
+	history := &domain.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: order.Status,
+		ToStatus:   next,
+		ChangedBy:  changedBy,
+		Reason:     reason,
+	}
+
+	previous := order.Status
+	order.Status = next
+	// processing หมายถึงได้รับเงินแล้ว ยกเว้นเก็บเงินปลายทางที่จะได้รับเงินตอนขนส่งโอนมา
+	paid := false
+	if next == domain.StatusProcessing && !order.IsCOD() && order.PaidAt == nil {
+		now := time.Now()
+		order.PaidAt = &now
+		paid = true
+	}
+	if err := repos.Order.Update(order); err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if err := repos.Order.CreateStatusHistory(history); err != nil {
+		return fmt.Errorf("failed to record order status: %w", err)
+	}
+
+	// ได้รับชำระเงินแล้ว ออกใบกำกับภาษีใน Transaction เดียวกัน (เลขที่จะไม่ขาดช่วงถ้ามี Rollback)
+	// เก็บเงินปลายทางยังไม่ได้รับเงิน ใบกำกับภาษีจะออกตอนขนส่งโอนเงินมาครบ (ดู payments/service.remitShipment)
+	if next == domain.StatusProcessing && order.PaidAt != nil {
+		if _, err := invoiceService.IssueInvoice(repos, order); err != nil {
+			return err
+		}
+	}
+
+	// บันทึก Domain Event ลง Outbox ใน Transaction เดียวกับสถานะใหม่
+	var eventType string
+	switch {
+	case paid:
+		eventType = domain.EventOrderPaid
+	case next == domain.StatusShipped:
+		eventType = domain.EventOrderShipped
+	case next == domain.StatusCompleted:
+		eventType = domain.EventOrderCompleted
+	case next == domain.StatusCancelled:
+		eventType = domain.EventOrderCancelled
+	default:
+		return nil
+	}
+	return outboxService.RecordOrderEvent(repos, eventType, order, previous, reason)
+}
+
+// restoreStock คืนสต็อกแบบ Atomic และบันทึก inventory.stock_changed ใน Transaction เดียวกัน
+func restoreStock(repos *datastore.Repositories, orderID, productID uint, quantity uint, reason string) error {
+	if err := repos.Product.RestoreStock(productID, quantity); err != nil {
+		return fmt.Errorf("failed to restore stock for product %d: %w", productID, err)
+	}
+	return outboxService.RecordStockChanged(repos, productID, int(quantity), reason, orderID)
 }
+
 func mapAddressToResponse(address *domain.Address) *dto.AddressResponse {
 	// ป้องกันกรณีที่ Address เป็น nil
 	if address == nil || address.ID == 0 {
@@ -231,5 +748,23 @@ func mapAddressToResponse(address *domain.Address) *dto.AddressResponse {
 		PostalCode:   address.PostalCode,
 		Country:      address.Country,
 		IsDefault:    address.IsDefault,
+		CompanyName:  address.CompanyName,
+		TaxID:        address.TaxID,
+		BranchCode:   address.BranchCode,
+	}
+}
+
+func mapSnapshotToAddressResponse(addressID uint, snapshot domain.AddressSnapshot) *dto.AddressResponse {
+	return &dto.AddressResponse{
+		ID:           addressID,
+		AddressLine1: snapshot.AddressLine1,
+		AddressLine2: snapshot.AddressLine2,
+		City:         snapshot.City,
+		State:        snapshot.State,
+		PostalCode:   snapshot.PostalCode,
+		Country:      snapshot.Country,
+		CompanyName:  snapshot.CompanyName,
+		TaxID:        snapshot.TaxID,
+		BranchCode:   snapshot.BranchCode,
 	}
 }
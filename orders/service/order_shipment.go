@@ -0,0 +1,368 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	"backend/orders/dto"
+	"backend/orders/repository"
+	outboxService "backend/outbox/service"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrInvalidShipmentItems = errors.New("shipment items are not valid for this order")
+	ErrTrackingNumberInUse  = errors.New("tracking number is already used by another shipment")
+)
+
+// TrackingEventResult คือผลของการรับ Event จากขนส่ง 1 ครั้ง
+type TrackingEventResult string
+
+const (
+	TrackingEventApplied   TrackingEventResult = "applied"   // บันทึกและอัปเดตสถานะพัสดุแล้ว
+	TrackingEventRecorded  TrackingEventResult = "recorded"  // บันทึกในประวัติ แต่เก่ากว่าสถานะปัจจุบันจึงไม่เปลี่ยนสถานะ
+	TrackingEventDuplicate TrackingEventResult = "duplicate" // เคยได้รับ Event นี้แล้ว
+)
+
+// shippableStatuses คือสถานะของ Order ที่สร้างพัสดุเพิ่มได้
+var shippableStatuses = map[domain.OrderStatus]bool{
+	domain.StatusProcessing: true,
+	domain.StatusShipped:    true,
+}
+
+// CreateShipment สร้างพัสดุ 1 กล่องสำหรับสินค้าบางส่วนหรือทั้งหมดของ Order
+func (s *orderService) CreateShipment(adminID, orderID uint, req dto.CreateShipmentRequest) (*dto.ShipmentResponse, error) {
+	var created *domain.Shipment
+	var order *domain.Order
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		order, err = repos.Order.LockByID(orderID)
+		if err != nil {
+			return err
+		}
+		created, err = createShipment(repos, order, req, &adminID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapShipmentToResponse(created, order.OrderItems), nil
+}
+
+// GetShipments ดึงพัสดุทั้งหมดของ Order (ลูกค้าดูได้เฉพาะ Order ของตัวเอง)
+func (s *orderService) GetShipments(userID uint, isAdmin bool, orderID uint) ([]dto.ShipmentResponse, error) {
+	order, err := s.uow.OrderRepository().FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && order.UserID != userID {
+		return nil, ErrOrderAccessDenied
+	}
+
+	shipments, err := s.uow.ShipmentRepository().FindByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.ShipmentResponse, 0, len(shipments))
+	for i := range shipments {
+		responses = append(responses, *mapShipmentToResponse(&shipments[i], order.OrderItems))
+	}
+	return responses, nil
+}
+
+// ProcessTrackingEvent บันทึกสถานะพัสดุที่ขนส่งแจ้งเข้ามา แล้วปรับสถานะ Order ตามพัสดุทั้งหมด
+func (s *orderService) ProcessTrackingEvent(req dto.TrackingWebhookRequest) (TrackingEventResult, error) {
+	var result TrackingEventResult
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		shipment, err := repos.Shipment.FindByTracking(req.Carrier, req.TrackingNumber)
+		if err != nil {
+			return err
+		}
+		// Lock Order ก่อน เพื่อให้ Event ของพัสดุหลายกล่องใน Order เดียวกันทำงานทีละรายการ
+		order, err := repos.Order.LockByID(shipment.OrderID)
+		if err != nil {
+			return err
+		}
+
+		occurredAt := req.OccurredAt.UTC()
+		if err := repos.Shipment.CreateTrackingEvent(&domain.TrackingEvent{
+			ShipmentID:  shipment.ID,
+			ExternalID:  req.EventID,
+			Status:      req.Status,
+			Description: req.Description,
+			Location:    req.Location,
+			OccurredAt:  occurredAt,
+		}); err != nil {
+			if errors.Is(err, repository.ErrDuplicateTrackingEvent) {
+				result = TrackingEventDuplicate
+				return nil
+			}
+			return err
+		}
+
+		// ขนส่งอาจส่ง Event มาไม่เรียงเวลา ใช้เฉพาะ Event ที่ใหม่กว่าล่าสุดมากำหนดสถานะ
+		if shipment.Status.IsFinal() || (shipment.LastEventAt != nil && occurredAt.Before(*shipment.LastEventAt)) {
+			result = TrackingEventRecorded
+			return nil
+		}
+		shipment.Status = req.Status
+		shipment.LastEventAt = &occurredAt
+		if req.Status == domain.ShipmentDelivered {
+			shipment.DeliveredAt = &occurredAt
+		}
+		// พัสดุเก็บเงินปลายทางที่ตีกลับ ไม่มีเงินให้ขนส่งโอนมาแล้ว ยอดที่เหลือจะไปเก็บกับกล่องที่ส่งใหม่
+		if req.Status == domain.ShipmentReturned && shipment.CODStatus == domain.CODPending {
+			shipment.CODStatus = domain.CODVoid
+		}
+		if err := repos.Shipment.Update(shipment); err != nil {
+			return fmt.Errorf("failed to update shipment: %w", err)
+		}
+
+		result = TrackingEventApplied
+		return syncOrderFulfillment(repos, order, nil)
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// createShipment ตรวจจำนวนสินค้าที่ยังไม่ได้ส่งแล้วสร้างพัสดุ ต้องเรียกภายใน Transaction โดย Lock Order ไว้แล้ว
+func createShipment(repos *datastore.Repositories, order *domain.Order, req dto.CreateShipmentRequest, createdBy *uint) (*domain.Shipment, error) {
+	if !shippableStatuses[order.Status] {
+		return nil, fmt.Errorf("%w: cannot ship an order in '%s' status", ErrInvalidOrderStatus, order.Status)
+	}
+	// ยอดเพิ่มจากการแก้ไข Order ต้องชำระก่อนส่งของ
+	if order.BalanceDue > 0 {
+		return nil, fmt.Errorf("%w: order has an unpaid balance of %.2f", ErrInvalidOrderStatus, order.BalanceDue)
+	}
+
+	if _, err := repos.Shipment.FindByTracking(req.Carrier, req.TrackingNumber); err == nil {
+		return nil, ErrTrackingNumberInUse
+	} else if !errors.Is(err, repository.ErrShipmentNotFound) {
+		return nil, err
+	}
+
+	existing, err := repos.Shipment.FindByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	shipped, _ := fulfilledQuantities(existing)
+
+	// จำนวนที่ยังไม่ได้ส่งของแต่ละ OrderItem
+	remaining := make(map[uint]uint, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		if item.Quantity > shipped[item.ID] {
+			remaining[item.ID] = item.Quantity - shipped[item.ID]
+		}
+	}
+
+	items := make([]domain.ShipmentItem, 0, len(order.OrderItems))
+	if len(req.Items) == 0 {
+		for _, item := range order.OrderItems {
+			if qty := remaining[item.ID]; qty > 0 {
+				items = append(items, domain.ShipmentItem{OrderItemID: item.ID, Quantity: qty})
+			}
+		}
+		if len(items) == 0 {
+			return nil, fmt.Errorf("%w: every item has already been shipped", ErrInvalidShipmentItems)
+		}
+	} else {
+		requested := make(map[uint]uint, len(req.Items))
+		for _, item := range req.Items {
+			requested[item.OrderItemID] += item.Quantity
+		}
+		for _, item := range req.Items {
+			if requested[item.OrderItemID] > remaining[item.OrderItemID] {
+				return nil, fmt.Errorf("%w: order item %d has only %d left to ship", ErrInvalidShipmentItems, item.OrderItemID, remaining[item.OrderItemID])
+			}
+			items = append(items, domain.ShipmentItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+		}
+	}
+
+	now := time.Now().UTC()
+	shipment := &domain.Shipment{
+		OrderID:        order.ID,
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+		Origin:         req.Origin,
+		Status:         domain.ShipmentInTransit,
+		CreatedBy:      createdBy,
+		LastEventAt:    &now,
+		Items:          items,
+		Events: []domain.TrackingEvent{{
+			ExternalID:  "created",
+			Status:      domain.ShipmentInTransit,
+			Description: "shipment handed over to carrier",
+			Location:    req.Origin,
+			OccurredAt:  now,
+		}},
+	}
+	// Order เก็บเงินปลายทางที่ยังไม่ได้รับเงิน: กำหนดยอดที่ขนส่งต้องเก็บจากกล่องนี้
+	if order.IsCOD() && order.PaidAt == nil {
+		shipment.CODAmount = codAmountForShipment(order, existing, remaining, items)
+		shipment.CODStatus = domain.CODPending
+	}
+	if err := repos.Shipment.Create(shipment); err != nil {
+		return nil, fmt.Errorf("failed to create shipment: %w", err)
+	}
+	if err := outboxService.RecordShipmentCreated(repos, order, shipment); err != nil {
+		return nil, err
+	}
+
+	// TrackingNumber ของ Order เก็บเลขพัสดุกล่องแรกไว้ (ใช้ใน Export และหน้าจอเดิม)
+	if order.TrackingNumber == nil {
+		order.TrackingNumber = &shipment.TrackingNumber
+		if err := repos.Order.Update(order); err != nil {
+			return nil, fmt.Errorf("failed to update order tracking number: %w", err)
+		}
+	}
+
+	return shipment, syncOrderFulfillment(repos, order, createdBy)
+}
+
+// codAmountForShipment คำนวณยอดเก็บเงินปลายทางของพัสดุกล่องใหม่
+// คิดตามมูลค่าสินค้าในกล่อง (ปันส่วนส่วนลดและภาษีตามสัดส่วนราคา) ค่าจัดส่งและค่าห่อของขวัญเก็บกับกล่องแรก
+// กล่องที่ทำให้ส่งครบทุกชิ้นจะเก็บยอดที่เหลือทั้งหมด เพื่อให้ยอดรวมทุกกล่องเท่ากับ FinalPrice พอดี
+func codAmountForShipment(order *domain.Order, existing []domain.Shipment, remaining map[uint]uint, items []domain.ShipmentItem) float64 {
+	var assigned float64
+	active := 0
+	for _, shipment := range existing {
+		if shipment.Status == domain.ShipmentReturned {
+			continue
+		}
+		assigned += shipment.CODAmount
+		active++
+	}
+	outstanding := math.Max(domain.RoundMoney(order.FinalPrice-assigned), 0)
+
+	priceByItem := make(map[uint]float64, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		priceByItem[item.ID] = item.Price
+	}
+	var subtotal float64
+	var shippedQty, remainingQty uint
+	for _, item := range items {
+		subtotal += priceByItem[item.OrderItemID] * float64(item.Quantity)
+		shippedQty += item.Quantity
+	}
+	for _, qty := range remaining {
+		remainingQty += qty
+	}
+	if shippedQty >= remainingQty || order.TotalPrice <= 0 {
+		return outstanding
+	}
+
+	amount := subtotal * (order.FinalPrice - order.AddOnCost()) / order.TotalPrice
+	if active == 0 {
+		amount += order.AddOnCost()
+	}
+	return math.Min(domain.RoundMoney(amount), outstanding)
+}
+
+// syncOrderFulfillment ปรับสถานะ Order ตามพัสดุทั้งหมด
+// ส่งครบทุกชิ้นแล้ว -> shipped และผู้รับได้รับครบทุกชิ้นแล้ว -> completed
+func syncOrderFulfillment(repos *datastore.Repositories, order *domain.Order, changedBy *uint) error {
+	shipments, err := repos.Shipment.FindByOrderID(order.ID)
+	if err != nil {
+		return err
+	}
+	shipped, delivered := fulfilledQuantities(shipments)
+
+	allShipped, allDelivered := true, true
+	for _, item := range order.OrderItems {
+		if shipped[item.ID] < item.Quantity {
+			allShipped = false
+		}
+		if delivered[item.ID] < item.Quantity {
+			allDelivered = false
+		}
+	}
+
+	// มีพัสดุตีกลับ สินค้ากลับมาอยู่ในคลัง ย้าย Order กลับไป processing ให้คลังส่งใหม่หรือ Admin ยกเลิก
+	if !allShipped && order.Status == domain.StatusShipped {
+		return TransitionOrderStatus(repos, order, domain.StatusProcessing, changedBy, "parcel returned to sender, awaiting re-shipment or cancellation")
+	}
+	if allShipped && order.Status == domain.StatusProcessing {
+		if err := TransitionOrderStatus(repos, order, domain.StatusShipped, changedBy, "all items shipped"); err != nil {
+			return err
+		}
+	}
+	if allDelivered && order.Status == domain.StatusShipped {
+		if err := TransitionOrderStatus(repos, order, domain.StatusCompleted, changedBy, "all items delivered"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fulfilledQuantities รวมจำนวนที่ส่งแล้วและที่ส่งถึงแล้วของแต่ละ OrderItem
+// พัสดุที่ตีกลับไม่นับเป็นส่งแล้ว เพราะสินค้ากลับมาอยู่ในคลัง (ส่งใหม่ได้ หรือคืนสต็อกตอนยกเลิก)
+func fulfilledQuantities(shipments []domain.Shipment) (shipped, delivered map[uint]uint) {
+	shipped = make(map[uint]uint)
+	delivered = make(map[uint]uint)
+	for _, shipment := range shipments {
+		if shipment.Status == domain.ShipmentReturned {
+			continue
+		}
+		for _, item := range shipment.Items {
+			shipped[item.OrderItemID] += item.Quantity
+			if shipment.Status == domain.ShipmentDelivered {
+				delivered[item.OrderItemID] += item.Quantity
+			}
+		}
+	}
+	return shipped, delivered
+}
+
+func mapShipmentToResponse(shipment *domain.Shipment, orderItems []domain.OrderItem) *dto.ShipmentResponse {
+	orderItemByID := make(map[uint]domain.OrderItem, len(orderItems))
+	for _, item := range orderItems {
+		orderItemByID[item.ID] = item
+	}
+
+	items := make([]dto.ShipmentItemResponse, 0, len(shipment.Items))
+	for _, item := range shipment.Items {
+		orderItem := orderItemByID[item.OrderItemID]
+		name := orderItem.ProductName
+		if name == "" {
+			name = orderItem.Product.Name
+		}
+		items = append(items, dto.ShipmentItemResponse{
+			OrderItemID: item.OrderItemID,
+			ProductID:   orderItem.ProductID,
+			Name:        name,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	events := make([]dto.TrackingEventResponse, 0, len(shipment.Events))
+	for _, event := range shipment.Events {
+		events = append(events, dto.TrackingEventResponse{
+			Status:      event.Status,
+			Description: event.Description,
+			Location:    event.Location,
+			OccurredAt:  event.OccurredAt,
+		})
+	}
+
+	return &dto.ShipmentResponse{
+		ID:             shipment.ID,
+		OrderID:        shipment.OrderID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		Origin:         shipment.Origin,
+		Status:         shipment.Status,
+		DeliveredAt:    shipment.DeliveredAt,
+		CreatedAt:      shipment.CreatedAt,
+		Items:          items,
+		Events:         events,
+
+		CODAmount:         shipment.CODAmount,
+		CODStatus:         shipment.CODStatus,
+		CODRemittedAmount: shipment.CODRemittedAmount,
+		CODRemittedAt:     shipment.CODRemittedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"backend/domain"
+	"encoding/json"
+	"time"
+)
+
+// OutboxQueryParams คือ Filter และการแบ่งหน้าของรายการ Event ในหน้า Admin
+type OutboxQueryParams struct {
+	Page  int
+	Limit int
+
+	Status        domain.OutboxStatus // ว่าง = ทุกสถานะ
+	EventType     string
+	AggregateType string
+	AggregateID   uint // 0 = ทุก Aggregate
+}
+
+// OutboxEventResponse คือข้อมูล Event 1 รายการสำหรับ Admin
+type OutboxEventResponse struct {
+	ID            uint                `json:"id"`
+	EventID       string              `json:"event_id"`
+	EventType     string              `json:"event_type"`
+	AggregateType string              `json:"aggregate_type"`
+	AggregateID   uint                `json:"aggregate_id"`
+	Payload       json.RawMessage     `json:"payload"`
+	OccurredAt    time.Time           `json:"occurred_at"`
+	Status        domain.OutboxStatus `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	NextAttemptAt time.Time           `json:"next_attempt_at"`
+	LastError     string              `json:"last_error,omitempty"`
+	LockedUntil   *time.Time          `json:"locked_until,omitempty"`
+	PublishedAt   *time.Time          `json:"published_at,omitempty"`
+}
+
+// PaginatedOutboxEventsDTO คือ Response รายการ Event แบบแบ่งหน้า
+type PaginatedOutboxEventsDTO struct {
+	Data        []OutboxEventResponse `json:"data"`
+	TotalItems  int64                 `json:"total_items"`
+	TotalPages  int                   `json:"total_pages"`
+	CurrentPage int                   `json:"current_page"`
+	Limit       int                   `json:"limit"`
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"backend/domain"
+	"backend/outbox/dto"
+	"backend/outbox/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OutboxHandler struct {
+	outboxSvc service.OutboxService
+}
+
+func NewOutboxHandler(outboxSvc service.OutboxService) *OutboxHandler {
+	return &OutboxHandler{outboxSvc: outboxSvc}
+}
+
+// HandleListEvents ดู Event ใน Outbox กรองด้วย ?status= &event_type= &aggregate_type= &aggregate_id= ได้
+func (h *OutboxHandler) HandleListEvents(c *fiber.Ctx) error {
+	params := dto.OutboxQueryParams{
+		Page:          c.QueryInt("page", 1),
+		Limit:         c.QueryInt("limit", 20),
+		Status:        domain.OutboxStatus(c.Query("status")),
+		EventType:     c.Query("event_type"),
+		AggregateType: c.Query("aggregate_type"),
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+	switch params.Status {
+	case "", domain.OutboxPending, domain.OutboxProcessing, domain.OutboxPublished, domain.OutboxFailed:
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid status, expected pending, processing, published or failed")
+	}
+	if id := c.QueryInt("aggregate_id", 0); id > 0 {
+		params.AggregateID = uint(id)
+	}
+
+	events, err := h.outboxSvc.ListEvents(params)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(events)
+}
+
+// HandleRetryEvent สั่งส่ง Event ที่ยังไม่สำเร็จใหม่อีกครั้ง
+func (h *OutboxHandler) HandleRetryEvent(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid event ID")
+	}
+
+	event, err := h.outboxSvc.RetryEvent(uint(id))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(event)
+}
@@ -0,0 +1,36 @@
+package outbox
+
+import (
+	"backend/config"
+	"backend/internal/datastore"
+	"backend/middleware"
+	"backend/outbox/handler"
+	"backend/outbox/publisher"
+	"backend/outbox/service"
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"log"
+)
+
+func RegisterModule(api fiber.Router, uow datastore.UnitOfWork, cfg *config.Config) {
+	pub, err := publisher.New(cfg.OutboxPublisher, publisher.Options{
+		WebhookURL:     cfg.OutboxWebhookURL,
+		WebhookSecret:  cfg.OutboxWebhookSecret,
+		WebhookTimeout: cfg.OutboxWebhookTimeout,
+	})
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	outboxSvc := service.NewOutboxService(uow, pub)
+	outboxHdl := handler.NewOutboxHandler(outboxSvc)
+
+	adminAPI := api.Group("/admin/outbox", middleware.Protected(), middleware.AdminRequired())
+	adminAPI.Get("/events", outboxHdl.HandleListEvents)
+	adminAPI.Post("/events/:id/retry", outboxHdl.HandleRetryEvent)
+
+	// ส่ง Domain Event ที่ Service ต่างๆ บันทึกไว้ออกไปยังระบบภายนอก
+	service.StartOutboxRelay(context.Background(), outboxSvc, cfg.OutboxRelayInterval, cfg.OutboxRetention)
+
+	log.Printf("✅ Outbox module registered successfully (publisher: %s).", pub.Name())
+}
@@ -0,0 +1,19 @@
+package publisher
+
+import (
+	"backend/domain"
+	"context"
+	"log"
+)
+
+// LogPublisher เขียน Event ลง Log แทนการส่งออกไปจริง (ใช้ตอนพัฒนาหรือยังไม่มีระบบปลายทาง)
+type LogPublisher struct{}
+
+func (p *LogPublisher) Name() string {
+	return "log"
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event *domain.OutboxEvent) error {
+	log.Printf("Outbox event %s %s (%s %d): %s", event.EventID, event.EventType, event.AggregateType, event.AggregateID, event.Payload)
+	return nil
+}
@@ -0,0 +1,65 @@
+// outbox/publisher/publisher.go
+package publisher
+
+import (
+	"backend/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrUnknownPublisher = errors.New("unknown outbox publisher")
+
+// Envelope คือรูปแบบ Event ที่ส่งให้ระบบปลายทาง (data คือ Payload ตามประเภทของ Event)
+type Envelope struct {
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint            `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// NewEnvelope แปลง OutboxEvent เป็น Envelope ที่พร้อมส่ง
+func NewEnvelope(event *domain.OutboxEvent) Envelope {
+	return Envelope{
+		EventID:       event.EventID,
+		EventType:     event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.OccurredAt,
+		Data:          json.RawMessage(event.Payload),
+	}
+}
+
+// Publisher คือ Interface กลางของช่องทางที่ใช้ส่ง Event ออกไป
+// Publish ต้องคืน nil ก็ต่อเมื่อปลายทางรับ Event แล้วจริงๆ ถ้าคืน Error Relay จะส่งใหม่ภายหลัง
+type Publisher interface {
+	// Name คือชื่อช่องทาง ใช้แสดงใน Log
+	Name() string
+	Publish(ctx context.Context, event *domain.OutboxEvent) error
+}
+
+// Options คือค่าที่ Publisher แต่ละแบบต้องใช้
+type Options struct {
+	WebhookURL     string
+	WebhookSecret  string // Secret สำหรับสร้าง HMAC Signature ให้ปลายทางตรวจสอบ
+	WebhookTimeout time.Duration
+}
+
+// New คืน Publisher ตามชื่อใน Config ("" หรือ "log" = เขียนลง Log อย่างเดียว, "webhook" = POST ไปที่ WebhookURL)
+func New(name string, opts Options) (Publisher, error) {
+	switch name {
+	case "", "log":
+		return &LogPublisher{}, nil
+	case "webhook":
+		if opts.WebhookURL == "" {
+			return nil, fmt.Errorf("%w: webhook publisher requires a URL", ErrUnknownPublisher)
+		}
+		return NewWebhookPublisher(opts.WebhookURL, opts.WebhookSecret, opts.WebhookTimeout), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPublisher, name)
+	}
+}
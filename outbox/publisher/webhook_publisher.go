@@ -0,0 +1,88 @@
+package publisher
+
+import (
+	"backend/domain"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventIDHeader          = "X-Event-ID"
+	EventTypeHeader        = "X-Event-Type"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookPublisher ส่ง Event เป็น HTTP POST ไปยัง URL เดียว
+// ถ้ามี Secret จะแนบ Signature แบบเดียวกับ Webhook ที่ระบบเรารับเข้ามา:
+// hex(HMAC-SHA256(secret, timestamp + "." + body)) ปลายทางตอบ 2xx ถือว่ารับแล้ว
+type WebhookPublisher struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url, secret string, timeout time.Duration) *WebhookPublisher {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookPublisher{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *WebhookPublisher) Name() string {
+	return "webhook"
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, event.EventID)
+	req.Header.Set(EventTypeHeader, event.EventType)
+	if p.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignPayload(p.secret, timestamp, body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("webhook responded %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	return nil
+}
+
+// SignPayload สร้าง Signature ของ Webhook: hex(HMAC-SHA256(secret, timestamp + "." + body))
+// เป็นตัวเดียวกับที่ middleware.VerifyWebhookSignature ใช้ตรวจ Webhook ขาเข้า
+func SignPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"backend/domain"
+	"backend/outbox/dto"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrOutboxEventNotFound = errors.New("outbox event not found")
+
+type OutboxRepository interface {
+	Create(event *domain.OutboxEvent) error
+	FindByID(id uint) (*domain.OutboxEvent, error)
+	FindAll(params dto.OutboxQueryParams) ([]domain.OutboxEvent, int64, error)
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.OutboxEvent, error)
+	Update(event *domain.OutboxEvent) error
+	Acknowledge(event *domain.OutboxEvent) error
+	DeletePublishedBefore(cutoff time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(event *domain.OutboxEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *outboxRepository) FindByID(id uint) (*domain.OutboxEvent, error) {
+	var event domain.OutboxEvent
+	err := r.db.First(&event, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutboxEventNotFound
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+// FindAll ดึง Event ตาม Filter พร้อมจำนวนทั้งหมด (ใหม่สุดก่อน)
+func (r *outboxRepository) FindAll(params dto.OutboxQueryParams) ([]domain.OutboxEvent, int64, error) {
+	query := r.db.Model(&domain.OutboxEvent{})
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.EventType != "" {
+		query = query.Where("event_type = ?", params.EventType)
+	}
+	if params.AggregateType != "" {
+		query = query.Where("aggregate_type = ?", params.AggregateType)
+	}
+	if params.AggregateID != 0 {
+		query = query.Where("aggregate_id = ?", params.AggregateID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []domain.OutboxEvent
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("id desc").Offset(offset).Limit(params.Limit).Find(&events).Error
+	return events, total, err
+}
+
+// outboxClaimLockKey คือคีย์ของ Advisory Lock ที่ให้ Relay จอง Event ได้ทีละ Instance
+const outboxClaimLockKey = 7340001
+
+// ClaimDue จอง Event ที่ถึงเวลาส่งตามลำดับที่เกิดขึ้น โดยเปลี่ยนเป็น processing พร้อมเวลาหมดการจอง (lease)
+// Event ที่ processing แต่เลยเวลาจองแล้ว (Relay ล่มระหว่างส่ง) จะถูกจองใหม่ได้
+// Event จะถูกข้ามถ้ามี Event ก่อนหน้าของ Aggregate เดียวกันที่ยังรอส่งใหม่ (ยังไม่ถึงเวลา),
+// กำลังถูกส่งโดย Relay อื่น หรือถูกพักเป็น failed เพื่อให้ปลายทางได้รับ Event ตามลำดับเสมอ
+// การจองทำทีละ Instance ด้วย Advisory Lock (Transaction สั้นๆ) จึงไม่มี Relay สองตัวจอง Event ของ Aggregate เดียวกันแซงกัน
+// ต้องเรียกภายใน Transaction (uow.Execute) เท่านั้น
+func (r *outboxRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.OutboxEvent, error) {
+	if err := r.db.Exec("SELECT pg_advisory_xact_lock(?)", outboxClaimLockKey).Error; err != nil {
+		return nil, err
+	}
+
+	var events []domain.OutboxEvent
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+			domain.OutboxPending, now, domain.OutboxProcessing, now).
+		Where(`NOT EXISTS (SELECT 1 FROM outbox_events prev
+			WHERE prev.aggregate_type = outbox_events.aggregate_type AND prev.aggregate_id = outbox_events.aggregate_id
+			AND prev.id < outbox_events.id AND prev.deleted_at IS NULL
+			AND ((prev.status = ? AND prev.next_attempt_at > ?) OR (prev.status = ? AND prev.locked_until >= ?) OR prev.status = ?))`,
+			domain.OutboxPending, now, domain.OutboxProcessing, now, domain.OutboxFailed).
+		Order("id asc").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil || len(events) == 0 {
+		return events, err
+	}
+
+	lockedUntil := now.Add(lease)
+	ids := make([]uint, 0, len(events))
+	for i := range events {
+		events[i].Status = domain.OutboxProcessing
+		events[i].LockedUntil = &lockedUntil
+		ids = append(ids, events[i].ID)
+	}
+	err = r.db.Model(&domain.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"status": domain.OutboxProcessing, "locked_until": lockedUntil}).Error
+	return events, err
+}
+
+func (r *outboxRepository) Update(event *domain.OutboxEvent) error {
+	return r.db.Save(event).Error
+}
+
+// Acknowledge บันทึกผลการส่งของ Event ที่จองไว้ (published, รอส่งใหม่ หรือ failed) และปลดการจอง
+// ถ้าการจองถูกยกเลิกไปแล้ว (เช่น Admin สั่งส่งใหม่) จะไม่เขียนทับ
+func (r *outboxRepository) Acknowledge(event *domain.OutboxEvent) error {
+	event.LockedUntil = nil
+	return r.db.Model(&domain.OutboxEvent{}).
+		Where("id = ? AND status = ?", event.ID, domain.OutboxProcessing).
+		Updates(map[string]interface{}{
+			"status":          event.Status,
+			"attempts":        event.Attempts,
+			"next_attempt_at": event.NextAttemptAt,
+			"last_error":      event.LastError,
+			"published_at":    event.PublishedAt,
+			"locked_until":    nil,
+		}).Error
+}
+
+// DeletePublishedBefore ลบ Event ที่ส่งสำเร็จก่อน cutoff ทิ้งถาวร (กันตารางโตไม่จำกัด)
+func (r *outboxRepository) DeletePublishedBefore(cutoff time.Time) (int64, error) {
+	tx := r.db.Unscoped().
+		Where("status = ? AND published_at < ?", domain.OutboxPublished, cutoff).
+		Delete(&domain.OutboxEvent{})
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	productRepo "backend/products/repository"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// เหตุผลที่สต็อกเปลี่ยน (ฟิลด์ reason ของ inventory.stock_changed)
+const (
+	StockReasonOrderPlaced     = "order_placed"
+	StockReasonOrderEdited     = "order_edited"
+	StockReasonOrderCancelled  = "order_cancelled"
+	StockReasonReturnRestocked = "return_restocked"
+	StockReasonProductCreated  = "product_created"
+	StockReasonAdminAdjustment = "admin_adjustment"
+)
+
+// OrderItemPayload คือสินค้า 1 รายการใน Event ของ Order
+type OrderItemPayload struct {
+	OrderItemID uint    `json:"order_item_id"`
+	ProductID   uint    `json:"product_id"`
+	SKU         string  `json:"sku"`
+	Name        string  `json:"name"`
+	Quantity    uint    `json:"quantity"`
+	Price       float64 `json:"price"`
+}
+
+// OrderPayload คือข้อมูลของ Order ณ ตอนที่เกิด Event (order.*)
+type OrderPayload struct {
+	OrderID        uint               `json:"order_id"`
+	OrderNumber    string             `json:"order_number,omitempty"`
+	UserID         uint               `json:"user_id"`
+	Status         domain.OrderStatus `json:"status"`
+	PreviousStatus domain.OrderStatus `json:"previous_status,omitempty"`
+	PaymentMethod  string             `json:"payment_method,omitempty"`
+	TotalPrice     float64            `json:"total_price"`
+	Discount       float64            `json:"discount"`
+	ShippingCost   float64            `json:"shipping_cost"`
+	GiftWrapPrice  float64            `json:"gift_wrap_price"`
+	TaxAmount      float64            `json:"tax_amount"`
+	FinalPrice     float64            `json:"final_price"`
+	RefundedAmount float64            `json:"refunded_amount"`
+	BalanceDue     float64            `json:"balance_due,omitempty"`
+	Currency       string             `json:"currency"`
+	PaidAt         *time.Time         `json:"paid_at,omitempty"`
+	Reason         string             `json:"reason,omitempty"`
+	Items          []OrderItemPayload `json:"items"`
+}
+
+// OrderRefundPayload คือยอดที่คืนเงินให้ลูกค้า (order.refunded)
+type OrderRefundPayload struct {
+	OrderID         uint    `json:"order_id"`
+	OrderNumber     string  `json:"order_number,omitempty"`
+	ReturnRequestID *uint   `json:"return_request_id,omitempty"`
+	Amount          float64 `json:"amount"`
+	TaxAmount       float64 `json:"tax_amount"`
+	Currency        string  `json:"currency"`
+}
+
+// ShipmentItemPayload คือสินค้าที่อยู่ในพัสดุ
+type ShipmentItemPayload struct {
+	OrderItemID uint   `json:"order_item_id"`
+	ProductID   uint   `json:"product_id,omitempty"`
+	SKU         string `json:"sku,omitempty"`
+	Quantity    uint   `json:"quantity"`
+}
+
+// ShipmentPayload คือข้อมูลพัสดุที่ส่งมอบให้ขนส่ง (shipment.created)
+type ShipmentPayload struct {
+	ShipmentID     uint                  `json:"shipment_id"`
+	OrderID        uint                  `json:"order_id"`
+	OrderNumber    string                `json:"order_number,omitempty"`
+	Carrier        string                `json:"carrier"`
+	TrackingNumber string                `json:"tracking_number"`
+	CODAmount      float64               `json:"cod_amount,omitempty"`
+	Items          []ShipmentItemPayload `json:"items"`
+}
+
+// PaymentRefundPayload คือการคืนเงินผ่าน Payment Gateway (payment.refunded)
+type PaymentRefundPayload struct {
+	PaymentID      uint                 `json:"payment_id"`
+	OrderID        uint                 `json:"order_id"`
+	Provider       string               `json:"provider"`
+	Amount         float64              `json:"amount"`
+	RefundedAmount float64              `json:"refunded_amount"`
+	Status         domain.PaymentStatus `json:"status"`
+	Reason         string               `json:"reason,omitempty"`
+	Currency       string               `json:"currency"`
+}
+
+// StockChangedPayload คือการเปลี่ยนแปลงสต็อกของสินค้า (inventory.stock_changed)
+// Quantity คือสต็อกหลังเปลี่ยน (ไม่มีถ้าสินค้าถูกลบไปแล้ว) ระบบปลายทางควรใช้ค่านี้แทนการบวก Delta สะสม
+type StockChangedPayload struct {
+	ProductID uint   `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Delta     int    `json:"delta"`
+	Quantity  *int   `json:"quantity,omitempty"`
+	Reason    string `json:"reason"`
+	OrderID   *uint  `json:"order_id,omitempty"`
+}
+
+// Record บันทึก Event ลง Outbox ต้องเรียกภายใน uow.Execute เสมอ
+// Event จะถูกส่งออกไปก็ต่อเมื่อ Transaction ของข้อมูลที่เปลี่ยน Commit สำเร็จเท่านั้น
+func Record(repos *datastore.Repositories, aggregateType string, aggregateID uint, eventType string, payload interface{}) error {
+	event, err := domain.NewOutboxEvent(aggregateType, aggregateID, eventType, payload)
+	if err != nil {
+		return err
+	}
+	if err := repos.Outbox.Create(event); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// RecordOrderEvent บันทึก Event ของ Order พร้อม Snapshot ของ Order ณ ตอนนั้น
+func RecordOrderEvent(repos *datastore.Repositories, eventType string, order *domain.Order, previous domain.OrderStatus, reason string) error {
+	payload := OrderPayload{
+		OrderID:        order.ID,
+		OrderNumber:    orderNumber(order),
+		UserID:         order.UserID,
+		Status:         order.Status,
+		PreviousStatus: previous,
+		TotalPrice:     order.TotalPrice,
+		Discount:       order.Discount,
+		ShippingCost:   order.ShippingCost,
+		GiftWrapPrice:  order.GiftWrapPrice,
+		TaxAmount:      order.TaxAmount,
+		FinalPrice:     order.FinalPrice,
+		RefundedAmount: order.RefundedAmount,
+		BalanceDue:     order.BalanceDue,
+		Currency:       "THB",
+		PaidAt:         order.PaidAt,
+		Reason:         reason,
+		Items:          make([]OrderItemPayload, 0, len(order.OrderItems)),
+	}
+	if order.PaymentMethod != nil {
+		payload.PaymentMethod = *order.PaymentMethod
+	}
+	for _, item := range order.OrderItems {
+		payload.Items = append(payload.Items, OrderItemPayload{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			SKU:         item.ProductSKU,
+			Name:        item.ProductName,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+		})
+	}
+	return Record(repos, domain.AggregateOrder, order.ID, eventType, payload)
+}
+
+// RecordOrderRefunded บันทึก order.refunded สำหรับเงินที่คืนให้ลูกค้า 1 ครั้ง
+func RecordOrderRefunded(repos *datastore.Repositories, order *domain.Order, returnRequestID *uint, amount, taxAmount float64) error {
+	return Record(repos, domain.AggregateOrder, order.ID, domain.EventOrderRefunded, OrderRefundPayload{
+		OrderID:         order.ID,
+		OrderNumber:     orderNumber(order),
+		ReturnRequestID: returnRequestID,
+		Amount:          amount,
+		TaxAmount:       taxAmount,
+		Currency:        "THB",
+	})
+}
+
+// RecordShipmentCreated บันทึก shipment.created (Aggregate เป็น Order เพื่อให้เรียงต่อจาก Event อื่นของ Order เดียวกัน)
+func RecordShipmentCreated(repos *datastore.Repositories, order *domain.Order, shipment *domain.Shipment) error {
+	items := make(map[uint]domain.OrderItem, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		items[item.ID] = item
+	}
+
+	payload := ShipmentPayload{
+		ShipmentID:     shipment.ID,
+		OrderID:        order.ID,
+		OrderNumber:    orderNumber(order),
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		CODAmount:      shipment.CODAmount,
+		Items:          make([]ShipmentItemPayload, 0, len(shipment.Items)),
+	}
+	for _, item := range shipment.Items {
+		orderItem := items[item.OrderItemID]
+		payload.Items = append(payload.Items, ShipmentItemPayload{
+			OrderItemID: item.OrderItemID,
+			ProductID:   orderItem.ProductID,
+			SKU:         orderItem.ProductSKU,
+			Quantity:    item.Quantity,
+		})
+	}
+	return Record(repos, domain.AggregateOrder, order.ID, domain.EventShipmentCreated, payload)
+}
+
+// RecordPaymentRefunded บันทึก payment.refunded หลังคืนเงินผ่าน Gateway สำเร็จ
+func RecordPaymentRefunded(repos *datastore.Repositories, payment *domain.Payment, amount float64) error {
+	return Record(repos, domain.AggregatePayment, payment.ID, domain.EventPaymentRefunded, PaymentRefundPayload{
+		PaymentID:      payment.ID,
+		OrderID:        payment.OrderID,
+		Provider:       payment.Provider,
+		Amount:         amount,
+		RefundedAmount: payment.RefundedAmount,
+		Status:         payment.Status,
+		Reason:         payment.RefundReason,
+		Currency:       payment.Currency,
+	})
+}
+
+// RecordStockChanged บันทึก inventory.stock_changed หลังสต็อกถูกเปลี่ยนใน Transaction เดียวกัน
+// delta เป็นบวกเมื่อสต็อกเพิ่ม ติดลบเมื่อสต็อกลด orderID เป็น 0 ถ้าไม่เกี่ยวกับ Order
+func RecordStockChanged(repos *datastore.Repositories, productID uint, delta int, reason string, orderID uint) error {
+	payload := StockChangedPayload{
+		ProductID: productID,
+		Delta:     delta,
+		Reason:    reason,
+	}
+	if orderID != 0 {
+		payload.OrderID = &orderID
+	}
+
+	// แถวของสินค้าถูก Lock ไว้แล้วจากการ UPDATE สต็อก ค่าที่อ่านได้จึงเป็นค่าหลังเปลี่ยนที่แน่นอน
+	product, err := repos.Product.FindByID(productID)
+	switch {
+	case err == nil:
+		payload.SKU = product.SKU
+		payload.Quantity = &product.Quantity
+	case !errors.Is(err, productRepo.ErrNotFound):
+		return fmt.Errorf("failed to read stock of product %d: %w", productID, err)
+	}
+	return Record(repos, domain.AggregateProduct, productID, domain.EventInventoryStockChange, payload)
+}
+
+func orderNumber(order *domain.Order) string {
+	if order.OrderNumber == nil {
+		return ""
+	}
+	return *order.OrderNumber
+}
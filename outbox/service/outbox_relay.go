@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartOutboxRelay รัน RelayPending ทุกๆ interval ใน Background จนกว่า ctx จะถูกยกเลิก
+// และลบ Event ที่ส่งสำเร็จนานกว่า retention ออกไปด้วย (retention = 0 คือเก็บไว้ตลอด)
+func StartOutboxRelay(ctx context.Context, outboxSvc OutboxService, interval, retention time.Duration) {
+	if interval <= 0 {
+		log.Println("Outbox relay is disabled.")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := outboxSvc.RelayPending(ctx)
+				if err != nil {
+					log.Printf("ERROR: outbox relay run failed after %d events: %v", count, err)
+				} else if count > 0 {
+					log.Printf("Outbox relay: published %d event(s)", count)
+				}
+
+				if retention > 0 {
+					if _, err := outboxSvc.PurgePublished(retention); err != nil {
+						log.Printf("ERROR: failed to purge published outbox events: %v", err)
+					}
+				}
+			}
+		}
+	}()
+	log.Printf("✅ Outbox relay started (every %s).", interval)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	"backend/outbox/dto"
+	"backend/outbox/publisher"
+	"backend/outbox/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrOutboxEventNotFound  = errors.New("outbox event not found")
+	ErrOutboxEventPublished = errors.New("outbox event has already been published")
+	ErrOutboxEventInFlight  = errors.New("outbox event is being published by the relay")
+)
+
+const (
+	relayBatchSize     = 100              // จำนวน Event สูงสุดที่จองและส่งต่อ 1 Batch
+	relayMaxBatches    = 10               // จำนวน Batch สูงสุดต่อการทำงาน 1 รอบ (กันรอบเดียวทำงานนานเกินไป)
+	maxPublishAttempts = 10               // ส่งไม่สำเร็จครบจำนวนนี้จะถูกพักไว้เป็น failed
+	retryBaseDelay     = 30 * time.Second // ระยะรอก่อนส่งใหม่ครั้งแรก แล้วเพิ่มเป็นเท่าตัวทุกครั้ง
+	retryMaxDelay      = time.Hour
+	maxLastErrorLength = 500
+	publishTimeout     = 15 * time.Second
+	claimLease         = 30 * time.Minute // เวลาที่ Relay จอง Event ไว้ ต้องนานกว่าการส่งทั้ง Batch (relayBatchSize × publishTimeout)
+)
+
+type OutboxService interface {
+	ListEvents(params dto.OutboxQueryParams) (*dto.PaginatedOutboxEventsDTO, error)
+	RetryEvent(id uint) (*dto.OutboxEventResponse, error)
+	RelayPending(ctx context.Context) (int, error)
+	PurgePublished(retention time.Duration) (int64, error)
+}
+
+type outboxService struct {
+	uow       datastore.UnitOfWork
+	publisher publisher.Publisher
+}
+
+func NewOutboxService(uow datastore.UnitOfWork, pub publisher.Publisher) OutboxService {
+	return &outboxService{uow: uow, publisher: pub}
+}
+
+// ListEvents ดึงรายการ Event สำหรับ Admin ตรวจสอบ (เช่น ดู Event ที่ส่งไม่สำเร็จ)
+func (s *outboxService) ListEvents(params dto.OutboxQueryParams) (*dto.PaginatedOutboxEventsDTO, error) {
+	events, total, err := s.uow.OutboxRepository().FindAll(params)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.OutboxEventResponse, 0, len(events))
+	for i := range events {
+		data = append(data, mapEventToResponse(&events[i]))
+	}
+	return &dto.PaginatedOutboxEventsDTO{
+		Data:        data,
+		TotalItems:  total,
+		TotalPages:  int(math.Ceil(float64(total) / float64(params.Limit))),
+		CurrentPage: params.Page,
+		Limit:       params.Limit,
+	}, nil
+}
+
+// RetryEvent สั่งให้ Event ที่ยังไม่ถูกส่ง (รวมถึงที่ถูกพักเป็น failed) ถูกส่งใหม่ในรอบถัดไปของ Relay ทันที
+func (s *outboxService) RetryEvent(id uint) (*dto.OutboxEventResponse, error) {
+	var event *domain.OutboxEvent
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		event, err = repos.Outbox.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if event.Status == domain.OutboxPublished {
+			return ErrOutboxEventPublished
+		}
+		if event.Status == domain.OutboxProcessing && event.LockedUntil != nil && event.LockedUntil.After(time.Now().UTC()) {
+			return ErrOutboxEventInFlight
+		}
+		event.Status = domain.OutboxPending
+		event.LockedUntil = nil
+		event.Attempts = 0
+		event.NextAttemptAt = time.Now().UTC()
+		return repos.Outbox.Update(event)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrOutboxEventNotFound) {
+			return nil, ErrOutboxEventNotFound
+		}
+		return nil, err
+	}
+	response := mapEventToResponse(event)
+	return &response, nil
+}
+
+// RelayPending ส่ง Event ที่ถึงเวลาออกไปผ่าน Publisher ตามลำดับที่บันทึก คืนค่าจำนวน Event ที่ส่งสำเร็จ
+// ขั้นตอน: จอง Event ใน Transaction สั้นๆ (processing + LockedUntil) แล้วส่งนอก Transaction จากนั้นบันทึกผลทีละรายการ
+// จึงไม่ถือ Lock ของ Database ไว้ระหว่างรอปลายทาง และรันพร้อมกันหลาย Instance ได้
+// สถานะ published ถูกบันทึกหลังปลายทางรับแล้วเท่านั้น ถ้าระบบล่มระหว่างนั้น Event จะถูกจองใหม่เมื่อหมดเวลาจองและส่งซ้ำ (At-least-once)
+func (s *outboxService) RelayPending(ctx context.Context) (int, error) {
+	published := 0
+	for batch := 0; batch < relayMaxBatches; batch++ {
+		var events []domain.OutboxEvent
+		err := s.uow.Execute(func(repos *datastore.Repositories) error {
+			var err error
+			events, err = repos.Outbox.ClaimDue(time.Now().UTC(), claimLease, relayBatchSize)
+			return err
+		})
+		if err != nil {
+			return published, err
+		}
+
+		sent, err := s.publishBatch(ctx, events)
+		published += sent
+		if err != nil {
+			return published, err
+		}
+		if len(events) < relayBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	return published, nil
+}
+
+// publishBatch ส่ง Event ที่จองไว้ทีละรายการแล้วบันทึกผล
+// ถ้า Event ของ Aggregate ใดส่งไม่สำเร็จ Event ถัดไปของ Aggregate เดียวกันจะถูกเลื่อนไปพร้อมกัน
+// เพื่อให้ปลายทางได้รับ Event ของ Order หรือสินค้าเดียวกันตามลำดับที่เกิดขึ้น
+func (s *outboxService) publishBatch(ctx context.Context, events []domain.OutboxEvent) (int, error) {
+	repo := s.uow.OutboxRepository()
+	blocked := make(map[string]time.Time)
+	sent := 0
+	for i := range events {
+		event := &events[i]
+		key := fmt.Sprintf("%s:%d", event.AggregateType, event.AggregateID)
+
+		if until, ok := blocked[key]; ok {
+			event.Status = domain.OutboxPending
+			event.NextAttemptAt = until
+		} else if err := s.publish(ctx, event); err != nil {
+			event.Attempts++
+			event.LastError = truncate(err.Error(), maxLastErrorLength)
+			event.NextAttemptAt = time.Now().UTC().Add(retryDelay(event.Attempts))
+			event.Status = domain.OutboxPending
+			if event.Attempts >= maxPublishAttempts {
+				event.Status = domain.OutboxFailed
+			}
+			blocked[key] = event.NextAttemptAt
+		} else {
+			now := time.Now().UTC()
+			event.Status = domain.OutboxPublished
+			event.PublishedAt = &now
+			event.LastError = ""
+			sent++
+		}
+
+		if err := repo.Acknowledge(event); err != nil {
+			return sent, fmt.Errorf("failed to update outbox event %d: %w", event.ID, err)
+		}
+	}
+	return sent, nil
+}
+
+func (s *outboxService) publish(ctx context.Context, event *domain.OutboxEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+	return s.publisher.Publish(ctx, event)
+}
+
+// PurgePublished ลบ Event ที่ส่งสำเร็จนานกว่า retention ออก
+func (s *outboxService) PurgePublished(retention time.Duration) (int64, error) {
+	return s.uow.OutboxRepository().DeletePublishedBefore(time.Now().UTC().Add(-retention))
+}
+
+// retryDelay คำนวณระยะรอแบบ Exponential Backoff: 30s, 1m, 2m, 4m, ... สูงสุด 1 ชั่วโมง
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+func mapEventToResponse(event *domain.OutboxEvent) dto.OutboxEventResponse {
+	return dto.OutboxEventResponse{
+		ID:            event.ID,
+		EventID:       event.EventID,
+		EventType:     event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       event.Payload,
+		OccurredAt:    event.OccurredAt,
+		Status:        event.Status,
+		Attempts:      event.Attempts,
+		NextAttemptAt: event.NextAttemptAt,
+		LastError:     event.LastError,
+		LockedUntil:   event.LockedUntil,
+		PublishedAt:   event.PublishedAt,
+	}
+}
@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"backend/domain"
+	"time"
+)
+
+// CreateIntentRequest คือ DTO สำหรับเริ่มการชำระเงินของ Order
+type CreateIntentRequest struct {
+	Method string `json:"method" validate:"required,oneof=credit_card bank_transfer"`
+}
+
+// RefundRequest คือ DTO สำหรับคืนเงิน ถ้าไม่ระบุ Amount (หรือเป็น 0) จะคืนยอดที่เหลือทั้งหมด
+type RefundRequest struct {
+	Amount float64 `json:"amount" validate:"gte=0"`
+	Reason string  `json:"reason" validate:"max=255"`
+}
+
+// PaymentResponse คือ DTO สำหรับแสดงข้อมูลการชำระเงิน
+type PaymentResponse struct {
+	ID                uint                 `json:"id"`
+	OrderID           uint                 `json:"order_id"`
+	Provider          string               `json:"provider"`
+	ProviderPaymentID string               `json:"provider_payment_id"`
+	Method            string               `json:"method"`
+	Amount            float64              `json:"amount"`
+	Currency          string               `json:"currency"`
+	Status            domain.PaymentStatus `json:"status"`
+	RefundedAmount    float64              `json:"refunded_amount"`
+	FailureReason     string               `json:"failure_reason,omitempty"`
+	RefundReason      string               `json:"refund_reason,omitempty"`
+	CapturedAt        *time.Time           `json:"captured_at,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+}
+
+// PromptPayQRResponse คือ DTO สำหรับ QR Code PromptPay ของ Order
+type PromptPayQRResponse struct {
+	OrderID   uint    `json:"order_id"`
+	Amount    float64 `json:"amount"`
+	Reference string  `json:"reference"`   // เลขอ้างอิงที่ใช้กระทบยอด
+	Payload   string  `json:"payload"`     // ข้อความ EMVCo สำหรับสร้าง QR เอง
+	QRCodePNG []byte  `json:"qr_code_png"` // รูป PNG (JSON จะเข้ารหัสเป็น Base64)
+}
+
+// BankSlip คือข้อมูลเงินโอน 1 รายการจาก Statement หรือสลิปธนาคาร
+type BankSlip struct {
+	BankReference string    `json:"bank_reference" validate:"required,max=100"` // เลขที่รายการของธนาคาร (ห้ามซ้ำ)
+	Reference     string    `json:"reference" validate:"max=25"`                // เลขอ้างอิงที่ลูกค้าโอนมา (ถ้ามี)
+	Amount        float64   `json:"amount" validate:"required,gt=0"`
+	TransferredAt time.Time `json:"transferred_at"`
+}
+
+// ReconcileRequest คือ DTO สำหรับอัปโหลดข้อมูลเงินโอนเพื่อกระทบยอดกับ Order ที่รอชำระ
+type ReconcileRequest struct {
+	Slips []BankSlip `json:"slips" validate:"required,min=1,dive"`
+}
+
+// SlipMatchResult คือผลการกระทบยอดของสลิปแต่ละรายการ
+type SlipMatchResult struct {
+	BankReference string  `json:"bank_reference"`
+	Reference     string  `json:"reference,omitempty"`
+	Amount        float64 `json:"amount"`
+	OrderID       uint    `json:"order_id,omitempty"`
+	Result        string  `json:"result"` // matched, already_recorded, amount_mismatch, order_not_pending, ambiguous, unmatched, error
+	Note          string  `json:"note,omitempty"`
+}
+
+// ReconcileResponse คือสรุปผลการกระทบยอดทั้งหมด
+type ReconcileResponse struct {
+	Matched   int               `json:"matched"`
+	Unmatched int               `json:"unmatched"`
+	Results   []SlipMatchResult `json:"results"`
+}
+
+// CODRemittanceResult คือผลการกระทบยอดเงินเก็บปลายทาง 1 แถวในไฟล์ของขนส่ง
+type CODRemittanceResult struct {
+	Line           int     `json:"line"` // บรรทัดในไฟล์ (นับหัวคอลัมน์เป็นบรรทัดที่ 1)
+	TrackingNumber string  `json:"tracking_number"`
+	Amount         float64 `json:"amount"`
+	ExpectedAmount float64 `json:"expected_amount,omitempty"`
+	OrderID        uint    `json:"order_id,omitempty"`
+	ShipmentID     uint    `json:"shipment_id,omitempty"`
+	Result         string  `json:"result"` // matched, already_recorded, amount_mismatch, not_cod, unmatched, invalid_row, error
+	Note           string  `json:"note,omitempty"`
+}
+
+// CODRemittanceResponse คือสรุปผลการนำเข้าไฟล์โอนเงินเก็บปลายทาง (Flagged = แถวที่ต้องให้ Admin ตรวจสอบ)
+type CODRemittanceResponse struct {
+	Carrier    string                `json:"carrier"`
+	Matched    int                   `json:"matched"`
+	Flagged    int                   `json:"flagged"`
+	OrdersPaid []uint                `json:"orders_paid"` // Order ที่ได้รับเงินครบจากไฟล์นี้
+	Results    []CODRemittanceResult `json:"results"`
+}
+
+// CODShipmentResponse คือพัสดุเก็บเงินปลายทางพร้อมสถานะการโอนเงินจากขนส่ง
+type CODShipmentResponse struct {
+	ShipmentID     uint                  `json:"shipment_id"`
+	OrderID        uint                  `json:"order_id"`
+	Carrier        string                `json:"carrier"`
+	TrackingNumber string                `json:"tracking_number"`
+	ShipmentStatus domain.ShipmentStatus `json:"shipment_status"`
+	CODAmount      float64               `json:"cod_amount"`
+	CODStatus      domain.CODStatus      `json:"cod_status"`
+	RemittedAmount float64               `json:"remitted_amount"`
+	RemittedAt     *time.Time            `json:"remitted_at,omitempty"`
+	RemittanceRef  string                `json:"remittance_ref,omitempty"`
+}
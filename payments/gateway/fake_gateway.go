@@ -0,0 +1,128 @@
+// payments/gateway/fake_gateway.go
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FakeGateway คือ PaymentGateway ที่ทำงานใน Process ทั้งหมด ไม่ต้องต่อบริการภายนอก
+// ใช้สำหรับ Local Dev และการทดสอบ ผลลัพธ์ Deterministic เสมอ (ภายใต้ prefix เดียวกัน):
+//   - Intent ID เรียงลำดับ fake_pi_<prefix>_000001, fake_pi_<prefix>_000002, ...
+//     การทดสอบกำหนด prefix เองได้ ส่วน Dev Server ใช้ prefix สุ่ม (ดู New) กัน ID ชนกับ Payment เดิมหลัง Restart
+//   - Capture สำเร็จทุกครั้ง ยกเว้น Decline คืนค่า true สำหรับ Request นั้น
+type FakeGateway struct {
+	// Decline (ไม่บังคับ) ใช้กำหนดว่า Intent ไหนควรถูกปฏิเสธตอน Capture
+	Decline func(req IntentRequest) bool
+
+	mu      sync.Mutex
+	prefix  string
+	seq     int
+	intents map[string]*fakeIntent
+}
+
+type fakeIntent struct {
+	request IntentRequest
+	intent  Intent
+}
+
+// NewFakeGatewayWithPrefix สร้าง FakeGateway ที่ออก Intent ID ด้วย prefix ที่กำหนด
+func NewFakeGatewayWithPrefix(prefix string) *FakeGateway {
+	return &FakeGateway{prefix: prefix, intents: make(map[string]*fakeIntent)}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.seq++
+	id := fmt.Sprintf("fake_pi_%s_%06d", g.prefix, g.seq)
+	g.intents[id] = &fakeIntent{
+		request: req,
+		intent:  Intent{ID: id, Status: IntentRequiresCapture, Amount: req.Amount},
+	}
+	result := g.intents[id].intent
+	return &result, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, intentID string) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fi, ok := g.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if fi.intent.Status != IntentRequiresCapture {
+		return nil, fmt.Errorf("%w: intent is '%s'", ErrInvalidState, fi.intent.Status)
+	}
+
+	if g.Decline != nil && g.Decline(fi.request) {
+		fi.intent.Status = IntentFailed
+		fi.intent.FailureReason = "declined by fake gateway"
+		result := fi.intent
+		return &result, ErrPaymentDeclined
+	}
+
+	fi.intent.Status = IntentSucceeded
+	result := fi.intent
+	return &result, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, intentID string, amount float64) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fi, ok := g.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if fi.intent.Status != IntentSucceeded && fi.intent.Status != IntentPartiallyRefunded {
+		return nil, fmt.Errorf("%w: intent is '%s'", ErrInvalidState, fi.intent.Status)
+	}
+	if amount <= 0 || fi.intent.RefundedAmount+amount > fi.intent.Amount {
+		return nil, ErrRefundTooLarge
+	}
+
+	fi.intent.RefundedAmount += amount
+	if fi.intent.RefundedAmount >= fi.intent.Amount {
+		fi.intent.Status = IntentRefunded
+	} else {
+		fi.intent.Status = IntentPartiallyRefunded
+	}
+	result := fi.intent
+	return &result, nil
+}
+
+func (g *FakeGateway) Cancel(ctx context.Context, intentID string) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fi, ok := g.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if fi.intent.Status != IntentRequiresCapture && fi.intent.Status != IntentCanceled {
+		return nil, fmt.Errorf("%w: intent is '%s'", ErrInvalidState, fi.intent.Status)
+	}
+	fi.intent.Status = IntentCanceled
+	result := fi.intent
+	return &result, nil
+}
+
+func (g *FakeGateway) GetStatus(ctx context.Context, intentID string) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fi, ok := g.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	result := fi.intent
+	return &result, nil
+}
@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeGatewayCaptureAndRefund(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGatewayWithPrefix("test")
+
+	intent, err := g.CreateIntent(ctx, IntentRequest{Amount: 100})
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	if intent.Status != IntentRequiresCapture {
+		t.Fatalf("new intent status = %s, want %s", intent.Status, IntentRequiresCapture)
+	}
+
+	if _, err := g.Refund(ctx, intent.ID, 10); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("refund before capture: got %v, want ErrInvalidState", err)
+	}
+
+	captured, err := g.Capture(ctx, intent.ID)
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if captured.Status != IntentSucceeded {
+		t.Fatalf("captured status = %s, want %s", captured.Status, IntentSucceeded)
+	}
+	if _, err := g.Capture(ctx, intent.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("second capture: got %v, want ErrInvalidState", err)
+	}
+
+	partial, err := g.Refund(ctx, intent.ID, 40)
+	if err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	if partial.Status != IntentPartiallyRefunded || partial.RefundedAmount != 40 {
+		t.Fatalf("after partial refund: status=%s refunded=%.2f", partial.Status, partial.RefundedAmount)
+	}
+
+	if _, err := g.Refund(ctx, intent.ID, 60.01); !errors.Is(err, ErrRefundTooLarge) {
+		t.Fatalf("refund over captured amount: got %v, want ErrRefundTooLarge", err)
+	}
+	if _, err := g.Refund(ctx, intent.ID, 0); !errors.Is(err, ErrRefundTooLarge) {
+		t.Fatalf("zero refund: got %v, want ErrRefundTooLarge", err)
+	}
+
+	full, err := g.Refund(ctx, intent.ID, 60)
+	if err != nil {
+		t.Fatalf("remaining refund: %v", err)
+	}
+	if full.Status != IntentRefunded || full.RefundedAmount != 100 {
+		t.Fatalf("after full refund: status=%s refunded=%.2f", full.Status, full.RefundedAmount)
+	}
+
+	status, err := g.GetStatus(ctx, intent.ID)
+	if err != nil {
+		t.Fatalf("GetStatus: %v", err)
+	}
+	if status.Status != IntentRefunded {
+		t.Fatalf("GetStatus = %s, want %s", status.Status, IntentRefunded)
+	}
+}
+
+func TestFakeGatewayDecline(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGatewayWithPrefix("test")
+	g.Decline = func(req IntentRequest) bool { return req.Amount > 1000 }
+
+	intent, err := g.CreateIntent(ctx, IntentRequest{Amount: 5000})
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	result, err := g.Capture(ctx, intent.ID)
+	if !errors.Is(err, ErrPaymentDeclined) {
+		t.Fatalf("Capture: got %v, want ErrPaymentDeclined", err)
+	}
+	if result == nil || result.Status != IntentFailed {
+		t.Fatalf("declined intent = %+v, want status %s", result, IntentFailed)
+	}
+}
+
+func TestFakeGatewayUnknownIntent(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGatewayWithPrefix("test")
+
+	if _, err := g.Capture(ctx, "fake_pi_missing"); !errors.Is(err, ErrIntentNotFound) {
+		t.Errorf("Capture: got %v, want ErrIntentNotFound", err)
+	}
+	if _, err := g.Refund(ctx, "fake_pi_missing", 1); !errors.Is(err, ErrIntentNotFound) {
+		t.Errorf("Refund: got %v, want ErrIntentNotFound", err)
+	}
+	if _, err := g.GetStatus(ctx, "fake_pi_missing"); !errors.Is(err, ErrIntentNotFound) {
+		t.Errorf("GetStatus: got %v, want ErrIntentNotFound", err)
+	}
+	if _, err := g.Cancel(ctx, "fake_pi_missing"); !errors.Is(err, ErrIntentNotFound) {
+		t.Errorf("Cancel: got %v, want ErrIntentNotFound", err)
+	}
+}
+
+func TestFakeGatewayCancel(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGatewayWithPrefix("test")
+
+	intent, err := g.CreateIntent(ctx, IntentRequest{Amount: 100})
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	canceled, err := g.Cancel(ctx, intent.ID)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if canceled.Status != IntentCanceled {
+		t.Fatalf("canceled status = %s, want %s", canceled.Status, IntentCanceled)
+	}
+	if _, err := g.Cancel(ctx, intent.ID); err != nil {
+		t.Fatalf("second cancel should be a no-op, got %v", err)
+	}
+	if _, err := g.Capture(ctx, intent.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("capture after cancel: got %v, want ErrInvalidState", err)
+	}
+
+	captured, err := g.CreateIntent(ctx, IntentRequest{Amount: 100})
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	if _, err := g.Capture(ctx, captured.ID); err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if _, err := g.Cancel(ctx, captured.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("cancel after capture: got %v, want ErrInvalidState", err)
+	}
+}
+
+func TestFakeGatewayIntentIDsAreDeterministic(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGatewayWithPrefix("test")
+	for _, want := range []string{"fake_pi_test_000001", "fake_pi_test_000002"} {
+		intent, err := g.CreateIntent(ctx, IntentRequest{Amount: 1})
+		if err != nil {
+			t.Fatalf("CreateIntent: %v", err)
+		}
+		if intent.ID != want {
+			t.Fatalf("intent id = %s, want %s", intent.ID, want)
+		}
+	}
+}
+
+// Dev Server ที่ Restart ต้องไม่ออก Intent ID ซ้ำกับรอบก่อน
+func TestBootPrefixDiffersAcrossRestarts(t *testing.T) {
+	if a, b := bootPrefix(), bootPrefix(); a == b {
+		t.Fatalf("bootPrefix returned %s twice", a)
+	}
+}
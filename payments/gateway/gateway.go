@@ -0,0 +1,101 @@
+// payments/gateway/gateway.go
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrIntentNotFound  = errors.New("payment intent not found")
+	ErrInvalidState    = errors.New("payment intent is not in a valid state for this operation")
+	ErrRefundTooLarge  = errors.New("refund amount exceeds the captured amount")
+	ErrPaymentDeclined = errors.New("payment was declined by the gateway")
+)
+
+// IntentStatus คือสถานะของ Payment Intent ฝั่ง Gateway
+type IntentStatus string
+
+const (
+	IntentRequiresCapture   IntentStatus = "requires_capture"
+	IntentSucceeded         IntentStatus = "succeeded"
+	IntentFailed            IntentStatus = "failed"
+	IntentPartiallyRefunded IntentStatus = "partially_refunded"
+	IntentRefunded          IntentStatus = "refunded"
+	IntentCanceled          IntentStatus = "canceled" // ยกเลิกก่อนตัดเงิน Capture ไม่ได้อีก
+)
+
+// IntentRequest คือข้อมูลที่ใช้สร้าง Payment Intent
+type IntentRequest struct {
+	OrderID  uint
+	Amount   float64
+	Currency string
+	Method   string
+}
+
+// Intent คือสถานะล่าสุดของการชำระเงินตามที่ Gateway รายงาน
+type Intent struct {
+	ID             string
+	Status         IntentStatus
+	Amount         float64
+	RefundedAmount float64
+	FailureReason  string
+}
+
+// PaymentGateway คือ Interface กลางของผู้ให้บริการรับชำระเงิน
+// Service จะเรียกผ่าน Interface นี้เท่านั้น ทำให้สลับผู้ให้บริการจริงกับ FakeGateway ได้
+type PaymentGateway interface {
+	// Name คือชื่อผู้ให้บริการ จะถูกบันทึกไว้ใน domain.Payment.Provider
+	Name() string
+	// CreateIntent สร้างรายการชำระเงินที่ยังไม่ได้ตัดเงิน
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Capture ตัดเงินจริง ถ้าถูกปฏิเสธจะคืน Intent ที่สถานะ failed พร้อม ErrPaymentDeclined
+	Capture(ctx context.Context, intentID string) (*Intent, error)
+	// Refund คืนเงินบางส่วนหรือทั้งหมดของรายการที่ตัดเงินแล้ว
+	Refund(ctx context.Context, intentID string, amount float64) (*Intent, error)
+	// Cancel ยกเลิกรายการที่ยังไม่ได้ตัดเงิน หลังจากนี้ Capture ไม่ได้อีก (ยกเลิกซ้ำได้)
+	Cancel(ctx context.Context, intentID string) (*Intent, error)
+	// GetStatus ดึงสถานะล่าสุดจาก Gateway
+	GetStatus(ctx context.Context, intentID string) (*Intent, error)
+}
+
+var (
+	ErrUnknownProvider       = errors.New("unknown payment provider")
+	ErrProviderNotConfigured = errors.New("payment provider is not configured, set PAYMENT_PROVIDER")
+)
+
+var (
+	sharedFake     *FakeGateway
+	sharedFakeOnce sync.Once
+)
+
+// New คืน PaymentGateway ตามชื่อผู้ให้บริการใน Config
+// FakeGateway ตัดเงินสำเร็จทุกครั้ง จึงต้องระบุ "fake" เองอย่างชัดเจน ค่าว่างถือเป็น Error เพื่อกันการเปิดใช้บน Production โดยไม่ตั้งใจ
+// FakeGateway เก็บรายการไว้ใน Memory จึงคืน Instance เดียวกันทุกครั้ง
+// เพื่อให้ทุก Module (เช่น payments และ returns) เห็นรายการชำระเงินชุดเดียวกัน
+// prefix ของ Intent ID สุ่มใหม่ทุกครั้งที่เริ่ม Process กัน ID ชนกับ Payment ใน Database จากรอบก่อน
+func New(provider string) (PaymentGateway, error) {
+	switch provider {
+	case "":
+		return nil, ErrProviderNotConfigured
+	case "fake":
+		sharedFakeOnce.Do(func() { sharedFake = NewFakeGatewayWithPrefix(bootPrefix()) })
+		return sharedFake, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, provider)
+	}
+}
+
+// bootPrefix สุ่ม prefix 8 ตัวอักษร (hex) สำหรับ FakeGateway ของ Process นี้ ถ้าสุ่มไม่ได้จะใช้เวลาที่เริ่มแทน
+func bootPrefix() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", uint32(time.Now().UnixNano()))
+	}
+	return hex.EncodeToString(b)
+}
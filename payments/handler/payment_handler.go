@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"backend/domain"
+	"backend/middleware"
+	"backend/payments/dto"
+	"backend/payments/service"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentHandler struct {
+	paymentSvc service.PaymentService
+}
+
+func NewPaymentHandler(paymentSvc service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentSvc: paymentSvc}
+}
+
+// HandleCreateIntent เริ่มการชำระเงินของ Order ที่ยังรอชำระ
+func (h *PaymentHandler) HandleCreateIntent(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("orderId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var req dto.CreateIntentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	payment, err := h.paymentSvc.CreateIntent(c.UserContext(), claims.UserID, uint(orderID), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(payment)
+}
+
+// HandleCapture ตัดเงินของ Payment ที่สร้างไว้
+func (h *PaymentHandler) HandleCapture(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	paymentID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid payment ID")
+	}
+
+	payment, err := h.paymentSvc.Capture(c.UserContext(), claims.UserID, uint(paymentID))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(payment)
+}
+
+// HandleGetPayment ดูสถานะล่าสุดของ Payment
+func (h *PaymentHandler) HandleGetPayment(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	paymentID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid payment ID")
+	}
+
+	payment, err := h.paymentSvc.GetPayment(c.UserContext(), claims.UserID, uint(paymentID))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(payment)
+}
+
+// HandleRefund คืนเงินบางส่วนหรือทั้งหมด (สำหรับ Admin)
+func (h *PaymentHandler) HandleRefund(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	paymentID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid payment ID")
+	}
+
+	var req dto.RefundRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	payment, err := h.paymentSvc.Refund(c.UserContext(), claims.UserID, uint(paymentID), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(payment)
+}
+
+// HandleGetPromptPayQR สร้าง QR Code PromptPay ของ Order (JSON พร้อมรูปแบบ Base64)
+func (h *PaymentHandler) HandleGetPromptPayQR(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("orderId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	qr, err := h.paymentSvc.GetPromptPayQR(claims.UserID, uint(orderID))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(qr)
+}
+
+// HandleGetPromptPayQRImage ส่งรูป QR Code PromptPay ของ Order เป็น PNG โดยตรง
+func (h *PaymentHandler) HandleGetPromptPayQRImage(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	orderID, err := c.ParamsInt("orderId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	qr, err := h.paymentSvc.GetPromptPayQR(claims.UserID, uint(orderID))
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Status(fiber.StatusOK).Send(qr.QRCodePNG)
+}
+
+// HandleReconcilePromptPay รับข้อมูลเงินโอนจากธนาคารเพื่อกระทบยอดกับ Order ที่รอชำระ (สำหรับ Admin)
+func (h *PaymentHandler) HandleReconcilePromptPay(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	var req dto.ReconcileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	result, err := h.paymentSvc.ReconcilePromptPay(claims.UserID, req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// HandleImportCODRemittance รับไฟล์ CSV เงินเก็บปลายทางที่ขนส่งโอนคืน (Multipart: "file" และ "carrier") เพื่อกระทบยอดกับพัสดุ (สำหรับ Admin)
+func (h *PaymentHandler) HandleImportCODRemittance(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	carrier := strings.TrimSpace(c.FormValue("carrier"))
+	if carrier == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Missing form field 'carrier'")
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Missing remittance file 'file'")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Cannot process uploaded file: "+fileHeader.Filename)
+	}
+	defer file.Close()
+
+	result, err := h.paymentSvc.ImportCODRemittance(claims.UserID, carrier, file)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// HandleGetCODShipments ดูพัสดุเก็บเงินปลายทาง กรองด้วย ?status=pending|remitted|mismatch|void ได้ (สำหรับ Admin)
+func (h *PaymentHandler) HandleGetCODShipments(c *fiber.Ctx) error {
+	status := domain.CODStatus(c.Query("status"))
+	switch status {
+	case "", domain.CODPending, domain.CODRemitted, domain.CODMismatch, domain.CODVoid:
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid COD status")
+	}
+
+	shipments, err := h.paymentSvc.GetCODShipments(status)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(shipments)
+}
@@ -0,0 +1,39 @@
+package payments
+
+import (
+	"backend/config"
+	"backend/internal/datastore"
+	"backend/middleware"
+	"backend/payments/gateway"
+	"backend/payments/handler"
+	"backend/payments/service"
+
+	"github.com/gofiber/fiber/v2"
+	"log"
+)
+
+func RegisterModule(api fiber.Router, uow datastore.UnitOfWork, cfg *config.Config) {
+	// เลือก Payment Gateway ตาม Config (ตอนนี้มีแค่ Fake สำหรับ Local Dev และการทดสอบ)
+	gw, err := gateway.New(cfg.PaymentProvider)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	paymentSvc := service.NewPaymentService(uow, gw, cfg.PromptPayID)
+	paymentHdl := handler.NewPaymentHandler(paymentSvc)
+
+	paymentAPI := api.Group("/payments", middleware.Protected())
+	paymentAPI.Post("/orders/:orderId/intent", paymentHdl.HandleCreateIntent)
+	paymentAPI.Post("/:id/capture", paymentHdl.HandleCapture)
+	paymentAPI.Get("/:id", paymentHdl.HandleGetPayment)
+	paymentAPI.Get("/orders/:orderId/promptpay", paymentHdl.HandleGetPromptPayQR)
+	paymentAPI.Get("/orders/:orderId/promptpay.png", paymentHdl.HandleGetPromptPayQRImage)
+
+	adminPaymentAPI := paymentAPI.Group("", middleware.AdminRequired())
+	adminPaymentAPI.Post("/:id/refund", paymentHdl.HandleRefund)
+	adminPaymentAPI.Post("/promptpay/reconcile", paymentHdl.HandleReconcilePromptPay)
+	adminPaymentAPI.Post("/cod/remittances", paymentHdl.HandleImportCODRemittance)
+	adminPaymentAPI.Get("/cod/shipments", paymentHdl.HandleGetCODShipments)
+
+	log.Printf("✅ Payment module registered successfully (provider: %s).", gw.Name())
+}
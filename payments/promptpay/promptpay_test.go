@@ -0,0 +1,52 @@
+package promptpay
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCRC16(t *testing.T) {
+	// ค่าตรวจสอบมาตรฐานของ CRC-16/CCITT-FALSE
+	if got := crc16("123456789"); got != 0x29B1 {
+		t.Fatalf("crc16(\"123456789\") = %04X, want 29B1", got)
+	}
+}
+
+func TestGeneratePayload(t *testing.T) {
+	payload, err := GeneratePayload("081-234-5678", 150.5, "ORD1")
+	if err != nil {
+		t.Fatalf("GeneratePayload: %v", err)
+	}
+
+	want := "000201" + "010212" +
+		"2937" + "0016A000000677010111" + "01130066812345678" +
+		"5303764" + "5406150.50" + "5802TH" + "62080504ORD1" + "6304"
+	if !strings.HasPrefix(payload, want) {
+		t.Fatalf("payload = %s, want prefix %s", payload, want)
+	}
+	body, checksum := payload[:len(payload)-4], payload[len(payload)-4:]
+	if expected := fmt.Sprintf("%04X", crc16(body)); checksum != expected {
+		t.Fatalf("checksum = %s, want %s", checksum, expected)
+	}
+}
+
+func TestFormatTarget(t *testing.T) {
+	cases := []struct {
+		id, tag, value string
+	}{
+		{"0812345678", "01", "0066812345678"},
+		{"1-2345-67890-12-3", "02", "1234567890123"},
+		{"123456789012345", "03", "123456789012345"},
+	}
+	for _, c := range cases {
+		tag, value, err := formatTarget(c.id)
+		if err != nil || tag != c.tag || value != c.value {
+			t.Errorf("formatTarget(%q) = %q, %q, %v; want %q, %q", c.id, tag, value, err, c.tag, c.value)
+		}
+	}
+	if _, _, err := formatTarget("12345"); !errors.Is(err, ErrInvalidPromptPayID) {
+		t.Errorf("formatTarget(\"12345\"): got %v, want ErrInvalidPromptPayID", err)
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrPaymentNotFound = errors.New("payment not found")
+
+type PaymentRepository interface {
+	Create(payment *domain.Payment) error
+	FindByID(id uint) (*domain.Payment, error)
+	FindByOrderID(orderID uint) ([]domain.Payment, error)
+	FindByProviderPaymentID(providerPaymentID string) (*domain.Payment, error)
+	Update(payment *domain.Payment) error
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func (r *paymentRepository) Create(payment *domain.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+// FindByID ค้นหา Payment พร้อมข้อมูล Order (ใช้ตรวจสอบเจ้าของและสถานะ Order)
+func (r *paymentRepository) FindByID(id uint) (*domain.Payment, error) {
+	var payment domain.Payment
+	err := r.db.Preload("Order").First(&payment, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *paymentRepository) FindByOrderID(orderID uint) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Where("order_id = ?", orderID).Order("created_at desc").Find(&payments).Error
+	return payments, err
+}
+
+func (r *paymentRepository) FindByProviderPaymentID(providerPaymentID string) (*domain.Payment, error) {
+	var payment domain.Payment
+	err := r.db.Where("provider_payment_id = ?", providerPaymentID).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Update บันทึกเฉพาะข้อมูล Payment โดยไม่แตะ Order ที่ Preload มา
+func (r *paymentRepository) Update(payment *domain.Payment) error {
+	return r.db.Omit("Order").Save(payment).Error
+}
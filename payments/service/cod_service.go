@@ -0,0 +1,310 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	invoiceService "backend/invoices/service"
+	orderRepo "backend/orders/repository"
+	outboxService "backend/outbox/service"
+	"backend/payments/dto"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRemittanceFile = errors.New("remittance file must be a CSV with tracking_number and amount columns")
+
+const (
+	codProvider            = domain.PaymentMethodCOD
+	remittanceMatched      = "matched"
+	remittanceAlreadyDone  = "already_recorded"
+	remittanceMismatch     = "amount_mismatch"
+	remittanceNotCOD       = "not_cod"
+	remittanceReturned     = "shipment_returned"
+	remittanceUnmatched    = "unmatched"
+	remittanceInvalidRow   = "invalid_row"
+	remittanceFailed       = "error"
+	remittanceMaxFileLines = 10000
+)
+
+// remittanceTimeLayouts คือรูปแบบวันเวลาที่รับได้ในคอลัมน์ remitted_at
+var remittanceTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// codRemittanceRow คือเงินที่ขนส่งโอนคืนสำหรับพัสดุ 1 กล่อง
+type codRemittanceRow struct {
+	line           int
+	trackingNumber string
+	amount         float64
+	remittedAt     time.Time
+	reference      string
+}
+
+// IsOfflineProvider คืนค่า true ถ้า Payment ของ Provider นี้ไม่ได้ผ่าน Payment Gateway (คืนเงินต้องโอนคืนเอง)
+func IsOfflineProvider(provider string) bool {
+	return provider == promptPayProvider || provider == codProvider
+}
+
+// ImportCODRemittance อ่านไฟล์ CSV ที่ขนส่งส่งมาพร้อมเงินเก็บปลายทาง แล้วกระทบยอดกับพัสดุทีละแถว
+// คอลัมน์ที่ต้องมี: tracking_number, amount ส่วน remitted_at และ reference ไม่บังคับ
+// แต่ละแถวทำใน Transaction ของตัวเอง ยอดไม่ตรงจะถูก Flag ไว้ที่พัสดุให้ Admin ตรวจสอบ
+func (s *paymentService) ImportCODRemittance(adminID uint, carrier string, file io.Reader) (*dto.CODRemittanceResponse, error) {
+	rows, invalid, err := parseRemittanceFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CODRemittanceResponse{
+		Carrier:    carrier,
+		OrdersPaid: []uint{},
+		Results:    make([]dto.CODRemittanceResult, 0, len(rows)+len(invalid)),
+	}
+	for _, result := range invalid {
+		response.Flagged++
+		response.Results = append(response.Results, result)
+	}
+	for _, row := range rows {
+		result, paidOrderID := s.remitShipment(adminID, carrier, row)
+		if result.Result == remittanceMatched || result.Result == remittanceAlreadyDone {
+			response.Matched++
+		} else {
+			response.Flagged++
+		}
+		if paidOrderID != 0 {
+			response.OrdersPaid = append(response.OrdersPaid, paidOrderID)
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+// GetCODShipments ดึงพัสดุเก็บเงินปลายทางตามสถานะการโอนเงิน (ว่าง = ทุกสถานะ)
+func (s *paymentService) GetCODShipments(status domain.CODStatus) ([]dto.CODShipmentResponse, error) {
+	shipments, err := s.uow.ShipmentRepository().FindByCODStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.CODShipmentResponse, 0, len(shipments))
+	for _, shipment := range shipments {
+		responses = append(responses, dto.CODShipmentResponse{
+			ShipmentID:     shipment.ID,
+			OrderID:        shipment.OrderID,
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+			ShipmentStatus: shipment.Status,
+			CODAmount:      shipment.CODAmount,
+			CODStatus:      shipment.CODStatus,
+			RemittedAmount: shipment.CODRemittedAmount,
+			RemittedAt:     shipment.CODRemittedAt,
+			RemittanceRef:  shipment.CODRemittanceRef,
+		})
+	}
+	return responses, nil
+}
+
+// remitShipment บันทึกเงินที่ขนส่งโอนมาของพัสดุ 1 กล่อง คืนผลลัพธ์และ ID ของ Order ถ้า Order ได้รับเงินครบจากแถวนี้
+func (s *paymentService) remitShipment(adminID uint, carrier string, row codRemittanceRow) (dto.CODRemittanceResult, uint) {
+	result := dto.CODRemittanceResult{
+		Line:           row.line,
+		TrackingNumber: row.trackingNumber,
+		Amount:         row.amount,
+	}
+
+	var paidOrderID uint
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		shipment, err := repos.Shipment.FindByTracking(carrier, row.trackingNumber)
+		if err != nil {
+			return err
+		}
+		result.ShipmentID = shipment.ID
+		result.OrderID = shipment.OrderID
+		result.ExpectedAmount = shipment.CODAmount
+		if shipment.CODStatus == "" {
+			result.Result = remittanceNotCOD
+			return nil
+		}
+
+		// Lock Order ก่อนแล้วอ่านพัสดุใหม่ กันการนำเข้าไฟล์ซ้อนกันบันทึกพัสดุเดียวกันซ้ำ
+		order, err := repos.Order.LockByID(shipment.OrderID)
+		if err != nil {
+			return err
+		}
+		if shipment, err = repos.Shipment.FindByTracking(carrier, row.trackingNumber); err != nil {
+			return err
+		}
+		if shipment.CODStatus == domain.CODRemitted {
+			result.Result = remittanceAlreadyDone
+			return nil
+		}
+		if shipment.CODStatus == domain.CODVoid {
+			result.Result = remittanceReturned
+			result.Note = "shipment was returned to sender, nothing to collect"
+			return nil
+		}
+
+		shipment.CODRemittedAmount = row.amount
+		shipment.CODRemittedAt = &row.remittedAt
+		shipment.CODRemittanceRef = row.reference
+		if !domain.SameMoney(shipment.CODAmount, row.amount) {
+			shipment.CODStatus = domain.CODMismatch
+			result.Result = remittanceMismatch
+			result.Note = fmt.Sprintf("shipment %d expects %.2f", shipment.ID, shipment.CODAmount)
+			return repos.Shipment.Update(shipment)
+		}
+
+		shipment.CODStatus = domain.CODRemitted
+		if err := repos.Shipment.Update(shipment); err != nil {
+			return err
+		}
+		if err := repos.Payment.Create(&domain.Payment{
+			OrderID:           order.ID,
+			Provider:          codProvider,
+			ProviderPaymentID: fmt.Sprintf("%s:%s:%s", codProvider, strings.ToLower(shipment.Carrier), shipment.TrackingNumber),
+			Method:            codProvider,
+			Amount:            row.amount,
+			Currency:          "THB",
+			Status:            domain.PaymentStatusCaptured,
+			CapturedAt:        &row.remittedAt,
+		}); err != nil {
+			return err
+		}
+		result.Result = remittanceMatched
+
+		// Order ถือว่าชำระแล้วเมื่อเงินที่ขนส่งโอนมาครบทุกกล่องรวมกันเท่ากับ FinalPrice
+		if order.PaidAt != nil {
+			return nil
+		}
+		shipments, err := repos.Shipment.FindByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
+		var remitted float64
+		for _, sh := range shipments {
+			if sh.CODStatus == domain.CODRemitted {
+				remitted += sh.CODRemittedAmount
+			}
+		}
+		if remitted < order.FinalPrice && !domain.SameMoney(remitted, order.FinalPrice) {
+			return nil
+		}
+		order.PaidAt = &row.remittedAt
+		if err := repos.Order.Update(order); err != nil {
+			return fmt.Errorf("failed to mark order as paid: %w", err)
+		}
+		if err := outboxService.RecordOrderEvent(repos, domain.EventOrderPaid, order, "", "cash on delivery remitted by "+carrier); err != nil {
+			return err
+		}
+		// ได้รับเงินครบแล้วจึงออกใบกำกับภาษีของ Order เก็บเงินปลายทาง
+		if _, err := invoiceService.IssueInvoice(repos, order); err != nil {
+			return err
+		}
+		paidOrderID = order.ID
+		return nil
+	})
+	switch {
+	case errors.Is(err, orderRepo.ErrShipmentNotFound):
+		result.Result = remittanceUnmatched
+		result.Note = "no shipment with this tracking number for the carrier"
+	case err != nil:
+		result.Result = remittanceFailed
+		result.Note = err.Error()
+		paidOrderID = 0
+	}
+	if result.Result != remittanceMatched {
+		log.Printf("COD remittance line %d (%s %s) imported by admin %d: %s %s", row.line, carrier, row.trackingNumber, adminID, result.Result, result.Note)
+	}
+	return result, paidOrderID
+}
+
+// parseRemittanceFile อ่านไฟล์ CSV ของขนส่ง แถวที่อ่านไม่ได้จะถูกคืนเป็นผลลัพธ์ invalid_row แยกไว้
+func parseRemittanceFile(file io.Reader) ([]codRemittanceRow, []dto.CODRemittanceResult, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, ErrInvalidRemittanceFile
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	trackingCol, hasTracking := columns["tracking_number"]
+	amountCol, hasAmount := columns["amount"]
+	if !hasTracking || !hasAmount {
+		return nil, nil, ErrInvalidRemittanceFile
+	}
+	value := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []codRemittanceRow
+	var invalid []dto.CODRemittanceResult
+	now := time.Now()
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidRemittanceFile, err)
+		}
+		if line > remittanceMaxFileLines {
+			return nil, nil, fmt.Errorf("%w: file has more than %d lines", ErrInvalidRemittanceFile, remittanceMaxFileLines)
+		}
+
+		row := codRemittanceRow{line: line, remittedAt: now, reference: value(record, "reference")}
+		if trackingCol < len(record) {
+			row.trackingNumber = strings.TrimSpace(record[trackingCol])
+		}
+		var amountErr error
+		if amountCol < len(record) {
+			row.amount, amountErr = strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(record[amountCol]), ",", ""), 64)
+		}
+
+		note := ""
+		switch {
+		case row.trackingNumber == "":
+			note = "tracking_number is empty"
+		case amountCol >= len(record) || amountErr != nil || row.amount <= 0:
+			note = "amount must be a number greater than 0"
+		}
+		if at := value(record, "remitted_at"); at != "" && note == "" {
+			parsed, ok := parseRemittanceTime(at)
+			if !ok {
+				note = "remitted_at must be YYYY-MM-DD, YYYY-MM-DD HH:MM:SS or RFC3339"
+			}
+			row.remittedAt = parsed
+		}
+		if note != "" {
+			invalid = append(invalid, dto.CODRemittanceResult{
+				Line:           line,
+				TrackingNumber: row.trackingNumber,
+				Amount:         row.amount,
+				Result:         remittanceInvalidRow,
+				Note:           note,
+			})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, invalid, nil
+}
+
+func parseRemittanceTime(value string) (time.Time, bool) {
+	for _, layout := range remittanceTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	orderService "backend/orders/service"
+	outboxService "backend/outbox/service"
+	"backend/payments/dto"
+	"backend/payments/gateway"
+	"backend/payments/repository"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrPaymentAccessDenied  = errors.New("you do not have permission to access this payment")
+	ErrOrderNotPayable      = errors.New("order is not awaiting payment")
+	ErrPaymentNotCapturable = errors.New("payment is not awaiting capture")
+	ErrPaymentNotRefundable = errors.New("payment has not been captured or is already fully refunded")
+	ErrInvalidRefundAmount  = errors.New("refund amount must be greater than 0 and not exceed the remaining captured amount")
+	ErrPaymentDeclined      = errors.New("payment was declined")
+	ErrPaymentAmountChanged = errors.New("order total has changed since this payment was created, please create a new payment")
+)
+
+type PaymentService interface {
+	CreateIntent(ctx context.Context, userID, orderID uint, req dto.CreateIntentRequest) (*dto.PaymentResponse, error)
+	Capture(ctx context.Context, userID, paymentID uint) (*dto.PaymentResponse, error)
+	Refund(ctx context.Context, adminID, paymentID uint, req dto.RefundRequest) (*dto.PaymentResponse, error)
+	SettleRefunds(ctx context.Context, adminID uint, order *domain.Order, returnRequestID *uint, refunds []domain.OrderRefund) ([]domain.OrderRefund, error)
+	ReserveOrderRefund(repos *datastore.Repositories, order *domain.Order, amount float64, reason string) ([]domain.OrderRefund, error)
+	ReserveAdjustmentRefund(repos *datastore.Repositories, order *domain.Order, amount, taxAmount float64, reason string) ([]domain.OrderRefund, error)
+	CancelIntents(ctx context.Context, payments []domain.Payment)
+	GetPayment(ctx context.Context, userID, paymentID uint) (*dto.PaymentResponse, error)
+
+	// PromptPay
+	GetPromptPayQR(userID, orderID uint) (*dto.PromptPayQRResponse, error)
+	ReconcilePromptPay(adminID uint, req dto.ReconcileRequest) (*dto.ReconcileResponse, error)
+
+	// เก็บเงินปลายทาง
+	ImportCODRemittance(adminID uint, carrier string, file io.Reader) (*dto.CODRemittanceResponse, error)
+	GetCODShipments(status domain.CODStatus) ([]dto.CODShipmentResponse, error)
+}
+
+type paymentService struct {
+	uow         datastore.UnitOfWork
+	gateway     gateway.PaymentGateway
+	promptPayID string
+}
+
+func NewPaymentService(uow datastore.UnitOfWork, gw gateway.PaymentGateway, promptPayID string) PaymentService {
+	return &paymentService{
+		uow:         uow,
+		gateway:     gw,
+		promptPayID: promptPayID,
+	}
+}
+
+// CreateIntent สร้างรายการชำระเงินสำหรับ Order ที่ยังรอชำระ (pending) ด้วยยอด FinalPrice
+// หรือ Order ที่ชำระแล้วแต่ยอดเพิ่มขึ้นจากการแก้ไข ด้วยยอด BalanceDue (ดู amountDue)
+func (s *paymentService) CreateIntent(ctx context.Context, userID, orderID uint, req dto.CreateIntentRequest) (*dto.PaymentResponse, error) {
+	order, err := s.uow.OrderRepository().FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, orderService.ErrOrderAccessDenied
+	}
+	amount, ok := amountDue(order)
+	if !ok {
+		return nil, fmt.Errorf("%w: order status is '%s'", ErrOrderNotPayable, order.Status)
+	}
+
+	// 1. สร้าง Intent ที่ Gateway ก่อน (อยู่นอก Transaction เพราะเป็นระบบภายนอก)
+	intent, err := s.gateway.CreateIntent(ctx, gateway.IntentRequest{
+		OrderID:  order.ID,
+		Amount:   amount,
+		Currency: "THB",
+		Method:   req.Method,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+
+	// 2. บันทึก Payment ลง Database
+	payment := &domain.Payment{
+		OrderID:           order.ID,
+		Provider:          s.gateway.Name(),
+		ProviderPaymentID: intent.ID,
+		Method:            req.Method,
+		Amount:            intent.Amount,
+		Currency:          "THB",
+		Status:            domain.PaymentStatusPending,
+	}
+	err = s.uow.Execute(func(repos *datastore.Repositories) error {
+		return repos.Payment.Create(payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapPaymentToResponse(payment), nil
+}
+
+// Capture ตัดเงินผ่าน Gateway แล้วเปลี่ยนสถานะ Order เป็น processing ผ่าน State Machine
+// ถ้าเป็นการชำระยอดเพิ่มของ Order ที่ชำระแล้ว (BalanceDue) จะล้างยอดค้างชำระแทนการเปลี่ยนสถานะ
+func (s *paymentService) Capture(ctx context.Context, userID, paymentID uint) (*dto.PaymentResponse, error) {
+	payment, err := s.findOwnedPayment(userID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != domain.PaymentStatusPending {
+		return nil, fmt.Errorf("%w: payment status is '%s'", ErrPaymentNotCapturable, payment.Status)
+	}
+	due, ok := amountDue(&payment.Order)
+	if !ok {
+		return nil, fmt.Errorf("%w: order status is '%s'", ErrOrderNotPayable, payment.Order.Status)
+	}
+	// ยอดของ Order เปลี่ยนหลังสร้าง Intent (เช่น Admin แก้รายการสินค้า) ห้ามตัดเงินด้วยยอดเดิม ลูกค้าต้องสร้าง Intent ใหม่
+	if !domain.SameMoney(payment.Amount, due) {
+		if err := s.uow.Execute(func(repos *datastore.Repositories) error {
+			payment.Status = domain.PaymentStatusFailed
+			payment.FailureReason = "order total changed before capture"
+			return repos.Payment.Update(payment)
+		}); err != nil {
+			return nil, err
+		}
+		s.CancelIntents(ctx, []domain.Payment{*payment})
+		return nil, ErrPaymentAmountChanged
+	}
+
+	// 1. ตัดเงินที่ Gateway
+	intent, captureErr := s.gateway.Capture(ctx, payment.ProviderPaymentID)
+	if captureErr != nil && !errors.Is(captureErr, gateway.ErrPaymentDeclined) {
+		return nil, fmt.Errorf("failed to capture payment: %w", captureErr)
+	}
+
+	// 2. บันทึกผลและเปลี่ยนสถานะ Order ใน Transaction เดียว
+	var notPayable error
+	err = s.uow.Execute(func(repos *datastore.Repositories) error {
+		p, err := repos.Payment.FindByID(paymentID)
+		if err != nil {
+			return err
+		}
+
+		if captureErr != nil {
+			p.Status = domain.PaymentStatusFailed
+			p.FailureReason = intent.FailureReason
+			payment = p
+			return repos.Payment.Update(p)
+		}
+
+		now := time.Now()
+		p.Status = domain.PaymentStatusCaptured
+		p.CapturedAt = &now
+		payment = p
+		if err := repos.Payment.Update(p); err != nil {
+			return err
+		}
+
+		order, err := repos.Order.LockByID(p.OrderID)
+		if err != nil {
+			return err
+		}
+		due, ok := amountDue(order)
+		if !ok {
+			// Order ถูกเปลี่ยนสถานะไประหว่างที่ตัดเงิน (เช่น ถูกยกเลิก) ต้องคืนเงินหลัง Commit
+			notPayable = ErrOrderNotPayable
+			return nil
+		}
+		// ยอดของ Order ถูกแก้ระหว่างที่ตัดเงิน ต้องคืนเงินหลัง Commit
+		if !domain.SameMoney(p.Amount, due) {
+			notPayable = ErrPaymentAmountChanged
+			return nil
+		}
+		if order.Status == domain.StatusProcessing {
+			order.BalanceDue = 0
+			if err := repos.Order.Update(order); err != nil {
+				return err
+			}
+			reason := fmt.Sprintf("balance paid via %s (%s)", p.Provider, p.ProviderPaymentID)
+			return outboxService.RecordOrderEvent(repos, domain.EventOrderUpdated, order, "", reason)
+		}
+		order.PaymentMethod = &p.Method
+		reason := fmt.Sprintf("payment captured via %s (%s)", p.Provider, p.ProviderPaymentID)
+		err = orderService.TransitionOrderStatus(repos, order, domain.StatusProcessing, &userID, reason)
+		if errors.Is(err, orderService.ErrInvalidOrderStatus) {
+			// Order ถูกเปลี่ยนสถานะไประหว่างที่ตัดเงิน (เช่น ถูกยกเลิก) ต้องคืนเงินหลัง Commit
+			notPayable = ErrOrderNotPayable
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if captureErr != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentDeclined, intent.FailureReason)
+	}
+	if notPayable != nil {
+		if _, err := s.refund(ctx, payment.ID, payment.Amount, notPayable.Error()); err != nil {
+			log.Printf("WARNING: failed to auto-refund payment %d: %v", payment.ID, err)
+		}
+		return nil, notPayable
+	}
+	return mapPaymentToResponse(payment), nil
+}
+
+// Refund คืนเงินบางส่วนหรือทั้งหมดของ Payment ที่ตัดเงินแล้ว (สำหรับ Admin)
+// ยอดถูกจองไว้กับ Order ก่อนเรียก Gateway และบันทึกเป็น OrderRefund เหมือนการคืนเงินจากคำขอคืนสินค้า
+func (s *paymentService) Refund(ctx context.Context, adminID, paymentID uint, req dto.RefundRequest) (*dto.PaymentResponse, error) {
+	payment, err := s.uow.PaymentRepository().FindByID(paymentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPaymentNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("refunded by admin %d", adminID)
+	}
+
+	var (
+		order   *domain.Order
+		refunds []domain.OrderRefund
+	)
+	err = s.uow.Execute(func(repos *datastore.Repositories) error {
+		// Lock Order ก่อนอ่าน Payment ใหม่ การคืนเงินทุกทางของ Order เดียวกันจึงทำทีละรายการ
+		var err error
+		if order, err = repos.Order.LockByID(payment.OrderID); err != nil {
+			return err
+		}
+		if payment, err = repos.Payment.FindByID(paymentID); err != nil {
+			return err
+		}
+		if payment.Status != domain.PaymentStatusCaptured && payment.Status != domain.PaymentStatusPartiallyRefunded {
+			return fmt.Errorf("%w: payment status is '%s'", ErrPaymentNotRefundable, payment.Status)
+		}
+		if IsOfflineProvider(payment.Provider) {
+			return fmt.Errorf("%w: %s payments must be refunded manually", ErrPaymentNotRefundable, payment.Provider)
+		}
+
+		remaining := domain.RoundMoney(payment.Amount - payment.RefundedAmount)
+		amount := domain.RoundMoney(req.Amount)
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount <= 0 || amount > remaining {
+			return ErrInvalidRefundAmount
+		}
+		refunds, err = ReserveRefund(repos, order, paymentID, amount, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.SettleRefunds(ctx, adminID, order, nil, refunds); err != nil {
+		return nil, err
+	}
+	refunded, err := s.uow.PaymentRepository().FindByID(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return mapPaymentToResponse(refunded), nil
+}
+
+// GetPayment ดึงสถานะล่าสุดจาก Gateway และอัปเดตข้อมูลใน Database ถ้ามีการเปลี่ยนแปลง
+func (s *paymentService) GetPayment(ctx context.Context, userID, paymentID uint) (*dto.PaymentResponse, error) {
+	payment, err := s.findOwnedPayment(userID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := s.gateway.GetStatus(ctx, payment.ProviderPaymentID)
+	if err != nil {
+		// ถ้า Gateway ไม่ตอบ ก็แสดงข้อมูลล่าสุดที่เรามี
+		log.Printf("WARNING: could not fetch status of payment %d from %s: %v", payment.ID, payment.Provider, err)
+		return mapPaymentToResponse(payment), nil
+	}
+
+	status := mapIntentStatus(intent.Status)
+	if !canSyncStatus(payment.Status, status) {
+		return mapPaymentToResponse(payment), nil
+	}
+	if status != payment.Status || intent.RefundedAmount != payment.RefundedAmount {
+		payment.Status = status
+		payment.RefundedAmount = intent.RefundedAmount
+		if err := s.uow.Execute(func(repos *datastore.Repositories) error {
+			return repos.Payment.Update(payment)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return mapPaymentToResponse(payment), nil
+}
+
+// CancelIntents ยกเลิก Intent ที่ Gateway ของ Payment ที่เราบันทึกเป็น failed ไปแล้ว (เช่น ยอด Order เปลี่ยน)
+// กัน Intent เดิมถูก Capture ได้ภายหลัง ยกเลิกไม่สำเร็จจะแค่ Log ไว้ เพราะ Capture ตรวจสถานะใน Database ก่อนเสมอ
+func (s *paymentService) CancelIntents(ctx context.Context, payments []domain.Payment) {
+	for _, payment := range payments {
+		if IsOfflineProvider(payment.Provider) {
+			continue
+		}
+		if _, err := s.gateway.Cancel(ctx, payment.ProviderPaymentID); err != nil {
+			log.Printf("WARNING: could not cancel intent of payment %d at %s: %v", payment.ID, payment.Provider, err)
+		}
+	}
+}
+
+// refund เรียก Gateway ให้คืนเงินแล้วบันทึกยอดที่คืนลง Payment
+// ใช้คืนเงินอัตโนมัติของ Payment ที่ Order ไม่ได้รับไว้ (ไม่เข้าบัญชีคืนเงินของ Order)
+func (s *paymentService) refund(ctx context.Context, paymentID uint, amount float64, reason string) (*domain.Payment, error) {
+	payment, err := s.uow.PaymentRepository().FindByID(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := s.gateway.Refund(ctx, payment.ProviderPaymentID, amount)
+	if err != nil {
+		if errors.Is(err, gateway.ErrRefundTooLarge) {
+			return nil, ErrInvalidRefundAmount
+		}
+		return nil, fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	err = s.uow.Execute(func(repos *datastore.Repositories) error {
+		p, err := repos.Payment.FindByID(paymentID)
+		if err != nil {
+			return err
+		}
+		p.RefundedAmount = intent.RefundedAmount
+		p.Status = mapIntentStatus(intent.Status)
+		p.RefundReason = reason
+		payment = p
+		if err := repos.Payment.Update(p); err != nil {
+			return err
+		}
+		return outboxService.RecordPaymentRefunded(repos, p, amount)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// findOwnedPayment ค้นหา Payment และตรวจว่าเป็นของ User คนนี้จริง
+func (s *paymentService) findOwnedPayment(userID, paymentID uint) (*domain.Payment, error) {
+	payment, err := s.uow.PaymentRepository().FindByID(paymentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPaymentNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	if payment.Order.UserID != userID {
+		return nil, ErrPaymentAccessDenied
+	}
+	return payment, nil
+}
+
+// canSyncStatus ตรวจว่าสถานะจาก Gateway เขียนทับสถานะใน Database ได้หรือไม่ (ห้ามถอยสถานะ)
+// failed และ refunded เป็นสถานะสุดท้าย ส่วน captured เปลี่ยนได้เฉพาะเป็นการคืนเงิน
+// กัน Payment ที่เรายกเลิกแล้วแต่ Intent ยังรอ Capture อยู่ ถูกดึงกลับมาเป็น pending
+func canSyncStatus(current, next domain.PaymentStatus) bool {
+	switch current {
+	case domain.PaymentStatusPending:
+		return true
+	case domain.PaymentStatusCaptured, domain.PaymentStatusPartiallyRefunded:
+		return next == domain.PaymentStatusPartiallyRefunded || next == domain.PaymentStatusRefunded
+	default:
+		return false
+	}
+}
+
+// amountDue คืนยอดที่ Order ยังต้องชำระผ่าน Gateway: FinalPrice ของ Order ที่รอชำระ
+// หรือ BalanceDue ของ Order ที่ชำระแล้วแต่ยอดเพิ่มขึ้นจากการแก้ไข คืน false ถ้าไม่มียอดต้องชำระ
+func amountDue(order *domain.Order) (float64, bool) {
+	switch {
+	case order.Status == domain.StatusPending:
+		return order.FinalPrice, true
+	case order.Status == domain.StatusProcessing && order.PaidAt != nil && order.BalanceDue > 0:
+		return order.BalanceDue, true
+	default:
+		return 0, false
+	}
+}
+
+// Helper function สำหรับแปลงสถานะของ Gateway เป็นสถานะของเรา
+func mapIntentStatus(status gateway.IntentStatus) domain.PaymentStatus {
+	switch status {
+	case gateway.IntentSucceeded:
+		return domain.PaymentStatusCaptured
+	case gateway.IntentFailed, gateway.IntentCanceled:
+		return domain.PaymentStatusFailed
+	case gateway.IntentPartiallyRefunded:
+		return domain.PaymentStatusPartiallyRefunded
+	case gateway.IntentRefunded:
+		return domain.PaymentStatusRefunded
+	default:
+		return domain.PaymentStatusPending
+	}
+}
+
+func mapPaymentToResponse(payment *domain.Payment) *dto.PaymentResponse {
+	return &dto.PaymentResponse{
+		ID:                payment.ID,
+		OrderID:           payment.OrderID,
+		Provider:          payment.Provider,
+		ProviderPaymentID: payment.ProviderPaymentID,
+		Method:            payment.Method,
+		Amount:            payment.Amount,
+		Currency:          payment.Currency,
+		Status:            payment.Status,
+		RefundedAmount:    payment.RefundedAmount,
+		FailureReason:     payment.FailureReason,
+		RefundReason:      payment.RefundReason,
+		CapturedAt:        payment.CapturedAt,
+		CreatedAt:         payment.CreatedAt,
+	}
+}
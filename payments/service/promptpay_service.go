@@ -0,0 +1,215 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	orderRepo "backend/orders/repository"
+	orderService "backend/orders/service"
+	"backend/payments/dto"
+	"backend/payments/promptpay"
+	"backend/payments/repository"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrPromptPayNotConfigured = errors.New("promptpay is not configured")
+
+const (
+	promptPayProvider    = "promptpay"
+	promptPayQRSize      = 512
+	promptPayRefPrefix   = "ORD"
+	slipMatched          = "matched"
+	slipAlreadyRecorded  = "already_recorded"
+	slipAmountMismatch   = "amount_mismatch"
+	slipOrderNotPending  = "order_not_pending"
+	slipAmbiguous        = "ambiguous"
+	slipUnmatched        = "unmatched"
+	slipProcessingFailed = "error"
+)
+
+// GetPromptPayQR สร้าง QR Code PromptPay สำหรับ Order ที่ยังรอชำระ ด้วยยอด FinalPrice
+func (s *paymentService) GetPromptPayQR(userID, orderID uint) (*dto.PromptPayQRResponse, error) {
+	if s.promptPayID == "" {
+		return nil, ErrPromptPayNotConfigured
+	}
+
+	order, err := s.uow.OrderRepository().FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, orderService.ErrOrderAccessDenied
+	}
+	if order.Status != domain.StatusPending {
+		return nil, fmt.Errorf("%w: order status is '%s'", ErrOrderNotPayable, order.Status)
+	}
+
+	reference := promptPayReference(order.ID)
+	payload, err := promptpay.GeneratePayload(s.promptPayID, order.FinalPrice, reference)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate promptpay payload: %w", err)
+	}
+	png, err := promptpay.GeneratePNG(payload, promptPayQRSize)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate promptpay qr code: %w", err)
+	}
+
+	return &dto.PromptPayQRResponse{
+		OrderID:   order.ID,
+		Amount:    order.FinalPrice,
+		Reference: reference,
+		Payload:   payload,
+		QRCodePNG: png,
+	}, nil
+}
+
+// ReconcilePromptPay จับคู่เงินโอนกับ Order ที่รอชำระ ด้วยเลขอ้างอิงก่อน ถ้าไม่มีจึงจับคู่ด้วยยอดเงิน
+// สลิปแต่ละรายการทำใน Transaction ของตัวเอง รายการที่มีปัญหาจะไม่กระทบรายการอื่น
+func (s *paymentService) ReconcilePromptPay(adminID uint, req dto.ReconcileRequest) (*dto.ReconcileResponse, error) {
+	response := &dto.ReconcileResponse{Results: make([]dto.SlipMatchResult, 0, len(req.Slips))}
+	for _, slip := range req.Slips {
+		result := s.reconcileSlip(adminID, slip)
+		if result.Result == slipMatched {
+			response.Matched++
+		} else {
+			response.Unmatched++
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+func (s *paymentService) reconcileSlip(adminID uint, slip dto.BankSlip) dto.SlipMatchResult {
+	result := dto.SlipMatchResult{
+		BankReference: slip.BankReference,
+		Reference:     slip.Reference,
+		Amount:        slip.Amount,
+	}
+	providerPaymentID := promptPayProvider + ":" + slip.BankReference
+
+	// 1. สลิปนี้เคยถูกบันทึกไปแล้วหรือยัง
+	if existing, err := s.uow.PaymentRepository().FindByProviderPaymentID(providerPaymentID); err == nil {
+		result.Result = slipAlreadyRecorded
+		result.OrderID = existing.OrderID
+		return result
+	} else if !errors.Is(err, repository.ErrPaymentNotFound) {
+		result.Result = slipProcessingFailed
+		result.Note = err.Error()
+		return result
+	}
+
+	// 2. หา Order ที่ตรงกับสลิป
+	order, outcome, note := s.matchSlipToOrder(slip)
+	if order == nil {
+		result.Result = outcome
+		result.Note = note
+		return result
+	}
+	result.OrderID = order.ID
+
+	// 3. บันทึก Payment และเปลี่ยนสถานะ Order ผ่าน State Machine
+	capturedAt := slip.TransferredAt
+	if capturedAt.IsZero() {
+		capturedAt = time.Now()
+	}
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		// Lock Order แล้วตรวจซ้ำ เพราะ Order อาจถูกยกเลิก ชำระด้วยช่องทางอื่น หรือถูกแก้ยอดหลังจับคู่
+		o, err := repos.Order.LockByID(order.ID)
+		if err != nil {
+			return err
+		}
+		if o.Status != domain.StatusPending {
+			result.Result = slipOrderNotPending
+			result.Note = fmt.Sprintf("order %d is '%s'", o.ID, o.Status)
+			return nil
+		}
+		if !domain.SameMoney(o.FinalPrice, slip.Amount) {
+			result.Result = slipAmountMismatch
+			result.Note = fmt.Sprintf("order %d expects %.2f", o.ID, o.FinalPrice)
+			return nil
+		}
+
+		payment := &domain.Payment{
+			OrderID:           order.ID,
+			Provider:          promptPayProvider,
+			ProviderPaymentID: providerPaymentID,
+			Method:            promptPayProvider,
+			Amount:            slip.Amount,
+			Currency:          "THB",
+			Status:            domain.PaymentStatusCaptured,
+			CapturedAt:        &capturedAt,
+		}
+		if err := repos.Payment.Create(payment); err != nil {
+			return err
+		}
+
+		method := promptPayProvider
+		o.PaymentMethod = &method
+		reason := fmt.Sprintf("promptpay transfer %s reconciled", slip.BankReference)
+		return orderService.TransitionOrderStatus(repos, o, domain.StatusProcessing, &adminID, reason)
+	})
+	if err != nil {
+		result.Result = slipProcessingFailed
+		result.Note = err.Error()
+		return result
+	}
+
+	if result.Result == "" {
+		result.Result = slipMatched
+	}
+	return result
+}
+
+// matchSlipToOrder คืน Order ที่จับคู่ได้ หรือคืน nil พร้อมเหตุผลที่จับคู่ไม่ได้
+func (s *paymentService) matchSlipToOrder(slip dto.BankSlip) (*domain.Order, string, string) {
+	// จับคู่ด้วยเลขอ้างอิงก่อน (แม่นยำที่สุด)
+	if orderID, ok := parsePromptPayReference(slip.Reference); ok {
+		order, err := s.uow.OrderRepository().FindByID(orderID)
+		if err == nil {
+			if order.Status != domain.StatusPending {
+				return nil, slipOrderNotPending, fmt.Sprintf("order %d is '%s'", order.ID, order.Status)
+			}
+			if !domain.SameMoney(order.FinalPrice, slip.Amount) {
+				return nil, slipAmountMismatch, fmt.Sprintf("order %d expects %.2f", order.ID, order.FinalPrice)
+			}
+			return order, slipMatched, ""
+		}
+		if !errors.Is(err, orderRepo.ErrOrderNotFound) {
+			return nil, slipProcessingFailed, err.Error()
+		}
+	}
+
+	// ไม่มีเลขอ้างอิงที่ใช้ได้ ลองจับคู่ด้วยยอดเงิน ต้องเจอเพียง Order เดียวเท่านั้น
+	candidates, err := s.uow.OrderRepository().FindByStatusAndFinalPrice(domain.StatusPending, slip.Amount)
+	if err != nil {
+		return nil, slipProcessingFailed, err.Error()
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, slipUnmatched, "no pending order with this amount"
+	case 1:
+		return &candidates[0], slipMatched, ""
+	default:
+		return nil, slipAmbiguous, fmt.Sprintf("%d pending orders have this amount", len(candidates))
+	}
+}
+
+// promptPayReference คือเลขอ้างอิงที่ใส่ใน QR เพื่อใช้กระทบยอด
+func promptPayReference(orderID uint) string {
+	return fmt.Sprintf("%s%d", promptPayRefPrefix, orderID)
+}
+
+func parsePromptPayReference(reference string) (uint, bool) {
+	reference = strings.ToUpper(strings.TrimSpace(reference))
+	if !strings.HasPrefix(reference, promptPayRefPrefix) {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(reference, promptPayRefPrefix), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
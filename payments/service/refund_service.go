@@ -0,0 +1,232 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	orderRepo "backend/orders/repository"
+	outboxService "backend/outbox/service"
+	"backend/payments/gateway"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+)
+
+// ReserveRefund จองยอดคืนเงินของ Order ก่อนเรียก Gateway กันการคืนเงินซ้อนกันจนเกินยอดที่จ่าย
+// แบ่งยอดไปยัง Payment ที่คืนผ่าน Gateway ได้ก่อน ส่วนที่เหลือเป็นการคืนเงินนอกระบบ (Admin โอนคืนเอง)
+// ถ้าระบุ paymentID ยอดทั้งหมดต้องคืนผ่าน Payment นั้นได้ ไม่เช่นนั้นคืน ErrInvalidRefundAmount
+// order ต้องถูก Lock ไว้แล้ว (repos.Order.LockByID) และต้องเรียกภายใน uow.Execute เสมอ
+func ReserveRefund(repos *datastore.Repositories, order *domain.Order, paymentID uint, amount float64, reason string) ([]domain.OrderRefund, error) {
+	refunds, err := reservePayments(repos, order, paymentID, amount, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range refunds {
+		refund := &refunds[i]
+		if order.FinalPrice > 0 {
+			refund.TaxAmount = domain.RoundMoney(refund.Amount * order.TaxAmount / order.FinalPrice)
+		}
+		if err := repos.Order.AddRefund(order.ID, refund.Amount, refund.TaxAmount); err != nil {
+			if errors.Is(err, orderRepo.ErrRefundExceedsPaid) {
+				return nil, ErrInvalidRefundAmount
+			}
+			return nil, err
+		}
+	}
+	return refunds, nil
+}
+
+// ReserveAdjustmentRefund จองยอดคืนส่วนต่างของ Order ที่ชำระแล้วแต่ยอดลดลง (เช่น Admin แก้รายการสินค้า)
+// ต่างจาก ReserveRefund ตรงที่ไม่นับเข้า Order.RefundedAmount เพราะ FinalPrice ลดลงไปแล้ว
+// taxAmount คือภาษีที่ลดลงจากการแก้ไข จะถูกปันส่วนตามยอดของแต่ละรายการ
+// order ต้องถูก Lock ไว้แล้ว และต้องเรียกภายใน uow.Execute เสมอ
+func ReserveAdjustmentRefund(repos *datastore.Repositories, order *domain.Order, amount, taxAmount float64, reason string) ([]domain.OrderRefund, error) {
+	refunds, err := reservePayments(repos, order, 0, amount, reason)
+	if err != nil {
+		return nil, err
+	}
+	for i := range refunds {
+		refunds[i].Adjustment = true
+		if amount > 0 {
+			refunds[i].TaxAmount = domain.RoundMoney(taxAmount * refunds[i].Amount / amount)
+		}
+	}
+	return refunds, nil
+}
+
+// reservePayments แบ่งยอดคืนเงินไปยัง Payment ที่คืนผ่าน Gateway ได้ และจองยอดไว้ที่ Payment นั้น
+// ส่วนที่เหลือเป็นการคืนเงินนอกระบบ (ยกเว้นระบุ paymentID ซึ่งจะคืน ErrInvalidRefundAmount แทน)
+func reservePayments(repos *datastore.Repositories, order *domain.Order, paymentID uint, amount float64, reason string) ([]domain.OrderRefund, error) {
+	payments, err := repos.Payment.FindByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds := make([]domain.OrderRefund, 0, len(payments)+1)
+	left := domain.RoundMoney(amount)
+	for i := range payments {
+		payment := &payments[i]
+		if left <= 0 {
+			break
+		}
+		if paymentID != 0 && payment.ID != paymentID {
+			continue
+		}
+		if !isRefundable(payment) {
+			continue
+		}
+		part := domain.RoundMoney(math.Min(left, payment.Amount-payment.RefundedAmount))
+		if part <= 0 {
+			continue
+		}
+		// จองยอดไว้ที่ Payment ด้วย ยอดจริงจะถูกแทนด้วยค่าจาก Gateway หลังคืนเงินสำเร็จ
+		payment.RefundedAmount = domain.RoundMoney(payment.RefundedAmount + part)
+		if err := repos.Payment.Update(payment); err != nil {
+			return nil, err
+		}
+		id := payment.ID
+		refunds = append(refunds, domain.OrderRefund{OrderID: order.ID, PaymentID: &id, Amount: part, Reason: reason})
+		left = domain.RoundMoney(left - part)
+	}
+	if left > 0 {
+		if paymentID != 0 {
+			return nil, ErrInvalidRefundAmount
+		}
+		refunds = append(refunds, domain.OrderRefund{OrderID: order.ID, Amount: left, Reason: reason + " (manual)"})
+	}
+	return refunds, nil
+}
+
+// ReserveOrderRefund คือ ReserveRefund ของทั้ง Order (ไม่เจาะจง Payment) ให้ orders/service เรียกผ่าน Interface ได้
+func (s *paymentService) ReserveOrderRefund(repos *datastore.Repositories, order *domain.Order, amount float64, reason string) ([]domain.OrderRefund, error) {
+	return ReserveRefund(repos, order, 0, amount, reason)
+}
+
+// ReserveAdjustmentRefund ให้ orders/service เรียก ReserveAdjustmentRefund ผ่าน Interface ได้
+func (s *paymentService) ReserveAdjustmentRefund(repos *datastore.Repositories, order *domain.Order, amount, taxAmount float64, reason string) ([]domain.OrderRefund, error) {
+	return ReserveAdjustmentRefund(repos, order, amount, taxAmount, reason)
+}
+
+// SettleRefunds คืนเงินส่วนที่จองไว้ด้วย ReserveRefund ผ่าน Gateway แล้วบันทึกลง OrderRefund
+// ถ้า Gateway คืนเงินไม่สำเร็จ ส่วนนั้นและส่วนที่เหลือทั้งหมดจะถูกปล่อยยอดจองคืน
+// แล้วคืน Error หลังบันทึกส่วนที่สำเร็จไปแล้ว คืนค่ารายการที่บันทึกสำเร็จเสมอ
+// ส่วนต่างจากการแก้ไข Order (Adjustment) ปล่อยคืนไม่ได้เพราะยอด Order ลดลงแล้ว จึงถูกบันทึกเป็นการคืนเงินนอกระบบแทน
+func (s *paymentService) SettleRefunds(ctx context.Context, adminID uint, order *domain.Order, returnRequestID *uint, refunds []domain.OrderRefund) ([]domain.OrderRefund, error) {
+	type gatewayResult struct {
+		payment *domain.Payment
+		amount  float64
+	}
+
+	var (
+		settled  []domain.OrderRefund
+		released []domain.OrderRefund
+		results  = make(map[uint]gatewayResult)
+		failure  error
+	)
+	for _, refund := range refunds {
+		if failure != nil {
+			released = append(released, refund)
+			continue
+		}
+		if refund.PaymentID != nil {
+			payment, err := s.uow.PaymentRepository().FindByID(*refund.PaymentID)
+			if err == nil {
+				var intent *gateway.Intent
+				intent, err = s.gateway.Refund(ctx, payment.ProviderPaymentID, refund.Amount)
+				if err == nil {
+					payment.RefundedAmount = intent.RefundedAmount
+					payment.Status = mapIntentStatus(intent.Status)
+				}
+			}
+			if err != nil {
+				if errors.Is(err, gateway.ErrRefundTooLarge) {
+					err = ErrInvalidRefundAmount
+				}
+				failure = fmt.Errorf("failed to refund payment %d: %w", *refund.PaymentID, err)
+				released = append(released, refund)
+				continue
+			}
+			result := results[payment.ID]
+			result.payment = payment
+			result.amount += refund.Amount
+			results[payment.ID] = result
+		}
+		settled = append(settled, refund)
+	}
+
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		if _, err := repos.Order.LockByID(order.ID); err != nil {
+			return err
+		}
+		for _, refund := range released {
+			if refund.PaymentID != nil {
+				payment, err := repos.Payment.FindByID(*refund.PaymentID)
+				if err != nil {
+					return err
+				}
+				payment.RefundedAmount = math.Max(domain.RoundMoney(payment.RefundedAmount-refund.Amount), 0)
+				if err := repos.Payment.Update(payment); err != nil {
+					return err
+				}
+			}
+			if refund.Adjustment {
+				refund.PaymentID = nil
+				refund.Reason += " (manual)"
+				settled = append(settled, refund)
+				continue
+			}
+			if err := repos.Order.ReleaseRefund(order.ID, refund.Amount, refund.TaxAmount); err != nil {
+				return err
+			}
+		}
+
+		for _, result := range results {
+			payment := result.payment
+			if reason := refundReason(settled, payment.ID); reason != "" {
+				payment.RefundReason = reason
+			}
+			if err := repos.Payment.Update(payment); err != nil {
+				return err
+			}
+			if err := outboxService.RecordPaymentRefunded(repos, payment, result.amount); err != nil {
+				return err
+			}
+		}
+		for i := range settled {
+			refund := &settled[i]
+			refund.ReturnRequestID = returnRequestID
+			refund.RefundedBy = &adminID
+			if err := repos.Return.CreateRefund(refund); err != nil {
+				return fmt.Errorf("failed to record refund: %w", err)
+			}
+			if err := outboxService.RecordOrderRefunded(repos, order, returnRequestID, refund.Amount, refund.TaxAmount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("ERROR: refunded order %d via gateway but could not record it: %v", order.ID, err)
+		return nil, err
+	}
+	return settled, failure
+}
+
+// isRefundable ตรวจว่า Payment ตัดเงินแล้วและคืนเงินผ่าน Gateway ได้
+func isRefundable(payment *domain.Payment) bool {
+	if payment.Status != domain.PaymentStatusCaptured && payment.Status != domain.PaymentStatusPartiallyRefunded {
+		return false
+	}
+	return !IsOfflineProvider(payment.Provider)
+}
+
+func refundReason(refunds []domain.OrderRefund, paymentID uint) string {
+	for _, refund := range refunds {
+		if refund.PaymentID != nil && *refund.PaymentID == paymentID {
+			return refund.Reason
+		}
+	}
+	return ""
+}
@@ -36,6 +36,8 @@ type CreateProductRequestData struct {
 	Quantity    int     `json:"quantity" validate:"required,gte=0"`
 	SKU         string  `json:"sku" validate:"required"`
 	CategoryID  uint    `json:"category_id" validate:"required"`
+	TaxClassID  *uint   `json:"tax_class_id"`            // ไม่ระบุ = ใช้ Tax Class เริ่มต้น
+	Weight      float64 `json:"weight" validate:"gte=0"` // กิโลกรัม ใช้คิดค่าจัดส่ง
 }
 
 // CreateProductRequest คือ DTO ที่ Handler สร้างเพื่อส่งให้ Service ตอนสร้างสินค้า
@@ -76,6 +78,8 @@ type ProductResponse struct {
 	Quantity    int              `json:"quantity"`
 	SKU         string           `json:"sku"`
 	Category    CategoryResponse `json:"category"`
+	TaxClassID  *uint            `json:"tax_class_id"`
+	Weight      float64          `json:"weight"`
 	Images      []ImageResponse  `json:"images"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
@@ -8,6 +8,7 @@ import (
 )
 
 var ErrNotFound = errors.New("record not found")
+var ErrInsufficientStock = errors.New("insufficient stock")
 
 type ProductRepository interface {
 	CreateProduct(product *domain.Product) error
@@ -23,6 +24,9 @@ type ProductRepository interface {
 	FindImagesByIDs(ids []uint) ([]domain.ProductImage, error)
 	DeleteImagesByIDs(ids []uint) error
 	FindByID(id uint) (*domain.Product, error)
+	// Method สำหรับจัดการสต็อก
+	DecreaseStock(id uint, quantity uint) error
+	RestoreStock(id uint, quantity uint) error
 }
 
 // ... UploadRepository Interface ...
@@ -175,3 +179,33 @@ func (r *productRepository) FindByID(id uint) (*domain.Product, error) {
 	}
 	return &product, nil
 }
+
+// DecreaseStock ตัดสต็อกสินค้าด้วย Conditional Update แบบ Atomic
+// เงื่อนไข quantity >= ? อยู่ใน UPDATE เดียวกัน ทำให้ Database เป็นคนตัดสินเมื่อมีหลาย Checkout พร้อมกัน
+// (Row ถูก Lock ระหว่าง Transaction อีกฝั่งจะรอแล้วประเมินเงื่อนไขใหม่) สต็อกจึงไม่มีทางติดลบ
+func (r *productRepository) DecreaseStock(id uint, quantity uint) error {
+	tx := r.db.Model(&domain.Product{}).
+		Where("id = ? AND quantity >= ?", id, quantity).
+		Update("quantity", gorm.Expr("quantity - ?", quantity))
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+// RestoreStock คืนสต็อกให้สินค้า (เช่น ตอนยกเลิก Order) ด้วยการบวกค่าแบบ Atomic
+// ใช้ Unscoped เพื่อให้คืนสต็อกได้แม้สินค้าจะถูก Soft Delete ไปแล้ว
+func (r *productRepository) RestoreStock(id uint, quantity uint) error {
+	tx := r.db.Unscoped().Model(&domain.Product{}).Where("id = ?", id).
+		Update("quantity", gorm.Expr("quantity + ?", quantity))
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"backend/domain"
 	"backend/internal/datastore"
+	outboxService "backend/outbox/service"
 	"backend/products/dto"
 	"backend/products/repository"
 	"context"
@@ -61,6 +62,8 @@ func (s *productService) CreateProductWithImages(ctx context.Context, req dto.Cr
 		Quantity:    productData.Quantity,
 		SKU:         productData.SKU,
 		CategoryID:  productData.CategoryID,
+		TaxClassID:  productData.TaxClassID,
+		Weight:      productData.Weight,
 	}
 
 	uploadedFilePaths := make(map[string]string) // temp -> final
@@ -80,6 +83,11 @@ func (s *productService) CreateProductWithImages(ctx context.Context, req dto.Cr
 		if err := repos.Product.CreateProduct(productToCreate); err != nil {
 			return err
 		}
+		if productToCreate.Quantity != 0 {
+			if err := outboxService.RecordStockChanged(repos, productToCreate.ID, productToCreate.Quantity, outboxService.StockReasonProductCreated, 0); err != nil {
+				return err
+			}
+		}
 
 		var newImagesData []domain.ProductImage
 		i := 0
@@ -174,11 +182,26 @@ func (s *productService) FindAllProducts(params dto.QueryParams) (*dto.Paginated
 
 func (s *productService) UpdateProduct(id uint, updates map[string]interface{}) (*dto.ProductResponse, error) {
 	err := s.uow.Execute(func(repos *datastore.Repositories) error {
-		_, err := repos.Product.FindProductByID(id)
+		before, err := repos.Product.FindProductByID(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.Product.Update(id, updates); err != nil {
+			return err
+		}
+
+		// สต็อกถูกแก้ด้วยมือ แจ้งระบบปลายทางด้วยส่วนต่างจากค่าเดิม
+		if _, ok := updates["quantity"]; !ok {
+			return nil
+		}
+		after, err := repos.Product.FindByID(id)
 		if err != nil {
 			return err
 		}
-		return repos.Product.Update(id, updates)
+		if delta := after.Quantity - before.Quantity; delta != 0 {
+			return outboxService.RecordStockChanged(repos, id, delta, outboxService.StockReasonAdminAdjustment, 0)
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -282,8 +305,10 @@ func mapProductToProductResponse(product *domain.Product, imageBaseURL string) *
 			ID:   product.Category.ID,
 			Name: product.Category.Name,
 		},
-		Images:    imagesDto,
-		CreatedAt: product.CreatedAt,
-		UpdatedAt: product.UpdatedAt,
+		TaxClassID: product.TaxClassID,
+		Weight:     product.Weight,
+		Images:     imagesDto,
+		CreatedAt:  product.CreatedAt,
+		UpdatedAt:  product.UpdatedAt,
 	}
 }
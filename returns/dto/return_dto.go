@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"backend/domain"
+	"io"
+	"time"
+)
+
+// FileInput คือข้อมูลไฟล์รูปที่ Handler เตรียมให้ Service (Service ไม่ต้องรู้จัก multipart.FileHeader)
+type FileInput struct {
+	Content     io.Reader
+	Filename    string
+	ContentType string
+}
+
+type ReturnItemRequest struct {
+	OrderItemID uint `json:"order_item_id" validate:"required"`
+	Quantity    uint `json:"quantity" validate:"required,gt=0"`
+}
+
+// CreateReturnRequestData คือข้อมูล JSON ใน Field "data" ของ Multipart Form
+type CreateReturnRequestData struct {
+	OrderID uint                `json:"order_id" validate:"required"`
+	Reason  string              `json:"reason" validate:"required,max=500"`
+	Items   []ReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// CreateReturnRequest คือ DTO ที่ Handler สร้างเพื่อส่งให้ Service
+type CreateReturnRequest struct {
+	Data   CreateReturnRequestData
+	Photos []FileInput
+}
+
+// ReviewReturnRequest คือ DTO ตอน Admin อนุมัติหรือปฏิเสธคำขอ
+type ReviewReturnRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+// ReceiveReturnRequest คือ DTO ตอนได้รับสินค้าคืน Restock = true จะคืนสินค้าเข้าสต็อก
+type ReceiveReturnRequest struct {
+	Restock bool `json:"restock"`
+}
+
+// RefundReturnRequest ถ้าไม่ระบุ Amount (หรือเป็น 0) จะคืนตามมูลค่าสินค้าที่ขอคืน
+type RefundReturnRequest struct {
+	Amount float64 `json:"amount" validate:"gte=0"`
+	Reason string  `json:"reason" validate:"max=255"`
+}
+
+type ReturnItemResponse struct {
+	OrderItemID uint    `json:"order_item_id"`
+	ProductID   uint    `json:"product_id"`
+	Name        string  `json:"name"`
+	Sku         string  `json:"sku"`
+	Price       float64 `json:"price"`
+	Quantity    uint    `json:"quantity"`
+}
+
+type ReturnResponse struct {
+	ID               uint                 `json:"id"`
+	OrderID          uint                 `json:"order_id"`
+	UserID           uint                 `json:"user_id"`
+	Status           domain.ReturnStatus  `json:"status"`
+	Reason           string               `json:"reason"`
+	AdminNote        string               `json:"admin_note,omitempty"`
+	Items            []ReturnItemResponse `json:"items"`
+	PhotoURLs        []string             `json:"photo_urls"`
+	RefundableAmount float64              `json:"refundable_amount"` // มูลค่าสินค้าที่ขอคืน (หลังหักส่วนลด)
+	RefundedAmount   float64              `json:"refunded_amount"`
+	Restocked        bool                 `json:"restocked"`
+	ReviewedAt       *time.Time           `json:"reviewed_at,omitempty"`
+	ReceivedAt       *time.Time           `json:"received_at,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"backend/domain"
+	"backend/middleware"
+	"backend/returns/dto"
+	"backend/returns/service"
+	"encoding/json"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReturnHandler struct {
+	returnSvc service.ReturnService
+}
+
+func NewReturnHandler(returnSvc service.ReturnService) *ReturnHandler {
+	return &ReturnHandler{returnSvc: returnSvc}
+}
+
+// HandleCreateReturn รับคำขอคืนสินค้าแบบ Multipart: Field "data" เป็น JSON และ "photos" เป็นรูปประกอบ
+func (h *ReturnHandler) HandleCreateReturn(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	returnDataJSON := c.FormValue("data")
+	if returnDataJSON == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Missing return data field 'data'")
+	}
+
+	var data dto.CreateReturnRequestData
+	if err := json.Unmarshal([]byte(returnDataJSON), &data); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid return data json")
+	}
+	if err := validator.New().Struct(data); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid form data")
+	}
+
+	var photos []dto.FileInput
+	for _, fileHeader := range form.File["photos"] {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Cannot process uploaded file: "+fileHeader.Filename)
+		}
+		defer file.Close()
+		photos = append(photos, dto.FileInput{
+			Content:     file,
+			Filename:    fileHeader.Filename,
+			ContentType: fileHeader.Header.Get("Content-Type"),
+		})
+	}
+
+	ret, err := h.returnSvc.CreateReturn(c.UserContext(), claims.UserID, dto.CreateReturnRequest{Data: data, Photos: photos})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(ret)
+}
+
+// HandleGetMyReturns ดูคำขอคืนสินค้าทั้งหมดของผู้ใช้
+func (h *ReturnHandler) HandleGetMyReturns(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	returns, err := h.returnSvc.GetMyReturns(claims.UserID)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(returns)
+}
+
+// HandleGetReturn ดูคำขอคืนสินค้า 1 รายการ (Admin ดูของทุกคนได้)
+func (h *ReturnHandler) HandleGetReturn(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid return ID")
+	}
+
+	isAdmin := claims.Role == string(domain.RoleAdmin)
+	ret, err := h.returnSvc.GetReturn(claims.UserID, isAdmin, uint(id))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(ret)
+}
+
+// HandleAdminListReturns ดูคำขอคืนสินค้าทั้งหมด กรองด้วย ?status= ได้
+func (h *ReturnHandler) HandleAdminListReturns(c *fiber.Ctx) error {
+	returns, err := h.returnSvc.GetAllReturns(domain.ReturnStatus(c.Query("status")))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(returns)
+}
+
+func (h *ReturnHandler) HandleApproveReturn(c *fiber.Ctx) error {
+	return h.handleReview(c, h.returnSvc.ApproveReturn)
+}
+
+func (h *ReturnHandler) HandleRejectReturn(c *fiber.Ctx) error {
+	return h.handleReview(c, h.returnSvc.RejectReturn)
+}
+
+// HandleReceiveReturn บันทึกการได้รับสินค้าคืน
+func (h *ReturnHandler) HandleReceiveReturn(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid return ID")
+	}
+
+	var req dto.ReceiveReturnRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	ret, err := h.returnSvc.ReceiveReturn(claims.UserID, uint(id), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(ret)
+}
+
+// HandleRefundReturn คืนเงินของคำขอคืนสินค้า (ไม่ส่ง Body = คืนตามมูลค่าสินค้าที่ขอคืน)
+func (h *ReturnHandler) HandleRefundReturn(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid return ID")
+	}
+
+	var req dto.RefundReturnRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	ret, err := h.returnSvc.RefundReturn(c.UserContext(), claims.UserID, uint(id), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(ret)
+}
+
+func (h *ReturnHandler) handleReview(c *fiber.Ctx, review func(adminID, id uint, req dto.ReviewReturnRequest) (*dto.ReturnResponse, error)) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid return ID")
+	}
+
+	var req dto.ReviewReturnRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	ret, err := review(claims.UserID, uint(id), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(ret)
+}
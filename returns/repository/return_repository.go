@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrReturnNotFound = errors.New("return request not found")
+
+type ReturnRepository interface {
+	Create(ret *domain.ReturnRequest) error
+	Update(ret *domain.ReturnRequest) error
+	FindByID(id uint) (*domain.ReturnRequest, error)
+	FindAllByUserID(userID uint) ([]domain.ReturnRequest, error)
+	FindAll(status domain.ReturnStatus) ([]domain.ReturnRequest, error)
+	FindByOrderID(orderID uint) ([]domain.ReturnRequest, error)
+	CreateRefund(refund *domain.OrderRefund) error
+}
+
+type returnRepository struct {
+	db *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &returnRepository{db: db}
+}
+
+// Create สร้างคำขอคืนสินค้าพร้อม Items และ Photos
+func (r *returnRepository) Create(ret *domain.ReturnRequest) error {
+	return r.db.Create(ret).Error
+}
+
+// Update อัปเดตเฉพาะข้อมูลของคำขอ (ไม่แตะ Items และ Photos)
+func (r *returnRepository) Update(ret *domain.ReturnRequest) error {
+	return r.db.Omit(clause.Associations).Save(ret).Error
+}
+
+func (r *returnRepository) FindByID(id uint) (*domain.ReturnRequest, error) {
+	var ret domain.ReturnRequest
+	err := r.withDetails().First(&ret, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (r *returnRepository) FindAllByUserID(userID uint) ([]domain.ReturnRequest, error) {
+	var returns []domain.ReturnRequest
+	err := r.withDetails().Where("user_id = ?", userID).Order("created_at desc").Find(&returns).Error
+	return returns, err
+}
+
+// FindAll ดึงคำขอคืนสินค้าทั้งหมด (status ว่าง = ทุกสถานะ) เรียงจากเก่าไปใหม่ให้ Admin ไล่ตรวจตามลำดับ
+func (r *returnRepository) FindAll(status domain.ReturnStatus) ([]domain.ReturnRequest, error) {
+	var returns []domain.ReturnRequest
+	query := r.withDetails()
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at asc").Find(&returns).Error
+	return returns, err
+}
+
+// FindByOrderID ดึงคำขอคืนสินค้าทั้งหมดของ Order (ใช้ตรวจจำนวนที่ขอคืนไปแล้ว)
+func (r *returnRepository) FindByOrderID(orderID uint) ([]domain.ReturnRequest, error) {
+	var returns []domain.ReturnRequest
+	err := r.db.Preload("Items").Where("order_id = ?", orderID).Find(&returns).Error
+	return returns, err
+}
+
+// CreateRefund บันทึกการคืนเงิน 1 ครั้งของ Order
+func (r *returnRepository) CreateRefund(refund *domain.OrderRefund) error {
+	return r.db.Create(refund).Error
+}
+
+func (r *returnRepository) withDetails() *gorm.DB {
+	return r.db.Preload("Items.OrderItem").Preload("Photos")
+}
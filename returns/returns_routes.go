@@ -0,0 +1,39 @@
+package returns
+
+import (
+	"backend/config"
+	"backend/internal/datastore"
+	"backend/middleware"
+	"backend/payments/gateway"
+	paymentService "backend/payments/service"
+	"backend/returns/handler"
+	"backend/returns/service"
+	"github.com/gofiber/fiber/v2"
+	"log"
+)
+
+func RegisterModule(api fiber.Router, uow datastore.UnitOfWork, cfg *config.Config) {
+	// คืนเงินผ่าน Gateway เดียวกับโมดูล payments
+	gw, err := gateway.New(cfg.PaymentProvider)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	paymentSvc := paymentService.NewPaymentService(uow, gw, cfg.PromptPayID)
+
+	returnSvc := service.NewReturnService(uow, paymentSvc, cfg.ImageBaseURL)
+	returnHdl := handler.NewReturnHandler(returnSvc)
+
+	returnAPI := api.Group("/returns", middleware.Protected())
+	returnAPI.Post("/", returnHdl.HandleCreateReturn)
+	returnAPI.Get("/", returnHdl.HandleGetMyReturns)
+	returnAPI.Get("/:id", returnHdl.HandleGetReturn)
+
+	adminAPI := api.Group("/admin/returns", middleware.Protected(), middleware.AdminRequired())
+	adminAPI.Get("/", returnHdl.HandleAdminListReturns)
+	adminAPI.Post("/:id/approve", returnHdl.HandleApproveReturn)
+	adminAPI.Post("/:id/reject", returnHdl.HandleRejectReturn)
+	adminAPI.Post("/:id/receive", returnHdl.HandleReceiveReturn)
+	adminAPI.Post("/:id/refund", returnHdl.HandleRefundReturn)
+
+	log.Println("✅ Return module registered successfully.")
+}
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	outboxService "backend/outbox/service"
+	paymentService "backend/payments/service"
+	"backend/returns/dto"
+	"backend/returns/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxReturnPhotos คือจำนวนรูปประกอบสูงสุดต่อคำขอ
+const maxReturnPhotos = 5
+
+var (
+	ErrReturnNotFound      = errors.New("return request not found")
+	ErrReturnAccessDenied  = errors.New("you do not have permission to access this return request")
+	ErrOrderNotReturnable  = errors.New("only completed orders can be returned")
+	ErrInvalidReturnItems  = errors.New("return items are not valid for this order")
+	ErrInvalidReturnPhotos = errors.New("return photos must be images and at most 5 files")
+	ErrInvalidReturnStatus = errors.New("return request status is not valid for this operation")
+	ErrInvalidRefundAmount = errors.New("refund amount must be greater than 0 and not exceed the amount paid")
+)
+
+type ReturnService interface {
+	CreateReturn(ctx context.Context, userID uint, req dto.CreateReturnRequest) (*dto.ReturnResponse, error)
+	GetMyReturns(userID uint) ([]dto.ReturnResponse, error)
+	GetReturn(userID uint, isAdmin bool, id uint) (*dto.ReturnResponse, error)
+	GetAllReturns(status domain.ReturnStatus) ([]dto.ReturnResponse, error)
+	ApproveReturn(adminID, id uint, req dto.ReviewReturnRequest) (*dto.ReturnResponse, error)
+	RejectReturn(adminID, id uint, req dto.ReviewReturnRequest) (*dto.ReturnResponse, error)
+	ReceiveReturn(adminID, id uint, req dto.ReceiveReturnRequest) (*dto.ReturnResponse, error)
+	RefundReturn(ctx context.Context, adminID, id uint, req dto.RefundReturnRequest) (*dto.ReturnResponse, error)
+}
+
+type returnService struct {
+	uow          datastore.UnitOfWork
+	paymentSvc   paymentService.PaymentService
+	imageBaseURL string
+}
+
+func NewReturnService(uow datastore.UnitOfWork, paymentSvc paymentService.PaymentService, imageBaseURL string) ReturnService {
+	return &returnService{
+		uow:          uow,
+		paymentSvc:   paymentSvc,
+		imageBaseURL: imageBaseURL,
+	}
+}
+
+// CreateReturn สร้างคำขอคืนสินค้าของ Order ที่ได้รับของแล้ว พร้อมอัปโหลดรูปประกอบ
+func (s *returnService) CreateReturn(ctx context.Context, userID uint, req dto.CreateReturnRequest) (*dto.ReturnResponse, error) {
+	if len(req.Photos) > maxReturnPhotos {
+		return nil, ErrInvalidReturnPhotos
+	}
+	for _, photo := range req.Photos {
+		if !strings.HasPrefix(photo.ContentType, "image/") {
+			return nil, ErrInvalidReturnPhotos
+		}
+	}
+
+	order, err := s.uow.OrderRepository().FindByID(req.Data.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrReturnAccessDenied
+	}
+	if order.Status != domain.StatusCompleted {
+		return nil, ErrOrderNotReturnable
+	}
+
+	// 1. อัปโหลดรูปก่อน ถ้าบันทึกคำขอไม่สำเร็จจะลบรูปทิ้ง
+	photos := make([]domain.ReturnPhoto, 0, len(req.Photos))
+	for _, photo := range req.Photos {
+		path := fmt.Sprintf("returns/orders/%d/%s%s", order.ID, uuid.New().String(), filepath.Ext(photo.Filename))
+		if _, err := s.uow.UploadRepository().UploadFile(ctx, path, photo.Content, photo.ContentType); err != nil {
+			s.deletePhotos(ctx, photos)
+			return nil, fmt.Errorf("failed to upload return photo: %w", err)
+		}
+		photos = append(photos, domain.ReturnPhoto{Path: path})
+	}
+
+	// 2. ตรวจจำนวนที่ขอคืนไม่เกินที่ซื้อ (หักจำนวนที่อยู่ในคำขออื่นที่ไม่ถูกปฏิเสธแล้ว) แล้วบันทึกคำขอ
+	ret := &domain.ReturnRequest{
+		OrderID: order.ID,
+		UserID:  userID,
+		Status:  domain.ReturnRequested,
+		Reason:  req.Data.Reason,
+		Photos:  photos,
+	}
+	err = s.uow.Execute(func(repos *datastore.Repositories) error {
+		// Lock Order ไว้เพื่อกันคำขอคืนซ้อนกันของ Order เดียวกัน
+		locked, err := repos.Order.LockByID(order.ID)
+		if err != nil {
+			return err
+		}
+		existing, err := repos.Return.FindByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
+		items, err := buildReturnItems(locked.OrderItems, existing, req.Data.Items)
+		if err != nil {
+			return err
+		}
+		ret.Items = items
+		return repos.Return.Create(ret)
+	})
+	if err != nil {
+		s.deletePhotos(ctx, photos)
+		return nil, err
+	}
+	return s.GetReturn(userID, false, ret.ID)
+}
+
+func (s *returnService) GetMyReturns(userID uint) ([]dto.ReturnResponse, error) {
+	returns, err := s.uow.ReturnRepository().FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapReturnsToResponse(returns)
+}
+
+// GetReturn ดึงคำขอคืนสินค้า 1 รายการ (ลูกค้าดูได้เฉพาะของตัวเอง)
+func (s *returnService) GetReturn(userID uint, isAdmin bool, id uint) (*dto.ReturnResponse, error) {
+	ret, err := s.findReturn(id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && ret.UserID != userID {
+		return nil, ErrReturnAccessDenied
+	}
+	order, err := s.uow.OrderRepository().FindByID(ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapReturnToResponse(ret, order), nil
+}
+
+func (s *returnService) GetAllReturns(status domain.ReturnStatus) ([]dto.ReturnResponse, error) {
+	returns, err := s.uow.ReturnRepository().FindAll(status)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapReturnsToResponse(returns)
+}
+
+func (s *returnService) ApproveReturn(adminID, id uint, req dto.ReviewReturnRequest) (*dto.ReturnResponse, error) {
+	return s.review(adminID, id, domain.ReturnApproved, req.Note)
+}
+
+func (s *returnService) RejectReturn(adminID, id uint, req dto.ReviewReturnRequest) (*dto.ReturnResponse, error) {
+	return s.review(adminID, id, domain.ReturnRejected, req.Note)
+}
+
+// ReceiveReturn บันทึกว่าได้รับสินค้าคืนแล้ว และคืนสินค้าเข้าสต็อกถ้า Restock = true
+func (s *returnService) ReceiveReturn(adminID, id uint, req dto.ReceiveReturnRequest) (*dto.ReturnResponse, error) {
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		ret, err := repos.Return.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if ret.ReceivedAt != nil || !ret.Status.CanTransitionTo(domain.ReturnReceived) {
+			return fmt.Errorf("%w: cannot receive a return in '%s' status", ErrInvalidReturnStatus, ret.Status)
+		}
+
+		if req.Restock {
+			for _, item := range ret.Items {
+				if err := repos.Product.RestoreStock(item.OrderItem.ProductID, item.Quantity); err != nil {
+					return fmt.Errorf("failed to restock product %d: %w", item.OrderItem.ProductID, err)
+				}
+				if err := outboxService.RecordStockChanged(repos, item.OrderItem.ProductID, int(item.Quantity), outboxService.StockReasonReturnRestocked, ret.OrderID); err != nil {
+					return err
+				}
+			}
+		}
+
+		now := time.Now()
+		ret.ReceivedAt = &now
+		ret.Restocked = req.Restock
+		// ถ้าคืนเงินไปก่อนแล้ว สถานะยังคงเป็น refunded
+		if ret.Status != domain.ReturnRefunded {
+			ret.Status = domain.ReturnReceived
+		}
+		return repos.Return.Update(ret)
+	})
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	return s.GetReturn(adminID, true, id)
+}
+
+// RefundReturn คืนเงินให้ลูกค้าสำหรับคำขอคืนสินค้า
+// คืนผ่าน Payment Gateway ก่อนตามยอดที่ตัดเงินไว้ ส่วนที่เหลือ (เช่น จ่ายด้วยการโอนเงิน) บันทึกเป็นการคืนเงินนอกระบบ
+// ยอดถูกจองไว้กับ Order และคำขอคืนสินค้าภายใต้ Lock ก่อนเรียก Gateway กันการกดคืนเงินซ้อนกันจนคืนเกิน
+func (s *returnService) RefundReturn(ctx context.Context, adminID, id uint, req dto.RefundReturnRequest) (*dto.ReturnResponse, error) {
+	ret, err := s.findReturn(id)
+	if err != nil {
+		return nil, err
+	}
+	reason := req.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("return request %d", ret.ID)
+	}
+
+	// 1. Lock Order แล้วจองยอดคืนเงิน (Order, Payment และคำขอคืนสินค้า) ใน Transaction เดียว
+	var (
+		order    *domain.Order
+		refunds  []domain.OrderRefund
+		previous domain.ReturnStatus
+		amount   float64
+	)
+	err = s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		if order, err = repos.Order.LockByID(ret.OrderID); err != nil {
+			return err
+		}
+		if ret, err = repos.Return.FindByID(id); err != nil {
+			return err
+		}
+		if !ret.Status.CanTransitionTo(domain.ReturnRefunded) {
+			return fmt.Errorf("%w: cannot refund a return in '%s' status", ErrInvalidReturnStatus, ret.Status)
+		}
+
+		// ยอดที่ยังค้างชำระจากการแก้ไข Order ไม่ได้รับเงินมา จึงคืนไม่ได้
+		remaining := domain.RoundMoney(order.FinalPrice - order.BalanceDue - order.RefundedAmount)
+		amount = domain.RoundMoney(req.Amount)
+		if amount == 0 {
+			amount = domain.RoundMoney(math.Min(returnValue(ret, order)-ret.RefundedAmount, remaining))
+		}
+		if amount <= 0 || amount > remaining {
+			return ErrInvalidRefundAmount
+		}
+		if refunds, err = paymentService.ReserveRefund(repos, order, 0, amount, reason); err != nil {
+			if errors.Is(err, paymentService.ErrInvalidRefundAmount) {
+				return ErrInvalidRefundAmount
+			}
+			return err
+		}
+
+		previous = ret.Status
+		ret.RefundedAmount = domain.RoundMoney(ret.RefundedAmount + amount)
+		ret.Status = domain.ReturnRefunded
+		return repos.Return.Update(ret)
+	})
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	// 2. คืนเงินผ่าน Gateway แล้วบันทึก OrderRefund ส่วนที่คืนไม่สำเร็จถูกปล่อยยอดจองคืนให้
+	settled, err := s.paymentSvc.SettleRefunds(ctx, adminID, order, &ret.ID, refunds)
+	if err != nil {
+		var total float64
+		for _, refund := range settled {
+			total += refund.Amount
+		}
+		if releaseErr := s.releaseReturnRefund(id, domain.RoundMoney(amount-total), total == 0, previous); releaseErr != nil {
+			log.Printf("ERROR: could not release reserved refund of return %d: %v", id, releaseErr)
+		}
+		return nil, err
+	}
+	return s.GetReturn(adminID, true, id)
+}
+
+// releaseReturnRefund ปล่อยยอดที่จองไว้กับคำขอคืนสินค้าส่วนที่คืนเงินไม่สำเร็จ
+// ถ้าไม่มีส่วนไหนคืนสำเร็จเลย สถานะจะกลับไปเป็นสถานะก่อนคืนเงิน
+func (s *returnService) releaseReturnRefund(id uint, amount float64, restore bool, previous domain.ReturnStatus) error {
+	return s.uow.Execute(func(repos *datastore.Repositories) error {
+		ret, err := repos.Return.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if _, err := repos.Order.LockByID(ret.OrderID); err != nil {
+			return err
+		}
+		if ret, err = repos.Return.FindByID(id); err != nil {
+			return err
+		}
+		ret.RefundedAmount = math.Max(domain.RoundMoney(ret.RefundedAmount-amount), 0)
+		if restore {
+			ret.Status = previous
+		}
+		return repos.Return.Update(ret)
+	})
+}
+
+// review อนุมัติหรือปฏิเสธคำขอที่รอตรวจสอบ
+func (s *returnService) review(adminID, id uint, next domain.ReturnStatus, note string) (*dto.ReturnResponse, error) {
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		ret, err := repos.Return.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if !ret.Status.CanTransitionTo(next) {
+			return fmt.Errorf("%w: cannot change status from '%s' to '%s'", ErrInvalidReturnStatus, ret.Status, next)
+		}
+		now := time.Now()
+		ret.Status = next
+		ret.AdminNote = note
+		ret.ReviewedBy = &adminID
+		ret.ReviewedAt = &now
+		return repos.Return.Update(ret)
+	})
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	return s.GetReturn(adminID, true, id)
+}
+
+func (s *returnService) findReturn(id uint) (*domain.ReturnRequest, error) {
+	ret, err := s.uow.ReturnRepository().FindByID(id)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	return ret, nil
+}
+
+func (s *returnService) deletePhotos(ctx context.Context, photos []domain.ReturnPhoto) {
+	for _, photo := range photos {
+		if err := s.uow.UploadRepository().DeleteFile(ctx, photo.Path); err != nil {
+			log.Printf("WARNING: failed to delete return photo %s: %v", photo.Path, err)
+		}
+	}
+}
+
+// buildReturnItems ตรวจว่าแต่ละรายการเป็นของ Order นี้ และจำนวนรวมกับคำขออื่นไม่เกินจำนวนที่ซื้อ
+func buildReturnItems(orderItems []domain.OrderItem, existing []domain.ReturnRequest, requested []dto.ReturnItemRequest) ([]domain.ReturnItem, error) {
+	available := make(map[uint]uint, len(orderItems))
+	for _, item := range orderItems {
+		available[item.ID] = item.Quantity
+	}
+	for _, ret := range existing {
+		if ret.Status == domain.ReturnRejected {
+			continue
+		}
+		for _, item := range ret.Items {
+			available[item.OrderItemID] -= min(item.Quantity, available[item.OrderItemID])
+		}
+	}
+
+	items := make([]domain.ReturnItem, 0, len(requested))
+	for _, req := range requested {
+		if req.Quantity > available[req.OrderItemID] {
+			return nil, fmt.Errorf("%w: order item %d has only %d left to return", ErrInvalidReturnItems, req.OrderItemID, available[req.OrderItemID])
+		}
+		available[req.OrderItemID] -= req.Quantity
+		items = append(items, domain.ReturnItem{OrderItemID: req.OrderItemID, Quantity: req.Quantity})
+	}
+	return items, nil
+}
+
+// returnValue คือมูลค่าที่ลูกค้าจ่ายจริงของสินค้าที่ขอคืน
+// ปันส่วนส่วนลดและภาษี (กรณีราคาไม่รวมภาษี) ตามสัดส่วนราคา ไม่รวมค่าจัดส่งและค่าห่อของขวัญ
+func returnValue(ret *domain.ReturnRequest, order *domain.Order) float64 {
+	if order.TotalPrice <= 0 {
+		return 0
+	}
+	var subtotal float64
+	for _, item := range ret.Items {
+		subtotal += item.OrderItem.Price * float64(item.Quantity)
+	}
+	return domain.RoundMoney(subtotal * (order.FinalPrice - order.AddOnCost()) / order.TotalPrice)
+}
+
+func (s *returnService) mapReturnsToResponse(returns []domain.ReturnRequest) ([]dto.ReturnResponse, error) {
+	responses := make([]dto.ReturnResponse, 0, len(returns))
+	for i := range returns {
+		order, err := s.uow.OrderRepository().FindByID(returns[i].OrderID)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *s.mapReturnToResponse(&returns[i], order))
+	}
+	return responses, nil
+}
+
+func (s *returnService) mapReturnToResponse(ret *domain.ReturnRequest, order *domain.Order) *dto.ReturnResponse {
+	items := make([]dto.ReturnItemResponse, 0, len(ret.Items))
+	for _, item := range ret.Items {
+		name, sku := item.OrderItem.ProductName, item.OrderItem.ProductSKU
+		if name == "" {
+			name, sku = item.OrderItem.Product.Name, item.OrderItem.Product.SKU
+		}
+		items = append(items, dto.ReturnItemResponse{
+			OrderItemID: item.OrderItemID,
+			ProductID:   item.OrderItem.ProductID,
+			Name:        name,
+			Sku:         sku,
+			Price:       item.OrderItem.Price,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	photoURLs := make([]string, 0, len(ret.Photos))
+	for _, photo := range ret.Photos {
+		photoURLs = append(photoURLs, s.imageBaseURL+"/"+photo.Path)
+	}
+
+	return &dto.ReturnResponse{
+		ID:               ret.ID,
+		OrderID:          ret.OrderID,
+		UserID:           ret.UserID,
+		Status:           ret.Status,
+		Reason:           ret.Reason,
+		AdminNote:        ret.AdminNote,
+		Items:            items,
+		PhotoURLs:        photoURLs,
+		RefundableAmount: returnValue(ret, order),
+		RefundedAmount:   ret.RefundedAmount,
+		Restocked:        ret.Restocked,
+		ReviewedAt:       ret.ReviewedAt,
+		ReceivedAt:       ret.ReceivedAt,
+		CreatedAt:        ret.CreatedAt,
+	}
+}
+
+func mapRepositoryError(err error) error {
+	if errors.Is(err, repository.ErrReturnNotFound) {
+		return ErrReturnNotFound
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package dto
+
+import "backend/domain"
+
+// ShippingZoneRequest คือ DTO สำหรับสร้างหรืออัปเดตโซนจัดส่ง
+type ShippingZoneRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Country     string `json:"country" validate:"max=100"`
+	States      string `json:"states" validate:"max=1000"`       // คั่นด้วยจุลภาค
+	PostalCodes string `json:"postal_codes" validate:"max=1000"` // เช่น "10110,10*,20000-20999"
+}
+
+// ShippingMethodRequest คือ DTO สำหรับสร้างหรืออัปเดตวิธีจัดส่ง
+type ShippingMethodRequest struct {
+	ZoneID        *uint                     `json:"zone_id"`
+	Name          string                    `json:"name" validate:"required,max=100"`
+	Carrier       string                    `json:"carrier" validate:"max=100"`
+	Type          domain.ShippingMethodType `json:"type" validate:"required,oneof=flat_rate weight_based free_over_threshold store_pickup"`
+	Rate          float64                   `json:"rate" validate:"gte=0"`
+	PerKgRate     float64                   `json:"per_kg_rate" validate:"gte=0"`
+	FreeThreshold float64                   `json:"free_threshold" validate:"gte=0"`
+	IsActive      bool                      `json:"is_active"`
+	SortOrder     int                       `json:"sort_order"`
+}
+
+// GiftWrapRequest คือ DTO สำหรับสร้างหรืออัปเดตบริการห่อของขวัญ
+type GiftWrapRequest struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description string  `json:"description" validate:"max=500"`
+	Price       float64 `json:"price" validate:"gte=0"`
+	IsActive    bool    `json:"is_active"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+type ShippingZoneResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Country     string `json:"country"`
+	States      string `json:"states"`
+	PostalCodes string `json:"postal_codes"`
+}
+
+type ShippingMethodResponse struct {
+	ID            uint                      `json:"id"`
+	ZoneID        *uint                     `json:"zone_id"`
+	ZoneName      string                    `json:"zone_name,omitempty"`
+	Name          string                    `json:"name"`
+	Carrier       string                    `json:"carrier"`
+	Type          domain.ShippingMethodType `json:"type"`
+	Rate          float64                   `json:"rate"`
+	PerKgRate     float64                   `json:"per_kg_rate"`
+	FreeThreshold float64                   `json:"free_threshold"`
+	IsActive      bool                      `json:"is_active"`
+	SortOrder     int                       `json:"sort_order"`
+}
+
+type GiftWrapResponse struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Price       float64 `json:"price"`
+	IsActive    bool    `json:"is_active"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+// ShippingRateResponse คือวิธีจัดส่งที่ใช้ได้พร้อมค่าจัดส่งสำหรับตะกร้าปัจจุบัน
+type ShippingRateResponse struct {
+	MethodID uint                      `json:"method_id"`
+	Name     string                    `json:"name"`
+	Carrier  string                    `json:"carrier"`
+	Type     domain.ShippingMethodType `json:"type"`
+	Cost     float64                   `json:"cost"`
+}
+
+// ShippingQuoteResponse คือ DTO สำหรับ Response ของการขอราคาค่าจัดส่ง
+type ShippingQuoteResponse struct {
+	AddressID uint                   `json:"address_id"`
+	Subtotal  float64                `json:"subtotal"`  // ยอดสินค้าหลังหักส่วนลด (ใช้เทียบเกณฑ์ส่งฟรี)
+	WeightKg  float64                `json:"weight_kg"` // น้ำหนักรวมของตะกร้า
+	Rates     []ShippingRateResponse `json:"rates"`
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"backend/middleware"
+	"backend/shipping/dto"
+	"backend/shipping/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShippingHandler struct {
+	shippingSvc service.ShippingService
+}
+
+func NewShippingHandler(shippingSvc service.ShippingService) *ShippingHandler {
+	return &ShippingHandler{shippingSvc: shippingSvc}
+}
+
+// HandleQuote คืนวิธีจัดส่งและค่าจัดส่งของตะกร้าปัจจุบัน (?address_id=)
+func (h *ShippingHandler) HandleQuote(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*middleware.JwtClaims)
+
+	addressID := c.QueryInt("address_id")
+	if addressID <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "address_id is required")
+	}
+
+	res, err := h.shippingSvc.QuoteCart(claims.UserID, uint(addressID))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *ShippingHandler) HandleCreateZone(c *fiber.Ctx) error {
+	var req dto.ShippingZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.shippingSvc.CreateZone(req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+func (h *ShippingHandler) HandleGetAllZones(c *fiber.Ctx) error {
+	res, err := h.shippingSvc.GetAllZones()
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *ShippingHandler) HandleUpdateZone(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid shipping zone ID")
+	}
+	var req dto.ShippingZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.shippingSvc.UpdateZone(uint(id), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *ShippingHandler) HandleDeleteZone(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid shipping zone ID")
+	}
+	if err := h.shippingSvc.DeleteZone(uint(id)); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *ShippingHandler) HandleCreateMethod(c *fiber.Ctx) error {
+	var req dto.ShippingMethodRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.shippingSvc.CreateMethod(req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+func (h *ShippingHandler) HandleGetAllMethods(c *fiber.Ctx) error {
+	res, err := h.shippingSvc.GetAllMethods()
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *ShippingHandler) HandleUpdateMethod(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid shipping method ID")
+	}
+	var req dto.ShippingMethodRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.shippingSvc.UpdateMethod(uint(id), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *ShippingHandler) HandleDeleteMethod(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid shipping method ID")
+	}
+	if err := h.shippingSvc.DeleteMethod(uint(id)); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleGetGiftWraps คืนบริการห่อของขวัญที่ลูกค้าเลือกได้ตอน Checkout
+func (h *ShippingHandler) HandleGetGiftWraps(c *fiber.Ctx) error {
+	res, err := h.shippingSvc.GetGiftWraps(true)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *ShippingHandler) HandleGetAllGiftWraps(c *fiber.Ctx) error {
+	res, err := h.shippingSvc.GetGiftWraps(false)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *ShippingHandler) HandleCreateGiftWrap(c *fiber.Ctx) error {
+	var req dto.GiftWrapRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.shippingSvc.CreateGiftWrap(req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+func (h *ShippingHandler) HandleUpdateGiftWrap(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid gift wrap ID")
+	}
+	var req dto.GiftWrapRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.shippingSvc.UpdateGiftWrap(uint(id), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *ShippingHandler) HandleDeleteGiftWrap(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid gift wrap ID")
+	}
+	if err := h.shippingSvc.DeleteGiftWrap(uint(id)); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+type ShippingRepository interface {
+	CreateZone(zone *domain.ShippingZone) error
+	FindAllZones() ([]domain.ShippingZone, error)
+	FindZoneByID(id uint) (*domain.ShippingZone, error)
+	UpdateZone(zone *domain.ShippingZone) error
+	DeleteZone(id uint) error
+
+	CreateMethod(method *domain.ShippingMethod) error
+	FindAllMethods() ([]domain.ShippingMethod, error)
+	FindActiveMethods() ([]domain.ShippingMethod, error)
+	FindMethodByID(id uint) (*domain.ShippingMethod, error)
+	UpdateMethod(method *domain.ShippingMethod) error
+	DeleteMethod(id uint) error
+
+	CreateGiftWrap(option *domain.GiftWrapOption) error
+	FindAllGiftWraps() ([]domain.GiftWrapOption, error)
+	FindActiveGiftWraps() ([]domain.GiftWrapOption, error)
+	FindGiftWrapByID(id uint) (*domain.GiftWrapOption, error)
+	UpdateGiftWrap(option *domain.GiftWrapOption) error
+	DeleteGiftWrap(id uint) error
+}
+
+type shippingRepository struct {
+	db *gorm.DB
+}
+
+func NewShippingRepository(db *gorm.DB) ShippingRepository {
+	return &shippingRepository{db: db}
+}
+
+func (r *shippingRepository) CreateZone(zone *domain.ShippingZone) error {
+	return r.db.Create(zone).Error
+}
+
+func (r *shippingRepository) FindAllZones() ([]domain.ShippingZone, error) {
+	var zones []domain.ShippingZone
+	err := r.db.Order("name asc").Find(&zones).Error
+	return zones, err
+}
+
+func (r *shippingRepository) FindZoneByID(id uint) (*domain.ShippingZone, error) {
+	var zone domain.ShippingZone
+	err := r.db.First(&zone, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &zone, err
+}
+
+func (r *shippingRepository) UpdateZone(zone *domain.ShippingZone) error {
+	return r.db.Save(zone).Error
+}
+
+// DeleteZone ลบโซนและวิธีจัดส่งทั้งหมดที่ผูกกับโซนนี้
+func (r *shippingRepository) DeleteZone(id uint) error {
+	if err := r.db.Where("zone_id = ?", id).Delete(&domain.ShippingMethod{}).Error; err != nil {
+		return err
+	}
+	result := r.db.Delete(&domain.ShippingZone{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *shippingRepository) CreateMethod(method *domain.ShippingMethod) error {
+	return r.db.Omit("Zone").Create(method).Error
+}
+
+func (r *shippingRepository) FindAllMethods() ([]domain.ShippingMethod, error) {
+	var methods []domain.ShippingMethod
+	err := r.db.Preload("Zone").Order("sort_order asc, id asc").Find(&methods).Error
+	return methods, err
+}
+
+func (r *shippingRepository) FindActiveMethods() ([]domain.ShippingMethod, error) {
+	var methods []domain.ShippingMethod
+	err := r.db.Preload("Zone").Where("is_active = ?", true).Order("sort_order asc, id asc").Find(&methods).Error
+	return methods, err
+}
+
+func (r *shippingRepository) FindMethodByID(id uint) (*domain.ShippingMethod, error) {
+	var method domain.ShippingMethod
+	err := r.db.Preload("Zone").First(&method, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &method, err
+}
+
+func (r *shippingRepository) UpdateMethod(method *domain.ShippingMethod) error {
+	return r.db.Omit("Zone").Save(method).Error
+}
+
+func (r *shippingRepository) DeleteMethod(id uint) error {
+	result := r.db.Delete(&domain.ShippingMethod{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *shippingRepository) CreateGiftWrap(option *domain.GiftWrapOption) error {
+	return r.db.Create(option).Error
+}
+
+func (r *shippingRepository) FindAllGiftWraps() ([]domain.GiftWrapOption, error) {
+	var options []domain.GiftWrapOption
+	err := r.db.Order("sort_order asc, id asc").Find(&options).Error
+	return options, err
+}
+
+func (r *shippingRepository) FindActiveGiftWraps() ([]domain.GiftWrapOption, error) {
+	var options []domain.GiftWrapOption
+	err := r.db.Where("is_active = ?", true).Order("sort_order asc, id asc").Find(&options).Error
+	return options, err
+}
+
+func (r *shippingRepository) FindGiftWrapByID(id uint) (*domain.GiftWrapOption, error) {
+	var option domain.GiftWrapOption
+	err := r.db.First(&option, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &option, err
+}
+
+func (r *shippingRepository) UpdateGiftWrap(option *domain.GiftWrapOption) error {
+	return r.db.Save(option).Error
+}
+
+func (r *shippingRepository) DeleteGiftWrap(id uint) error {
+	result := r.db.Delete(&domain.GiftWrapOption{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/internal/datastore"
+	"backend/shipping/dto"
+	"backend/shipping/repository"
+	"errors"
+)
+
+var (
+	ErrGiftWrapNotFound    = errors.New("gift wrap option not found")
+	ErrGiftWrapUnavailable = errors.New("gift wrap option is not available")
+)
+
+func (s *shippingService) CreateGiftWrap(req dto.GiftWrapRequest) (*dto.GiftWrapResponse, error) {
+	option := &domain.GiftWrapOption{}
+	applyGiftWrapRequest(option, req)
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		return repos.Shipping.CreateGiftWrap(option)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapGiftWrapToResponse(option), nil
+}
+
+// GetGiftWraps ดึงบริการห่อของขวัญ (activeOnly = เฉพาะที่ลูกค้าเลือกได้ตอน Checkout)
+func (s *shippingService) GetGiftWraps(activeOnly bool) ([]dto.GiftWrapResponse, error) {
+	var options []domain.GiftWrapOption
+	var err error
+	if activeOnly {
+		options, err = s.uow.ShippingRepository().FindActiveGiftWraps()
+	} else {
+		options, err = s.uow.ShippingRepository().FindAllGiftWraps()
+	}
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.GiftWrapResponse, 0, len(options))
+	for i := range options {
+		responses = append(responses, *mapGiftWrapToResponse(&options[i]))
+	}
+	return responses, nil
+}
+
+func (s *shippingService) UpdateGiftWrap(id uint, req dto.GiftWrapRequest) (*dto.GiftWrapResponse, error) {
+	var option *domain.GiftWrapOption
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		option, err = repos.Shipping.FindGiftWrapByID(id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrGiftWrapNotFound
+			}
+			return err
+		}
+		applyGiftWrapRequest(option, req)
+		return repos.Shipping.UpdateGiftWrap(option)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapGiftWrapToResponse(option), nil
+}
+
+func (s *shippingService) DeleteGiftWrap(id uint) error {
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		return repos.Shipping.DeleteGiftWrap(id)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrGiftWrapNotFound
+	}
+	return err
+}
+
+// GiftWrapForCheckout คืนบริการห่อของขวัญที่ลูกค้าเลือก ถ้าถูกลบหรือปิดใช้งานแล้วจะคืน ErrGiftWrapUnavailable
+// repo ส่งเข้ามาได้ทั้งแบบปกติและแบบที่อยู่ใน Transaction (ใช้ร่วมกันระหว่าง Quote และ Checkout)
+func GiftWrapForCheckout(repo repository.ShippingRepository, id uint) (*domain.GiftWrapOption, error) {
+	option, err := repo.FindGiftWrapByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrGiftWrapUnavailable
+		}
+		return nil, err
+	}
+	if !option.IsActive {
+		return nil, ErrGiftWrapUnavailable
+	}
+	return option, nil
+}
+
+func applyGiftWrapRequest(option *domain.GiftWrapOption, req dto.GiftWrapRequest) {
+	option.Name = req.Name
+	option.Description = req.Description
+	option.Price = req.Price
+	option.IsActive = req.IsActive
+	option.SortOrder = req.SortOrder
+}
+
+func mapGiftWrapToResponse(option *domain.GiftWrapOption) *dto.GiftWrapResponse {
+	return &dto.GiftWrapResponse{
+		ID:          option.ID,
+		Name:        option.Name,
+		Description: option.Description,
+		Price:       option.Price,
+		IsActive:    option.IsActive,
+		SortOrder:   option.SortOrder,
+	}
+}
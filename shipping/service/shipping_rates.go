@@ -0,0 +1,67 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/shipping/repository"
+	"errors"
+)
+
+var ErrShippingMethodUnavailable = errors.New("shipping method is not available for this address")
+
+// Parcel คือข้อมูลของสินค้าที่จะจัดส่งที่ใช้คิดค่าจัดส่ง
+type Parcel struct {
+	Subtotal float64 // ยอดสินค้าหลังหักส่วนลด
+	WeightKg float64
+}
+
+// Rate คือวิธีจัดส่งที่ใช้ได้พร้อมค่าจัดส่ง
+type Rate struct {
+	Method domain.ShippingMethod
+	Cost   float64
+}
+
+// AvailableRates คืนวิธีจัดส่งทั้งหมดที่ใช้ได้กับที่อยู่นี้ พร้อมคำนวณค่าจัดส่ง
+// ที่อยู่จะถูกจับคู่กับโซนที่เจาะจงที่สุดเพียงโซนเดียว แล้วรวมกับวิธีจัดส่งที่ไม่ผูกโซน
+// repo ส่งเข้ามาได้ทั้งแบบปกติและแบบที่อยู่ใน Transaction (ใช้ร่วมกันระหว่าง Quote และ Checkout)
+func AvailableRates(repo repository.ShippingRepository, address *domain.Address, parcel Parcel) ([]Rate, error) {
+	methods, err := repo.FindActiveMethods()
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := repo.FindAllZones()
+	if err != nil {
+		return nil, err
+	}
+	var zoneID *uint
+	bestScore := -1
+	for i := range zones {
+		if ok, score := zones[i].Match(address); ok && score > bestScore {
+			zoneID = &zones[i].ID
+			bestScore = score
+		}
+	}
+
+	rates := make([]Rate, 0, len(methods))
+	for _, m := range methods {
+		if m.ZoneID != nil && (zoneID == nil || *m.ZoneID != *zoneID) {
+			continue
+		}
+		rates = append(rates, Rate{Method: m, Cost: domain.RoundMoney(m.CalculateCost(parcel.Subtotal, parcel.WeightKg))})
+	}
+	return rates, nil
+}
+
+// RateForMethod คืนค่าจัดส่งของวิธีที่เลือก ถ้าวิธีนั้นใช้กับที่อยู่นี้ไม่ได้จะคืน ErrShippingMethodUnavailable
+func RateForMethod(repo repository.ShippingRepository, methodID uint, address *domain.Address, parcel Parcel) (*Rate, error) {
+	rates, err := AvailableRates(repo, address, parcel)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rates {
+		if rates[i].Method.ID == methodID {
+			return &rates[i], nil
+		}
+	}
+	return nil, ErrShippingMethodUnavailable
+}
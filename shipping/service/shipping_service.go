@@ -0,0 +1,276 @@
+package service
+
+import (
+	cartRepo "backend/carts/repository"
+	"backend/domain"
+	"backend/internal/datastore"
+	"backend/shipping/dto"
+	"backend/shipping/repository"
+	userRepo "backend/users/repository"
+	"errors"
+)
+
+var (
+	ErrShippingZoneNotFound   = errors.New("shipping zone not found")
+	ErrShippingMethodNotFound = errors.New("shipping method not found")
+	ErrAddressNotFound        = errors.New("shipping address not found")
+)
+
+type ShippingService interface {
+	CreateZone(req dto.ShippingZoneRequest) (*dto.ShippingZoneResponse, error)
+	GetAllZones() ([]dto.ShippingZoneResponse, error)
+	UpdateZone(id uint, req dto.ShippingZoneRequest) (*dto.ShippingZoneResponse, error)
+	DeleteZone(id uint) error
+
+	CreateMethod(req dto.ShippingMethodRequest) (*dto.ShippingMethodResponse, error)
+	GetAllMethods() ([]dto.ShippingMethodResponse, error)
+	UpdateMethod(id uint, req dto.ShippingMethodRequest) (*dto.ShippingMethodResponse, error)
+	DeleteMethod(id uint) error
+
+	CreateGiftWrap(req dto.GiftWrapRequest) (*dto.GiftWrapResponse, error)
+	GetGiftWraps(activeOnly bool) ([]dto.GiftWrapResponse, error)
+	UpdateGiftWrap(id uint, req dto.GiftWrapRequest) (*dto.GiftWrapResponse, error)
+	DeleteGiftWrap(id uint) error
+
+	QuoteCart(userID, addressID uint) (*dto.ShippingQuoteResponse, error)
+}
+
+type shippingService struct {
+	uow datastore.UnitOfWork
+}
+
+func NewShippingService(uow datastore.UnitOfWork) ShippingService {
+	return &shippingService{uow: uow}
+}
+
+func (s *shippingService) CreateZone(req dto.ShippingZoneRequest) (*dto.ShippingZoneResponse, error) {
+	zone := &domain.ShippingZone{}
+	applyZoneRequest(zone, req)
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		return repos.Shipping.CreateZone(zone)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapZoneToResponse(zone), nil
+}
+
+func (s *shippingService) GetAllZones() ([]dto.ShippingZoneResponse, error) {
+	zones, err := s.uow.ShippingRepository().FindAllZones()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.ShippingZoneResponse, 0, len(zones))
+	for i := range zones {
+		responses = append(responses, *mapZoneToResponse(&zones[i]))
+	}
+	return responses, nil
+}
+
+func (s *shippingService) UpdateZone(id uint, req dto.ShippingZoneRequest) (*dto.ShippingZoneResponse, error) {
+	var zone *domain.ShippingZone
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		zone, err = repos.Shipping.FindZoneByID(id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrShippingZoneNotFound
+			}
+			return err
+		}
+		applyZoneRequest(zone, req)
+		return repos.Shipping.UpdateZone(zone)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapZoneToResponse(zone), nil
+}
+
+func (s *shippingService) DeleteZone(id uint) error {
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		return repos.Shipping.DeleteZone(id)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrShippingZoneNotFound
+	}
+	return err
+}
+
+func (s *shippingService) CreateMethod(req dto.ShippingMethodRequest) (*dto.ShippingMethodResponse, error) {
+	method := &domain.ShippingMethod{}
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		if err := applyMethodRequest(repos, method, req); err != nil {
+			return err
+		}
+		return repos.Shipping.CreateMethod(method)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapMethodToResponse(method), nil
+}
+
+func (s *shippingService) GetAllMethods() ([]dto.ShippingMethodResponse, error) {
+	methods, err := s.uow.ShippingRepository().FindAllMethods()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.ShippingMethodResponse, 0, len(methods))
+	for i := range methods {
+		responses = append(responses, *mapMethodToResponse(&methods[i]))
+	}
+	return responses, nil
+}
+
+func (s *shippingService) UpdateMethod(id uint, req dto.ShippingMethodRequest) (*dto.ShippingMethodResponse, error) {
+	var method *domain.ShippingMethod
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		method, err = repos.Shipping.FindMethodByID(id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrShippingMethodNotFound
+			}
+			return err
+		}
+		if err := applyMethodRequest(repos, method, req); err != nil {
+			return err
+		}
+		return repos.Shipping.UpdateMethod(method)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapMethodToResponse(method), nil
+}
+
+func (s *shippingService) DeleteMethod(id uint) error {
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		return repos.Shipping.DeleteMethod(id)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrShippingMethodNotFound
+	}
+	return err
+}
+
+// QuoteCart คืนวิธีจัดส่งที่ใช้ได้และค่าจัดส่งของตะกร้าปัจจุบันไปยังที่อยู่ที่เลือก
+func (s *shippingService) QuoteCart(userID, addressID uint) (*dto.ShippingQuoteResponse, error) {
+	address, err := s.uow.AddressRepository().FindByID(addressID)
+	if err != nil {
+		if errors.Is(err, userRepo.ErrNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	if address.UserID != userID {
+		return nil, ErrAddressNotFound
+	}
+
+	// ตะกร้าว่างก็ยังขอราคาได้ (ค่าจัดส่งคิดจากยอด 0 และน้ำหนัก 0)
+	var parcel Parcel
+	cart, err := s.uow.CartRepository().GetCartByUserID(userID)
+	switch {
+	case err == nil:
+		parcel = ParcelFromCart(cart)
+	case !errors.Is(err, cartRepo.ErrNotFound):
+		return nil, err
+	}
+
+	rates, err := AvailableRates(s.uow.ShippingRepository(), address, parcel)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ShippingQuoteResponse{
+		AddressID: address.ID,
+		Subtotal:  parcel.Subtotal,
+		WeightKg:  parcel.WeightKg,
+		Rates:     make([]dto.ShippingRateResponse, 0, len(rates)),
+	}
+	for _, r := range rates {
+		response.Rates = append(response.Rates, dto.ShippingRateResponse{
+			MethodID: r.Method.ID,
+			Name:     r.Method.Name,
+			Carrier:  r.Method.Carrier,
+			Type:     r.Method.Type,
+			Cost:     r.Cost,
+		})
+	}
+	return response, nil
+}
+
+// ParcelFromCart คำนวณยอดสินค้าหลังหักส่วนลดและน้ำหนักรวมของตะกร้า
+func ParcelFromCart(cart *domain.Cart) Parcel {
+	var parcel Parcel
+	for _, item := range cart.Items {
+		parcel.Subtotal += item.Product.Price * float64(item.Quantity)
+		parcel.WeightKg += item.Product.Weight * float64(item.Quantity)
+	}
+	if cart.Coupon != nil && cart.Coupon.ID != 0 {
+		parcel.Subtotal -= cart.Coupon.CalculateDiscount(parcel.Subtotal)
+	}
+	return parcel
+}
+
+func applyZoneRequest(zone *domain.ShippingZone, req dto.ShippingZoneRequest) {
+	zone.Name = req.Name
+	zone.Country = req.Country
+	zone.States = req.States
+	zone.PostalCodes = req.PostalCodes
+}
+
+// applyMethodRequest ตรวจสอบโซน (ถ้าระบุ) แล้วคัดลอกข้อมูลจาก Request ลงใน method
+func applyMethodRequest(repos *datastore.Repositories, method *domain.ShippingMethod, req dto.ShippingMethodRequest) error {
+	method.Zone = nil
+	if req.ZoneID != nil {
+		zone, err := repos.Shipping.FindZoneByID(*req.ZoneID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrShippingZoneNotFound
+			}
+			return err
+		}
+		method.Zone = zone
+	}
+	method.ZoneID = req.ZoneID
+	method.Name = req.Name
+	method.Carrier = req.Carrier
+	method.Type = req.Type
+	method.Rate = req.Rate
+	method.PerKgRate = req.PerKgRate
+	method.FreeThreshold = req.FreeThreshold
+	method.IsActive = req.IsActive
+	method.SortOrder = req.SortOrder
+	return nil
+}
+
+func mapZoneToResponse(zone *domain.ShippingZone) *dto.ShippingZoneResponse {
+	return &dto.ShippingZoneResponse{
+		ID:          zone.ID,
+		Name:        zone.Name,
+		Country:     zone.Country,
+		States:      zone.States,
+		PostalCodes: zone.PostalCodes,
+	}
+}
+
+func mapMethodToResponse(method *domain.ShippingMethod) *dto.ShippingMethodResponse {
+	response := &dto.ShippingMethodResponse{
+		ID:            method.ID,
+		ZoneID:        method.ZoneID,
+		Name:          method.Name,
+		Carrier:       method.Carrier,
+		Type:          method.Type,
+		Rate:          method.Rate,
+		PerKgRate:     method.PerKgRate,
+		FreeThreshold: method.FreeThreshold,
+		IsActive:      method.IsActive,
+		SortOrder:     method.SortOrder,
+	}
+	if method.Zone != nil {
+		response.ZoneName = method.Zone.Name
+	}
+	return response
+}
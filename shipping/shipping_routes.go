@@ -0,0 +1,36 @@
+package shipping
+
+import (
+	"backend/config"
+	"backend/internal/datastore"
+	"backend/middleware"
+	"backend/shipping/handler"
+	"backend/shipping/service"
+	"github.com/gofiber/fiber/v2"
+	"log"
+)
+
+func RegisterModule(api fiber.Router, uow datastore.UnitOfWork, cfg *config.Config) {
+	shippingSvc := service.NewShippingService(uow)
+	shippingHdl := handler.NewShippingHandler(shippingSvc)
+
+	shippingAPI := api.Group("/shipping", middleware.Protected())
+	shippingAPI.Get("/quote", shippingHdl.HandleQuote)
+	shippingAPI.Get("/gift-wraps", shippingHdl.HandleGetGiftWraps)
+
+	adminAPI := api.Group("/admin/shipping", middleware.Protected(), middleware.AdminRequired())
+	adminAPI.Get("/zones", shippingHdl.HandleGetAllZones)
+	adminAPI.Post("/zones", shippingHdl.HandleCreateZone)
+	adminAPI.Put("/zones/:id", shippingHdl.HandleUpdateZone)
+	adminAPI.Delete("/zones/:id", shippingHdl.HandleDeleteZone)
+	adminAPI.Get("/methods", shippingHdl.HandleGetAllMethods)
+	adminAPI.Post("/methods", shippingHdl.HandleCreateMethod)
+	adminAPI.Put("/methods/:id", shippingHdl.HandleUpdateMethod)
+	adminAPI.Delete("/methods/:id", shippingHdl.HandleDeleteMethod)
+	adminAPI.Get("/gift-wraps", shippingHdl.HandleGetAllGiftWraps)
+	adminAPI.Post("/gift-wraps", shippingHdl.HandleCreateGiftWrap)
+	adminAPI.Put("/gift-wraps/:id", shippingHdl.HandleUpdateGiftWrap)
+	adminAPI.Delete("/gift-wraps/:id", shippingHdl.HandleDeleteGiftWrap)
+
+	log.Println("✅ Shipping module registered successfully.")
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// TaxClassRequest คือ DTO สำหรับสร้าง Tax Class
+type TaxClassRequest struct {
+	Code string `json:"code" validate:"required,max=50"`
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// TaxRateRequest คือ DTO สำหรับสร้างหรืออัปเดตอัตราภาษี
+type TaxRateRequest struct {
+	TaxClassID uint    `json:"tax_class_id" validate:"required"`
+	Name       string  `json:"name" validate:"required,max=100"`
+	Country    string  `json:"country" validate:"max=100"` // ว่าง = ทุกประเทศ
+	State      string  `json:"state" validate:"max=100"`   // ว่าง = ทั้งประเทศ
+	Rate       float64 `json:"rate" validate:"gte=0,lt=1"` // 0.07 = 7%
+}
+
+type TaxClassResponse struct {
+	ID        uint      `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TaxRateResponse struct {
+	ID           uint    `json:"id"`
+	TaxClassID   uint    `json:"tax_class_id"`
+	TaxClassCode string  `json:"tax_class_code"`
+	Name         string  `json:"name"`
+	Country      string  `json:"country"`
+	State        string  `json:"state"`
+	Rate         float64 `json:"rate"`
+}
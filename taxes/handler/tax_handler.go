@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"backend/taxes/dto"
+	"backend/taxes/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TaxHandler struct {
+	taxSvc service.TaxService
+}
+
+func NewTaxHandler(taxSvc service.TaxService) *TaxHandler {
+	return &TaxHandler{taxSvc: taxSvc}
+}
+
+func (h *TaxHandler) HandleCreateClass(c *fiber.Ctx) error {
+	var req dto.TaxClassRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.taxSvc.CreateClass(req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+func (h *TaxHandler) HandleGetAllClasses(c *fiber.Ctx) error {
+	res, err := h.taxSvc.GetAllClasses()
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *TaxHandler) HandleCreateRate(c *fiber.Ctx) error {
+	var req dto.TaxRateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.taxSvc.CreateRate(req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+func (h *TaxHandler) HandleGetAllRates(c *fiber.Ctx) error {
+	res, err := h.taxSvc.GetAllRates()
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *TaxHandler) HandleUpdateRate(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tax rate ID")
+	}
+	var req dto.TaxRateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+	res, err := h.taxSvc.UpdateRate(uint(id), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *TaxHandler) HandleDeleteRate(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tax rate ID")
+	}
+	if err := h.taxSvc.DeleteRate(uint(id)); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+type TaxRepository interface {
+	CreateClass(class *domain.TaxClass) error
+	FindAllClasses() ([]domain.TaxClass, error)
+	FindClassByID(id uint) (*domain.TaxClass, error)
+	FindClassByCode(code string) (*domain.TaxClass, error)
+
+	CreateRate(rate *domain.TaxRate) error
+	FindAllRates() ([]domain.TaxRate, error)
+	FindRateByID(id uint) (*domain.TaxRate, error)
+	UpdateRate(rate *domain.TaxRate) error
+	DeleteRate(id uint) error
+	FindRatesForCountry(country string) ([]domain.TaxRate, error)
+}
+
+type taxRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRepository(db *gorm.DB) TaxRepository {
+	return &taxRepository{db: db}
+}
+
+func (r *taxRepository) CreateClass(class *domain.TaxClass) error {
+	return r.db.Create(class).Error
+}
+
+func (r *taxRepository) FindAllClasses() ([]domain.TaxClass, error) {
+	var classes []domain.TaxClass
+	err := r.db.Order("code asc").Find(&classes).Error
+	return classes, err
+}
+
+func (r *taxRepository) FindClassByID(id uint) (*domain.TaxClass, error) {
+	var class domain.TaxClass
+	err := r.db.First(&class, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &class, err
+}
+
+func (r *taxRepository) FindClassByCode(code string) (*domain.TaxClass, error) {
+	var class domain.TaxClass
+	err := r.db.Where("code = ?", code).First(&class).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &class, err
+}
+
+func (r *taxRepository) CreateRate(rate *domain.TaxRate) error {
+	return r.db.Create(rate).Error
+}
+
+func (r *taxRepository) FindAllRates() ([]domain.TaxRate, error) {
+	var rates []domain.TaxRate
+	err := r.db.Preload("TaxClass").Order("tax_class_id asc, country asc, state asc").Find(&rates).Error
+	return rates, err
+}
+
+func (r *taxRepository) FindRateByID(id uint) (*domain.TaxRate, error) {
+	var rate domain.TaxRate
+	err := r.db.Preload("TaxClass").First(&rate, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &rate, err
+}
+
+func (r *taxRepository) UpdateRate(rate *domain.TaxRate) error {
+	return r.db.Omit("TaxClass").Save(rate).Error
+}
+
+func (r *taxRepository) DeleteRate(id uint) error {
+	result := r.db.Delete(&domain.TaxRate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FindRatesForCountry ดึงอัตราภาษีที่ใช้กับประเทศนี้ได้ (รวมอัตราที่ไม่ระบุประเทศ)
+// การเลือกอัตราตามภูมิภาค (State) ทำใน Tax Engine
+func (r *taxRepository) FindRatesForCountry(country string) ([]domain.TaxRate, error) {
+	var rates []domain.TaxRate
+	err := r.db.Preload("TaxClass").
+		Where("country = '' OR LOWER(country) = LOWER(?)", country).
+		Find(&rates).Error
+	return rates, err
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"backend/domain"
+	"backend/taxes/repository"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Settings คือค่าตั้งต้นของการคำนวณภาษีทั้งระบบ
+type Settings struct {
+	PricesIncludeTax bool   // ราคาสินค้าที่ตั้งไว้รวมภาษีแล้วหรือไม่ (ร้านในไทยส่วนใหญ่ตั้งราคารวม VAT)
+	DefaultClassCode string // Tax Class ที่ใช้กับสินค้าที่ไม่ได้ระบุ TaxClassID
+	DefaultCountry   string // ประเทศที่ใช้คำนวณเมื่อยังไม่รู้ที่อยู่จัดส่ง (เช่น ในตะกร้า)
+}
+
+// Region คือพื้นที่ที่ใช้เลือกอัตราภาษี
+type Region struct {
+	Country string
+	State   string
+}
+
+// Line คือสินค้า 1 รายการที่ต้องการคำนวณภาษี
+type Line struct {
+	ProductID  uint
+	TaxClassID *uint
+	UnitPrice  float64
+	Quantity   uint
+}
+
+// LineTax คือผลการคำนวณภาษีของสินค้า 1 รายการ (เรียงตามลำดับของ Line ที่ส่งเข้ามา)
+type LineTax struct {
+	ProductID    uint
+	TaxClassCode string
+	RateName     string
+	Rate         float64
+	Discount     float64 // ส่วนลดที่ปันส่วนมาให้รายการนี้
+	Net          float64 // มูลค่าหลังหักส่วนลด ไม่รวมภาษี
+	Tax          float64
+}
+
+// RateSummary คือยอดภาษีรวมของแต่ละอัตรา
+type RateSummary struct {
+	TaxClassCode string
+	Name         string
+	Rate         float64
+	Taxable      float64
+	Tax          float64
+}
+
+// Result คือผลการคำนวณภาษีของตะกร้าหรือ Order ทั้งก้อน
+type Result struct {
+	PricesIncludeTax bool
+	Subtotal         float64 // ราคารวมตามราคาสินค้า ก่อนหักส่วนลด
+	Discount         float64
+	Net              float64 // หลังหักส่วนลด ไม่รวมภาษี
+	Tax              float64
+	Total            float64 // ยอดที่ต้องจ่าย (รวมภาษี)
+	Lines            []LineTax
+	Summaries        []RateSummary
+}
+
+// Engine คำนวณภาษีของรายการสินค้าตาม Tax Class ของสินค้าและพื้นที่จัดส่ง
+type Engine struct {
+	settings Settings
+}
+
+func NewEngine(settings Settings) *Engine {
+	return &Engine{settings: settings}
+}
+
+// RegionFor คืนพื้นที่ของที่อยู่ ถ้าไม่มีที่อยู่จะใช้ประเทศเริ่มต้น
+func (e *Engine) RegionFor(address *domain.Address) Region {
+	if address == nil || address.Country == "" {
+		return Region{Country: e.settings.DefaultCountry}
+	}
+	return Region{Country: address.Country, State: address.State}
+}
+
+// Calculate คำนวณภาษีรายบรรทัดและรวมทั้งก้อน
+// discount (เช่น จากคูปอง) จะถูกปันส่วนตามสัดส่วนราคาของแต่ละรายการก่อนคิดภาษี
+// repo ส่งเข้ามาได้ทั้งแบบปกติและแบบที่อยู่ใน Transaction
+func (e *Engine) Calculate(repo repository.TaxRepository, region Region, lines []Line, discount float64) (*Result, error) {
+	rates, err := repo.FindRatesForCountry(region.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultClassID *uint
+	if e.settings.DefaultClassCode != "" {
+		class, err := repo.FindClassByCode(e.settings.DefaultClassCode)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		if class != nil {
+			defaultClassID = &class.ID
+		}
+	}
+
+	return calculate(lines, bestRates(rates, region), defaultClassID, e.settings.PricesIncludeTax, discount), nil
+}
+
+// bestRates เลือกอัตราที่เจาะจงที่สุดของแต่ละ Tax Class (ประเทศ+ภูมิภาค > ประเทศ > ไม่ระบุ)
+func bestRates(rates []domain.TaxRate, region Region) map[uint]*domain.TaxRate {
+	best := make(map[uint]*domain.TaxRate)
+	bestScore := make(map[uint]int)
+	for i := range rates {
+		rate := &rates[i]
+		score := 0
+		if rate.Country != "" {
+			if !strings.EqualFold(rate.Country, region.Country) {
+				continue
+			}
+			score += 2
+		}
+		if rate.State != "" {
+			if !strings.EqualFold(rate.State, region.State) {
+				continue
+			}
+			score++
+		}
+		if current, ok := bestScore[rate.TaxClassID]; !ok || score > current {
+			best[rate.TaxClassID] = rate
+			bestScore[rate.TaxClassID] = score
+		}
+	}
+	return best
+}
+
+func calculate(lines []Line, rates map[uint]*domain.TaxRate, defaultClassID *uint, inclusive bool, discount float64) *Result {
+	result := &Result{PricesIncludeTax: inclusive, Lines: make([]LineTax, 0, len(lines))}
+
+	for _, l := range lines {
+		result.Subtotal += l.UnitPrice * float64(l.Quantity)
+	}
+	result.Subtotal = domain.RoundMoney(result.Subtotal)
+	if discount > result.Subtotal {
+		discount = result.Subtotal
+	}
+	result.Discount = domain.RoundMoney(discount)
+
+	summaries := make(map[string]*RateSummary)
+	remainingDiscount := result.Discount
+	for i, l := range lines {
+		gross := l.UnitPrice * float64(l.Quantity)
+
+		// ปันส่วนส่วนลด รายการสุดท้ายรับเศษที่เหลือเพื่อให้ผลรวมตรงพอดี
+		var lineDiscount float64
+		if i == len(lines)-1 {
+			lineDiscount = remainingDiscount
+		} else if result.Subtotal > 0 {
+			lineDiscount = domain.RoundMoney(result.Discount * gross / result.Subtotal)
+		}
+		remainingDiscount = domain.RoundMoney(remainingDiscount - lineDiscount)
+		base := gross - lineDiscount
+
+		lt := LineTax{ProductID: l.ProductID, Discount: lineDiscount}
+		classID := l.TaxClassID
+		if classID == nil {
+			classID = defaultClassID
+		}
+		var rate *domain.TaxRate
+		if classID != nil {
+			rate = rates[*classID]
+		}
+
+		if rate == nil {
+			lt.Net = domain.RoundMoney(base)
+		} else {
+			lt.TaxClassCode = rate.TaxClass.Code
+			lt.RateName = rate.Name
+			lt.Rate = rate.Rate
+			if inclusive {
+				lt.Tax = domain.RoundMoney(base * rate.Rate / (1 + rate.Rate))
+				lt.Net = domain.RoundMoney(base - lt.Tax)
+			} else {
+				lt.Tax = domain.RoundMoney(base * rate.Rate)
+				lt.Net = domain.RoundMoney(base)
+			}
+
+			key := lt.TaxClassCode + "|" + lt.RateName
+			summary, ok := summaries[key]
+			if !ok {
+				summary = &RateSummary{TaxClassCode: lt.TaxClassCode, Name: lt.RateName, Rate: lt.Rate}
+				summaries[key] = summary
+			}
+			summary.Taxable = domain.RoundMoney(summary.Taxable + lt.Net)
+			summary.Tax = domain.RoundMoney(summary.Tax + lt.Tax)
+		}
+
+		result.Net += lt.Net
+		result.Tax += lt.Tax
+		result.Lines = append(result.Lines, lt)
+	}
+
+	result.Net = domain.RoundMoney(result.Net)
+	result.Tax = domain.RoundMoney(result.Tax)
+	result.Total = domain.RoundMoney(result.Net + result.Tax)
+
+	for _, s := range summaries {
+		result.Summaries = append(result.Summaries, *s)
+	}
+	sort.Slice(result.Summaries, func(i, j int) bool { return result.Summaries[i].Name < result.Summaries[j].Name })
+	return result
+}
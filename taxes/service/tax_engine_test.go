@@ -0,0 +1,130 @@
+package service
+
+import (
+	"backend/domain"
+	"testing"
+)
+
+func TestCalculate(t *testing.T) {
+	standard, reduced, exempt := uint(1), uint(2), uint(3)
+	rates := map[uint]*domain.TaxRate{
+		standard: {TaxClassID: standard, TaxClass: domain.TaxClass{Code: "standard"}, Name: "VAT 7%", Rate: 0.07},
+		reduced:  {TaxClassID: reduced, TaxClass: domain.TaxClass{Code: "reduced"}, Name: "Reduced 3%", Rate: 0.03},
+	}
+
+	cases := []struct {
+		name      string
+		inclusive bool
+		lines     []Line
+		discount  float64
+		want      Result
+		summaries []RateSummary
+	}{
+		{
+			name:      "exclusive rate",
+			lines:     []Line{{ProductID: 1, TaxClassID: &standard, UnitPrice: 100, Quantity: 2}},
+			want:      Result{Subtotal: 200, Net: 200, Tax: 14, Total: 214},
+			summaries: []RateSummary{{TaxClassCode: "standard", Name: "VAT 7%", Rate: 0.07, Taxable: 200, Tax: 14}},
+		},
+		{
+			name:      "inclusive rate",
+			inclusive: true,
+			lines:     []Line{{ProductID: 1, TaxClassID: &standard, UnitPrice: 107, Quantity: 1}},
+			want:      Result{Subtotal: 107, Net: 100, Tax: 7, Total: 107},
+			summaries: []RateSummary{{TaxClassCode: "standard", Name: "VAT 7%", Rate: 0.07, Taxable: 100, Tax: 7}},
+		},
+		{
+			name:      "discount above subtotal is capped",
+			lines:     []Line{{ProductID: 1, TaxClassID: &standard, UnitPrice: 50, Quantity: 1}},
+			discount:  80,
+			want:      Result{Subtotal: 50, Discount: 50},
+			summaries: []RateSummary{{TaxClassCode: "standard", Name: "VAT 7%", Rate: 0.07}},
+		},
+		{
+			name:      "inclusive discount above subtotal is capped",
+			inclusive: true,
+			lines:     []Line{{ProductID: 1, TaxClassID: &standard, UnitPrice: 50, Quantity: 1}},
+			discount:  80,
+			want:      Result{Subtotal: 50, Discount: 50},
+			summaries: []RateSummary{{TaxClassCode: "standard", Name: "VAT 7%", Rate: 0.07}},
+		},
+		{
+			// ส่วนลดถูกปันตามสัดส่วนราคา รายการสุดท้าย (ยกเว้นภาษี) รับเศษ
+			name: "mixed-rate cart with discount",
+			lines: []Line{
+				{ProductID: 1, TaxClassID: &standard, UnitPrice: 100, Quantity: 1},
+				{ProductID: 2, TaxClassID: &reduced, UnitPrice: 50, Quantity: 2},
+				{ProductID: 3, TaxClassID: &exempt, UnitPrice: 33.33, Quantity: 1},
+			},
+			discount: 10,
+			want:     Result{Subtotal: 233.33, Discount: 10, Net: 223.33, Tax: 9.57, Total: 232.90},
+			summaries: []RateSummary{
+				{TaxClassCode: "reduced", Name: "Reduced 3%", Rate: 0.03, Taxable: 95.71, Tax: 2.87},
+				{TaxClassCode: "standard", Name: "VAT 7%", Rate: 0.07, Taxable: 95.71, Tax: 6.70},
+			},
+		},
+		{
+			// ราคาหลักสตางค์ที่ปัดเศษทุกบรรทัด ยอดรวมต้องยังตรงกับราคาที่ลูกค้าเห็นพอดี
+			name:      "inclusive mixed-rate cart adds up to the satang",
+			inclusive: true,
+			lines: []Line{
+				{ProductID: 1, TaxClassID: &standard, UnitPrice: 0.99, Quantity: 3},
+				{ProductID: 2, TaxClassID: &reduced, UnitPrice: 1.01, Quantity: 7},
+				{ProductID: 3, UnitPrice: 0.33, Quantity: 1}, // ไม่ระบุ Tax Class ใช้ค่าเริ่มต้น (standard)
+			},
+			discount: 1,
+			want:     Result{Subtotal: 10.37, Discount: 1, Net: 8.98, Tax: 0.39, Total: 9.37},
+			summaries: []RateSummary{
+				{TaxClassCode: "reduced", Name: "Reduced 3%", Rate: 0.03, Taxable: 6.20, Tax: 0.19},
+				{TaxClassCode: "standard", Name: "VAT 7%", Rate: 0.07, Taxable: 2.78, Tax: 0.20},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := calculate(tc.lines, rates, &standard, tc.inclusive, tc.discount)
+
+			if !domain.SameMoney(got.Subtotal, tc.want.Subtotal) || !domain.SameMoney(got.Discount, tc.want.Discount) ||
+				!domain.SameMoney(got.Net, tc.want.Net) || !domain.SameMoney(got.Tax, tc.want.Tax) || !domain.SameMoney(got.Total, tc.want.Total) {
+				t.Errorf("totals = subtotal %.2f discount %.2f net %.2f tax %.2f total %.2f, want subtotal %.2f discount %.2f net %.2f tax %.2f total %.2f",
+					got.Subtotal, got.Discount, got.Net, got.Tax, got.Total,
+					tc.want.Subtotal, tc.want.Discount, tc.want.Net, tc.want.Tax, tc.want.Total)
+			}
+			if tc.inclusive && !domain.SameMoney(got.Total, got.Subtotal-got.Discount) {
+				t.Errorf("inclusive total %.2f should equal subtotal %.2f minus discount %.2f", got.Total, got.Subtotal, got.Discount)
+			}
+
+			// ผลรวมรายบรรทัดต้องตรงกับยอดรวมถึงหลักสตางค์
+			if len(got.Lines) != len(tc.lines) {
+				t.Fatalf("got %d lines, want %d", len(got.Lines), len(tc.lines))
+			}
+			var discount, net, tax float64
+			for _, lt := range got.Lines {
+				discount += lt.Discount
+				net += lt.Net
+				tax += lt.Tax
+			}
+			if !domain.SameMoney(discount, got.Discount) || !domain.SameMoney(net, got.Net) || !domain.SameMoney(tax, got.Tax) {
+				t.Errorf("line sums discount %.2f net %.2f tax %.2f do not match totals %.2f %.2f %.2f",
+					discount, net, tax, got.Discount, got.Net, got.Tax)
+			}
+
+			if len(got.Summaries) != len(tc.summaries) {
+				t.Fatalf("got %d summaries, want %d: %+v", len(got.Summaries), len(tc.summaries), got.Summaries)
+			}
+			var summaryTax float64
+			for i, want := range tc.summaries {
+				s := got.Summaries[i]
+				if s.TaxClassCode != want.TaxClassCode || s.Name != want.Name || s.Rate != want.Rate ||
+					!domain.SameMoney(s.Taxable, want.Taxable) || !domain.SameMoney(s.Tax, want.Tax) {
+					t.Errorf("summary %d = %+v, want %+v", i, s, want)
+				}
+				summaryTax += s.Tax
+			}
+			if !domain.SameMoney(summaryTax, got.Tax) {
+				t.Errorf("summary tax %.2f does not match total tax %.2f", summaryTax, got.Tax)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"backend/config"
+	"backend/domain"
+	"backend/internal/datastore"
+	"backend/taxes/dto"
+	"backend/taxes/repository"
+	"errors"
+)
+
+var (
+	ErrTaxClassNotFound = errors.New("tax class not found")
+	ErrTaxClassExists   = errors.New("tax class code already exists")
+	ErrTaxRateNotFound  = errors.New("tax rate not found")
+)
+
+// SettingsFromConfig สร้าง Settings ของ Tax Engine จาก Config
+func SettingsFromConfig(cfg *config.Config) Settings {
+	return Settings{
+		PricesIncludeTax: cfg.PricesIncludeTax,
+		DefaultClassCode: cfg.TaxDefaultClass,
+		DefaultCountry:   cfg.TaxDefaultCountry,
+	}
+}
+
+type TaxService interface {
+	CreateClass(req dto.TaxClassRequest) (*dto.TaxClassResponse, error)
+	GetAllClasses() ([]dto.TaxClassResponse, error)
+	CreateRate(req dto.TaxRateRequest) (*dto.TaxRateResponse, error)
+	GetAllRates() ([]dto.TaxRateResponse, error)
+	UpdateRate(id uint, req dto.TaxRateRequest) (*dto.TaxRateResponse, error)
+	DeleteRate(id uint) error
+}
+
+type taxService struct {
+	uow datastore.UnitOfWork
+}
+
+func NewTaxService(uow datastore.UnitOfWork) TaxService {
+	return &taxService{uow: uow}
+}
+
+func (s *taxService) CreateClass(req dto.TaxClassRequest) (*dto.TaxClassResponse, error) {
+	// ตรวจสอบว่าโค้ดซ้ำหรือไม่
+	_, err := s.uow.TaxRepository().FindClassByCode(req.Code)
+	if !errors.Is(err, repository.ErrNotFound) {
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrTaxClassExists
+	}
+
+	class := &domain.TaxClass{Code: req.Code, Name: req.Name}
+	err = s.uow.Execute(func(repos *datastore.Repositories) error {
+		return repos.Tax.CreateClass(class)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapClassToResponse(class), nil
+}
+
+func (s *taxService) GetAllClasses() ([]dto.TaxClassResponse, error) {
+	classes, err := s.uow.TaxRepository().FindAllClasses()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.TaxClassResponse, 0, len(classes))
+	for i := range classes {
+		responses = append(responses, *mapClassToResponse(&classes[i]))
+	}
+	return responses, nil
+}
+
+func (s *taxService) CreateRate(req dto.TaxRateRequest) (*dto.TaxRateResponse, error) {
+	rate := &domain.TaxRate{}
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		if err := applyRateRequest(repos, rate, req); err != nil {
+			return err
+		}
+		return repos.Tax.CreateRate(rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapRateToResponse(rate), nil
+}
+
+func (s *taxService) GetAllRates() ([]dto.TaxRateResponse, error) {
+	rates, err := s.uow.TaxRepository().FindAllRates()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.TaxRateResponse, 0, len(rates))
+	for i := range rates {
+		responses = append(responses, *mapRateToResponse(&rates[i]))
+	}
+	return responses, nil
+}
+
+func (s *taxService) UpdateRate(id uint, req dto.TaxRateRequest) (*dto.TaxRateResponse, error) {
+	var rate *domain.TaxRate
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		var err error
+		rate, err = repos.Tax.FindRateByID(id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrTaxRateNotFound
+			}
+			return err
+		}
+		if err := applyRateRequest(repos, rate, req); err != nil {
+			return err
+		}
+		return repos.Tax.UpdateRate(rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapRateToResponse(rate), nil
+}
+
+func (s *taxService) DeleteRate(id uint) error {
+	err := s.uow.Execute(func(repos *datastore.Repositories) error {
+		return repos.Tax.DeleteRate(id)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTaxRateNotFound
+	}
+	return err
+}
+
+// applyRateRequest ตรวจสอบ Tax Class แล้วคัดลอกข้อมูลจาก Request ลงใน rate
+func applyRateRequest(repos *datastore.Repositories, rate *domain.TaxRate, req dto.TaxRateRequest) error {
+	class, err := repos.Tax.FindClassByID(req.TaxClassID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTaxClassNotFound
+		}
+		return err
+	}
+	rate.TaxClassID = class.ID
+	rate.TaxClass = *class
+	rate.Name = req.Name
+	rate.Country = req.Country
+	rate.State = req.State
+	rate.Rate = req.Rate
+	return nil
+}
+
+func mapClassToResponse(class *domain.TaxClass) *dto.TaxClassResponse {
+	return &dto.TaxClassResponse{
+		ID:        class.ID,
+		Code:      class.Code,
+		Name:      class.Name,
+		CreatedAt: class.CreatedAt,
+	}
+}
+
+func mapRateToResponse(rate *domain.TaxRate) *dto.TaxRateResponse {
+	return &dto.TaxRateResponse{
+		ID:           rate.ID,
+		TaxClassID:   rate.TaxClassID,
+		TaxClassCode: rate.TaxClass.Code,
+		Name:         rate.Name,
+		Country:      rate.Country,
+		State:        rate.State,
+		Rate:         rate.Rate,
+	}
+}
@@ -0,0 +1,27 @@
+package taxes
+
+import (
+	"backend/config"
+	"backend/internal/datastore"
+	"backend/middleware"
+	"backend/taxes/handler"
+	"backend/taxes/service"
+	"github.com/gofiber/fiber/v2"
+	"log"
+)
+
+func RegisterModule(api fiber.Router, uow datastore.UnitOfWork, cfg *config.Config) {
+	taxSvc := service.NewTaxService(uow)
+	taxHdl := handler.NewTaxHandler(taxSvc)
+
+	adminAPI := api.Group("/admin/taxes", middleware.Protected(), middleware.AdminRequired())
+
+	adminAPI.Get("/classes", taxHdl.HandleGetAllClasses)
+	adminAPI.Post("/classes", taxHdl.HandleCreateClass)
+	adminAPI.Get("/rates", taxHdl.HandleGetAllRates)
+	adminAPI.Post("/rates", taxHdl.HandleCreateRate)
+	adminAPI.Put("/rates/:id", taxHdl.HandleUpdateRate)
+	adminAPI.Delete("/rates/:id", taxHdl.HandleDeleteRate)
+
+	log.Printf("✅ Tax module registered successfully (prices include tax: %t).", cfg.PricesIncludeTax)
+}
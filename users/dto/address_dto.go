@@ -9,4 +9,9 @@ type AddressRequest struct {
 	PostalCode   string `json:"postal_code" validate:"required"` // รหัสไปรษณีย์
 	Country      string `json:"country" validate:"required"`
 	IsDefault    bool   `json:"is_default"` // รับค่าว่าต้องการตั้งเป็นที่อยู่หลักหรือไม่
+
+	// สำหรับใบกำกับภาษี (ไม่บังคับ)
+	CompanyName string `json:"company_name" validate:"max=255"`
+	TaxID       string `json:"tax_id" validate:"omitempty,len=13,numeric"`
+	BranchCode  string `json:"branch_code" validate:"omitempty,len=5,numeric"`
 }
@@ -299,6 +299,9 @@ func (s *userService) AddAddress(userID uint, req dto.AddressRequest) (*domain.A
 		PostalCode:   req.PostalCode,
 		Country:      req.Country,
 		IsDefault:    req.IsDefault,
+		CompanyName:  req.CompanyName,
+		TaxID:        req.TaxID,
+		BranchCode:   req.BranchCode,
 	}
 
 	// [Logic สำคัญ] ถ้าตั้งที่อยู่นี้เป็น Default ต้องไปเคลียร์ Default เก่าก่อน
@@ -331,8 +334,15 @@ func (s *userService) UpdateAddress(userID, addressID uint, req dto.AddressReque
 
 	// อัปเดตข้อมูล
 	address.AddressLine1 = req.AddressLine1
-	// ... อัปเดตฟิลด์อื่นๆ ...
+	address.AddressLine2 = req.AddressLine2
+	address.City = req.City
+	address.State = req.State
+	address.PostalCode = req.PostalCode
+	address.Country = req.Country
 	address.IsDefault = req.IsDefault
+	address.CompanyName = req.CompanyName
+	address.TaxID = req.TaxID
+	address.BranchCode = req.BranchCode
 
 	if address.IsDefault {
 		if err := s.uow.AddressRepository().ClearDefault(userID); err != nil {